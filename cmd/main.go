@@ -1,40 +1,78 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/Slightly-Techie/st-okr-api/config"
 	"github.com/Slightly-Techie/st-okr-api/db"
 	"github.com/Slightly-Techie/st-okr-api/internal/logger"
 	"github.com/Slightly-Techie/st-okr-api/internal/message"
+	"github.com/Slightly-Techie/st-okr-api/internal/metrics"
 	"github.com/Slightly-Techie/st-okr-api/internal/routes"
+	"github.com/Slightly-Techie/st-okr-api/internal/scheduler"
+	"github.com/Slightly-Techie/st-okr-api/internal/tracing"
+	"github.com/Slightly-Techie/st-okr-api/internal/validation"
 	auth "github.com/Slightly-Techie/st-okr-api/pkg"
 	"github.com/Slightly-Techie/st-okr-api/provider"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
 )
 
 func main() {
 	// Initialize logger
 	logger.InitGlobal()
-	defer logger.Custom.Close()
+	// Custom is replaced once the configuration is loaded
+	defer func() { logger.Custom.Close() }()
 
 	logger.Info("Starting ST OKR API server")
 
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		logger.Fatal("Failed to load configuration", "error", err)
+	}
+	if err := logger.Configure(cfg.Log); err != nil {
+		logger.Fatal("Failed to configure logger", "error", err)
+	}
+	logger.Info("Configuration loaded", "profile", cfg.Profile, "log_level", cfg.Log.Level)
+
+	if err := auth.InitKeys(); err != nil {
+		logger.Fatal("Failed to load JWT signing keys", "error", err)
+	}
+	logger.Info("JWT signing keys loaded", "active_key_id", auth.Keys().Active().ID)
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		logger.Fatal("Failed to initialize tracing", "error", err)
+	}
+	logger.Info("Tracing initialized", "exporter", cfg.Tracing.Exporter)
+
 	database, err := db.InitDB()
 	if err != nil {
 		logger.Fatal("Failed to connect to database", "error", err)
 	}
 	logger.Info("Database connection established")
 
-	config := config.ENV
+	if err := metrics.RegisterGORMCallbacks(database); err != nil {
+		logger.Fatal("Failed to register database metrics", "error", err)
+	}
+	metrics.Registry.MustRegister(metrics.NewOKRCollector(database))
+
+	if err := database.Use(gormtracing.NewPlugin(gormtracing.WithoutMetrics())); err != nil {
+		logger.Fatal("Failed to register database tracing", "error", err)
+	}
 
 	// initialize rabitmq
 	logger.Info("Initializing RabbitMQ connection")
 	var connected bool
 	for retries := 0; retries < 5; retries++ {
-		if err := message.TestRabbitMQConnection(config); err != nil {
+		if err := message.TestRabbitMQConnection(cfg.Rabbit); err != nil {
 			logger.Warn("RabbitMQ connection attempt failed", "attempt", retries+1, "error", err)
 			time.Sleep(10 * time.Second)
 		} else {
@@ -50,17 +88,88 @@ func main() {
 	logger.Info("RabbitMQ connection established")
 
 	validator := validator.New()
+	if err := validation.RegisterTranslations(validator); err != nil {
+		logger.Fatal("Failed to register validation messages", "error", err)
+	}
 	auth.NewAuth()
 
-	go message.ConsumeMessages()
+	consumer, err := message.NewConsumer(cfg.Rabbit)
+	if err != nil {
+		logger.Fatal("Failed to create message consumer", "error", err)
+	}
+	if err := consumer.Start(); err != nil {
+		logger.Fatal("Failed to start message consumer", "error", err)
+	}
 
 	provider := provider.NewProvider(database, validator)
+	provider.HealthController.Register("rabbitmq_consumer", consumer.Check)
+
+	jobs := scheduler.New(cfg.Scheduler, provider.Jobs...)
+	jobs.Start()
 
 	router := routes.SetupRouter(provider)
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "Hello to the SlightlyTechie OKR API!"})
 	})
 
-	logger.Info("Server starting", "port", "8080")
-	router.Run(":8080")
+	srv := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           router,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("Server starting", "port", cfg.Server.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+		logger.Info("Shutdown signal received", "timeout", cfg.Server.ShutdownTimeout.String())
+	case err := <-serverErr:
+		logger.Error("Server stopped unexpectedly", "error", err)
+	}
+
+	// Everything below shares one deadline
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	// Stop accepting connections and wait for in-flight requests
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("HTTP server did not shut down cleanly", "error", err)
+	}
+
+	// Let consumers finish the message they are handling
+	if err := consumer.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Message consumer did not shut down cleanly", "error", err)
+	}
+
+	// Stop scheduled jobs before the database goes away
+	if err := jobs.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Scheduled jobs did not shut down cleanly", "error", err)
+	}
+
+	if err := message.DefaultPublisher().Close(); err != nil {
+		logger.Error("Failed to close message publisher", "error", err)
+	}
+
+	if err := db.Close(database); err != nil {
+		logger.Error("Failed to close database", "error", err)
+	}
+
+	// Flush spans recorded during shutdown last
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("Failed to flush traces", "error", err)
+	}
+
+	logger.Info("Server stopped")
 }
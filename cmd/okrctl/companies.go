@@ -0,0 +1,22 @@
+package main
+
+func companiesList(a *app, args []string) error {
+	if _, err := a.parse(args, 0, nil); err != nil {
+		return err
+	}
+
+	st, err := a.store()
+	if err != nil {
+		return err
+	}
+	companies, err := st.ListCompanies(a.ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(companies))
+	for i, company := range companies {
+		rows[i] = []string{company.ID, company.Name, company.Code, company.CreatorID, formatTime(company.CreatedAt)}
+	}
+	return a.print(companies, []string{"ID", "NAME", "CODE", "CREATOR", "CREATED"}, rows)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+)
+
+func cyclesList(a *app, args []string) error {
+	args, err := a.parse(args, 1, nil)
+	if err != nil {
+		return err
+	}
+
+	st, err := a.store()
+	if err != nil {
+		return err
+	}
+	cycles, err := st.ListCycles(a.ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(cycles))
+	for i, cycle := range cycles {
+		rows[i] = cycleRow(cycle)
+	}
+	return a.print(cycles, []string{"ID", "NAME", "START", "END"}, rows)
+}
+
+func cyclesCreate(a *app, args []string) error {
+	var companyID, as string
+	var req dto.CreateCycleRequest
+	args, err := a.parse(args, 1, func(flags *flag.FlagSet) {
+		flags.StringVar(&companyID, "company", "", "company the cycle belongs to")
+		flags.StringVar(&as, "as", "", "email or ID of the admin to act as (database only)")
+		flags.StringVar(&req.StartDate, "from", "", "first day of the cycle, YYYY-MM-DD")
+		flags.StringVar(&req.EndDate, "to", "", "last day of the cycle, YYYY-MM-DD")
+	})
+	if err != nil {
+		return err
+	}
+	if companyID == "" {
+		return errors.New("-company is required")
+	}
+	req.Name = args[0]
+
+	st, err := a.store()
+	if err != nil {
+		return err
+	}
+	cycle, err := st.CreateCycle(a.ctx, companyID, as, req)
+	if err != nil {
+		return err
+	}
+	return a.print(cycle, []string{"ID", "NAME", "START", "END"}, [][]string{cycleRow(*cycle)})
+}
+
+func cycleRow(cycle models.Cycle) []string {
+	return []string{cycle.ID, cycle.Name, formatTime(cycle.StartDate), formatTime(cycle.EndDate)}
+}
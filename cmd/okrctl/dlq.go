@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"strconv"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/message"
+)
+
+func dlqList(a *app, args []string) error {
+	if _, err := a.parse(args, 0, nil); err != nil {
+		return err
+	}
+
+	cfg, err := a.config()
+	if err != nil {
+		return err
+	}
+	counts, err := message.DeadLetters(cfg.Rabbit)
+	if err != nil {
+		return err
+	}
+
+	queues := message.Queues()
+	rows := make([][]string, len(queues))
+	for i, queue := range queues {
+		rows[i] = []string{queue, message.DeadLetterQueue(queue), strconv.Itoa(counts[queue])}
+	}
+	return a.print(counts, []string{"QUEUE", "DEAD LETTER QUEUE", "MESSAGES"}, rows)
+}
+
+func dlqReplay(a *app, args []string) error {
+	var limit int
+	args, err := a.parse(args, 1, func(flags *flag.FlagSet) {
+		flags.IntVar(&limit, "limit", 0, "maximum number of messages to replay; all waiting messages when 0")
+	})
+	if err != nil {
+		return err
+	}
+
+	cfg, err := a.config()
+	if err != nil {
+		return err
+	}
+	replayed, err := message.Replay(a.ctx, cfg.Rabbit, args[0], limit)
+	if err != nil && replayed == 0 {
+		return err
+	}
+
+	result := map[string]any{"queue": args[0], "replayed": replayed}
+	if printErr := a.print(result, []string{"QUEUE", "REPLAYED"}, [][]string{{args[0], strconv.Itoa(replayed)}}); printErr != nil {
+		return printErr
+	}
+	// Report a failure part way through after what was already moved
+	return err
+}
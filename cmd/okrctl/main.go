@@ -0,0 +1,157 @@
+// Command okrctl administers an OKR API instance. Most commands work through
+// the HTTP API when -api is set and directly against the database otherwise;
+// the dead-letter commands talk to RabbitMQ.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/Slightly-Techie/st-okr-api/config"
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
+	"github.com/Slightly-Techie/st-okr-api/internal/validation"
+	"github.com/Slightly-Techie/st-okr-api/pkg/client"
+	"github.com/go-playground/validator/v10"
+)
+
+// command is a subcommand such as "memberships promote"
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(a *app, args []string) error
+}
+
+var commands = []command{
+	{"companies list", "", "List every company (database only)", companiesList},
+	{"memberships list", "COMPANY_ID", "List a company's members", membershipsList},
+	{"memberships promote", "MEMBERSHIP_ID", "Make a member an admin", membershipsPromote},
+	{"memberships set-role", "MEMBERSHIP_ID admin|member|viewer", "Change a member's role; the last admin cannot be demoted", membershipsSetRole},
+	{"cycles list", "COMPANY_ID", "List a company's named cycles", cyclesList},
+	{"cycles create", "-company COMPANY_ID [-as EMAIL] [-from DATE -to DATE] NAME", "Name a period such as a fiscal quarter; the dates default to the calendar period NAME, e.g. 2025-Q3", cyclesCreate},
+	{"okrs import", "-company COMPANY_ID [-as EMAIL] [-dry-run] FILE", "Create the objectives and key results of a CSV, JSON or YAML import file in one transaction", okrsImport},
+	{"okrs export", "[-file PATH] COMPANY_ID", "Write a company, its members and its OKRs as JSON", okrsExport},
+	{"dlq list", "", "Count the dead-lettered messages of each queue", dlqList},
+	{"dlq replay", "[-limit N] QUEUE", "Move dead-lettered messages back onto their queue", dlqReplay},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "okrctl:", describe(err))
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, out io.Writer) error {
+	// Library logs would interleave with the command output
+	if err := logger.Configure(config.LogConfig{Level: "warn", Format: "console", Outputs: []string{"stderr"}}); err != nil {
+		return err
+	}
+	defer logger.Custom.Close()
+
+	a := &app{ctx: ctx, out: out}
+
+	flags := flag.NewFlagSet("okrctl", flag.ContinueOnError)
+	flags.StringVar(&a.apiURL, "api", os.Getenv("OKR_API_URL"), "API base URL; the database is used when empty")
+	flags.StringVar(&a.accessToken, "token", os.Getenv("OKR_ACCESS_TOKEN"), "access token for the API")
+	flags.StringVar(&a.refreshToken, "refresh-token", os.Getenv("OKR_REFRESH_TOKEN"), "refresh token for the API")
+	flags.StringVar(&a.configFile, "config", "", "server config file for database and RabbitMQ access")
+	flags.StringVar(&a.profile, "profile", "", "server configuration profile")
+	flags.StringVar(&a.format, "o", "table", "output format: table or json")
+	flags.Usage = func() { usage(flags) }
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if a.format != "table" && a.format != "json" {
+		return fmt.Errorf("unknown output format %q", a.format)
+	}
+
+	args = flags.Args()
+	if len(args) < 2 {
+		usage(flags)
+		return flag.ErrHelp
+	}
+
+	name := args[0] + " " + args[1]
+	for _, cmd := range commands {
+		if cmd.name == name {
+			a.command = cmd
+			defer a.close()
+			return cmd.run(a, args[2:])
+		}
+	}
+
+	usage(flags)
+	return fmt.Errorf("unknown command %q", name)
+}
+
+func usage(flags *flag.FlagSet) {
+	w := flags.Output()
+	fmt.Fprintln(w, "Usage: okrctl [flags] COMMAND [args]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s %s\n    \t%s\n", cmd.name, cmd.usage, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nFlags:")
+	flags.PrintDefaults()
+}
+
+// parse parses the flags of the running subcommand and checks the number of
+// positional arguments
+func (a *app) parse(args []string, want int, define func(*flag.FlagSet)) ([]string, error) {
+	flags := flag.NewFlagSet("okrctl "+a.command.name, flag.ContinueOnError)
+	if define != nil {
+		define(flags)
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() != want {
+		return nil, fmt.Errorf("usage: okrctl %s %s", a.command.name, a.command.usage)
+	}
+	return flags.Args(), nil
+}
+
+// describe adds the per-field messages of validation errors to err
+func describe(err error) string {
+	details := map[string]string{}
+
+	var apiErr *client.Error
+	var validationErrs validator.ValidationErrors
+	if appErr, ok := apperror.As(err); ok {
+		details = appErr.Details
+	}
+	switch {
+	case errors.As(err, &apiErr):
+		details = apiErr.Details
+	case errors.As(err, &validationErrs):
+		details = validation.Translate(validationErrs, "en")
+	}
+
+	fields := make([]string, 0, len(details))
+	for field := range details {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var b strings.Builder
+	b.WriteString(err.Error())
+	for _, field := range fields {
+		fmt.Fprintf(&b, "\n  %s: %s", field, details[field])
+	}
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+)
+
+func membershipsList(a *app, args []string) error {
+	args, err := a.parse(args, 1, nil)
+	if err != nil {
+		return err
+	}
+
+	st, err := a.store()
+	if err != nil {
+		return err
+	}
+	members, err := st.ListMembers(a.ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(members))
+	for i, member := range members {
+		rows[i] = []string{member.ID, member.UserID, string(member.Role), string(member.Status), formatTime(member.CreatedAt)}
+	}
+	return a.print(members, []string{"ID", "USER", "ROLE", "STATUS", "JOINED"}, rows)
+}
+
+func membershipsPromote(a *app, args []string) error {
+	args, err := a.parse(args, 1, nil)
+	if err != nil {
+		return err
+	}
+	return setRole(a, args[0], models.RoleAdmin)
+}
+
+func membershipsSetRole(a *app, args []string) error {
+	args, err := a.parse(args, 2, nil)
+	if err != nil {
+		return err
+	}
+
+	role := models.RoleType(args[1])
+	switch role {
+	case models.RoleAdmin, models.RoleMember, models.RoleViewer:
+	default:
+		return fmt.Errorf("unknown role %q: want admin, member or viewer", args[1])
+	}
+	return setRole(a, args[0], role)
+}
+
+// setRole changes the role through the service, which refuses to demote a
+// company's last active admin
+func setRole(a *app, membershipID string, role models.RoleType) error {
+	st, err := a.store()
+	if err != nil {
+		return err
+	}
+	if err := st.SetRole(a.ctx, membershipID, role); err != nil {
+		return err
+	}
+
+	result := map[string]string{"id": membershipID, "role": string(role)}
+	return a.print(result, []string{"ID", "ROLE"}, [][]string{{membershipID, string(role)}})
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"github.com/Slightly-Techie/st-okr-api/internal/services"
+)
+
+// importJob is a bulk import run by okrs import
+type importJob struct {
+	companyID string
+	// as is the member the import runs as when it goes to the database.
+	// Over the API it is the signed-in user.
+	as       string
+	format   services.ImportFormat
+	document []byte
+	dryRun   bool
+}
+
+// companyExport is the document written by okrs export
+type companyExport struct {
+	ExportedAt time.Time               `json:"exported_at"`
+	Company    *models.Company         `json:"company"`
+	Members    []models.Membership     `json:"members"`
+	Objectives []dto.ObjectiveResponse `json:"objectives"`
+}
+
+func okrsImport(a *app, args []string) error {
+	job := importJob{}
+	var format string
+	args, err := a.parse(args, 1, func(flags *flag.FlagSet) {
+		flags.StringVar(&job.companyID, "company", "", "company the objectives belong to")
+		flags.StringVar(&job.as, "as", "", "email or ID of the member to import as (database only)")
+		flags.StringVar(&format, "format", "", "csv, json or yaml; taken from the file extension when empty")
+		flags.BoolVar(&job.dryRun, "dry-run", false, "only check the file and list its problems")
+	})
+	if err != nil {
+		return err
+	}
+	if job.companyID == "" {
+		return errors.New("-company is required")
+	}
+
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(args[0]), ".")
+	}
+	var ok bool
+	if job.format, ok = services.ParseImportFormat(format); !ok {
+		return fmt.Errorf("unknown import format %q; use -format csv, json or yaml", format)
+	}
+	if job.document, err = os.ReadFile(args[0]); err != nil {
+		return err
+	}
+
+	st, err := a.store()
+	if err != nil {
+		return err
+	}
+
+	// The whole file is created in one transaction, or nothing is
+	report, err := st.Import(a.ctx, job)
+	if err != nil {
+		return err
+	}
+
+	if len(report.Errors) > 0 {
+		rows := make([][]string, len(report.Errors))
+		for i, e := range report.Errors {
+			rows[i] = []string{strconv.Itoa(e.Row), e.Field, e.Message}
+		}
+		return a.print(report, []string{"ROW", "FIELD", "PROBLEM"}, rows)
+	}
+	return a.print(report, []string{"OBJECTIVES", "KEY RESULTS", "DRY RUN"},
+		[][]string{{strconv.Itoa(report.Objectives), strconv.Itoa(report.KeyResults), strconv.FormatBool(report.DryRun)}})
+}
+
+func okrsExport(a *app, args []string) error {
+	var file string
+	args, err := a.parse(args, 1, func(flags *flag.FlagSet) {
+		flags.StringVar(&file, "file", "", "write to this file instead of standard output")
+	})
+	if err != nil {
+		return err
+	}
+
+	st, err := a.store()
+	if err != nil {
+		return err
+	}
+
+	export := companyExport{ExportedAt: time.Now().UTC()}
+	if export.Company, err = st.GetCompany(a.ctx, args[0]); err != nil {
+		return err
+	}
+	if export.Members, err = st.ListMembers(a.ctx, args[0]); err != nil {
+		return err
+	}
+
+	list, err := st.ListObjectives(a.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	export.Objectives = make([]dto.ObjectiveResponse, 0, len(list))
+	for _, item := range list {
+		objective, err := st.GetObjective(a.ctx, item.ID)
+		if err != nil {
+			return err
+		}
+		export.Objectives = append(export.Objectives, *objective)
+	}
+
+	// The export is JSON whatever -o says; it is meant to be read back
+	if file == "" {
+		return writeJSON(a.out, export)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(f, export); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return a.print(map[string]any{"file": file, "objectives": len(export.Objectives)},
+		[]string{"FILE", "OBJECTIVES"}, [][]string{{file, strconv.Itoa(len(export.Objectives))}})
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Slightly-Techie/st-okr-api/config"
+	"github.com/Slightly-Techie/st-okr-api/db"
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"github.com/Slightly-Techie/st-okr-api/internal/repositories"
+	"github.com/Slightly-Techie/st-okr-api/internal/services"
+	"github.com/Slightly-Techie/st-okr-api/internal/validation"
+	"github.com/Slightly-Techie/st-okr-api/pkg/client"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// app holds the global flags and the connections opened for a command
+type app struct {
+	ctx     context.Context
+	out     io.Writer
+	format  string
+	command command
+
+	apiURL       string
+	accessToken  string
+	refreshToken string
+	configFile   string
+	profile      string
+
+	cfg     *config.Config
+	st      store
+	cleanup func()
+}
+
+// store is what the commands need from an instance. Both implementations go
+// through the same rules as the API: apiStore over HTTP, dbStore by calling
+// the services directly.
+type store interface {
+	ListCompanies(ctx context.Context) ([]models.Company, error)
+	GetCompany(ctx context.Context, id string) (*models.Company, error)
+	ListMembers(ctx context.Context, companyID string) ([]models.Membership, error)
+	SetRole(ctx context.Context, membershipID string, role models.RoleType) error
+	Import(ctx context.Context, job importJob) (*dto.ImportReport, error)
+	ListCycles(ctx context.Context, companyID string) ([]models.Cycle, error)
+	CreateCycle(ctx context.Context, companyID, as string, req dto.CreateCycleRequest) (*models.Cycle, error)
+	ListObjectives(ctx context.Context, companyID string) ([]dto.ObjectiveListResponse, error)
+	GetObjective(ctx context.Context, id string) (*dto.ObjectiveResponse, error)
+}
+
+// config loads the server configuration, for database and RabbitMQ access
+func (a *app) config() (*config.Config, error) {
+	if a.cfg != nil {
+		return a.cfg, nil
+	}
+
+	var args []string
+	if a.configFile != "" {
+		args = append(args, "-config", a.configFile)
+	}
+	if a.profile != "" {
+		args = append(args, "-profile", a.profile)
+	}
+	cfg, err := config.Load(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	a.cfg = cfg
+	return cfg, nil
+}
+
+func (a *app) close() {
+	if a.cleanup != nil {
+		a.cleanup()
+	}
+}
+
+// store connects to the API when -api is set and to the database otherwise
+func (a *app) store() (store, error) {
+	if a.st != nil {
+		return a.st, nil
+	}
+
+	if a.apiURL != "" {
+		c, err := client.New(client.Config{
+			BaseURL:      a.apiURL,
+			AccessToken:  a.accessToken,
+			RefreshToken: a.refreshToken,
+			UserAgent:    "okrctl",
+		})
+		if err != nil {
+			return nil, err
+		}
+		a.st = &apiStore{c}
+		return a.st, nil
+	}
+
+	if _, err := a.config(); err != nil {
+		return nil, err
+	}
+	// The server migrates the schema; admin commands only connect
+	database, err := db.Open()
+	if err != nil {
+		return nil, err
+	}
+	a.cleanup = func() { _ = db.Close(database) }
+
+	st, err := newDBStore(database)
+	if err != nil {
+		return nil, err
+	}
+	a.st = st
+	return a.st, nil
+}
+
+type apiStore struct {
+	c *client.Client
+}
+
+func (s *apiStore) ListCompanies(context.Context) ([]models.Company, error) {
+	return nil, errors.New("the API cannot list companies; run without -api to read them from the database")
+}
+
+func (s *apiStore) GetCompany(ctx context.Context, id string) (*models.Company, error) {
+	return s.c.Companies.Get(ctx, id)
+}
+
+func (s *apiStore) ListMembers(ctx context.Context, companyID string) ([]models.Membership, error) {
+	return client.Collect(s.c.Memberships.ListByCompany(ctx, companyID))
+}
+
+func (s *apiStore) SetRole(ctx context.Context, membershipID string, role models.RoleType) error {
+	return s.c.Memberships.UpdateRole(ctx, membershipID, role)
+}
+
+// importMediaTypes are the Content-Types the import endpoint reads
+var importMediaTypes = map[services.ImportFormat]string{
+	services.ImportFormatJSON: "application/json",
+	services.ImportFormatYAML: "application/yaml",
+	services.ImportFormatCSV:  "text/csv",
+}
+
+func (s *apiStore) Import(ctx context.Context, job importJob) (*dto.ImportReport, error) {
+	if job.as != "" {
+		return nil, errors.New("-as only applies to the database; the API imports as the signed-in user")
+	}
+	return s.c.Companies.Import(ctx, job.companyID, importMediaTypes[job.format], job.document, job.dryRun)
+}
+
+func (s *apiStore) ListCycles(ctx context.Context, companyID string) ([]models.Cycle, error) {
+	return s.c.Companies.Cycles(ctx, companyID)
+}
+
+func (s *apiStore) CreateCycle(ctx context.Context, companyID, as string, req dto.CreateCycleRequest) (*models.Cycle, error) {
+	if as != "" {
+		return nil, errors.New("-as only applies to the database; the API acts as the signed-in user")
+	}
+	return s.c.Companies.CreateCycle(ctx, companyID, req)
+}
+
+func (s *apiStore) ListObjectives(ctx context.Context, companyID string) ([]dto.ObjectiveListResponse, error) {
+	return client.Collect(s.c.Objectives.ListByCompany(ctx, companyID))
+}
+
+func (s *apiStore) GetObjective(ctx context.Context, id string) (*dto.ObjectiveResponse, error) {
+	return s.c.Objectives.GetWithKeyResults(ctx, id)
+}
+
+type dbStore struct {
+	companies   services.CompanyService
+	memberships services.MembershipService
+	objectives  services.ObjectiveService
+	imports     services.ImportService
+	users       repositories.UserRepository
+	companyRepo repositories.CompanyRepository
+}
+
+func newDBStore(database *gorm.DB) (*dbStore, error) {
+	v := validator.New()
+	if err := validation.RegisterTranslations(v); err != nil {
+		return nil, err
+	}
+
+	companyRepo := repositories.NewCompanyRepository(database)
+	return &dbStore{
+		companies:   services.NewCompanyService(companyRepo, v),
+		memberships: services.NewMembershipService(repositories.NewMembershipRepository(database), v),
+		objectives:  services.NewObjectiveService(repositories.NewObjectiveRepository(database), v),
+		imports:     services.NewImportService(repositories.NewObjectiveRepository(database), v),
+		users:       repositories.NewUserRepository(database),
+		companyRepo: companyRepo,
+	}, nil
+}
+
+func (s *dbStore) ListCompanies(ctx context.Context) ([]models.Company, error) {
+	return s.companies.ListCompanies(ctx)
+}
+
+func (s *dbStore) GetCompany(ctx context.Context, id string) (*models.Company, error) {
+	return s.companies.GetCompany(ctx, "id", id)
+}
+
+func (s *dbStore) ListMembers(ctx context.Context, companyID string) ([]models.Membership, error) {
+	return s.memberships.GetCompanyMembers(ctx, companyID)
+}
+
+func (s *dbStore) SetRole(ctx context.Context, membershipID string, role models.RoleType) error {
+	return s.memberships.UpdateMembershipRole(ctx, membershipID, role)
+}
+
+// Import runs the import as the member named by -as, who must be an admin or
+// member of the company, as over the API
+func (s *dbStore) Import(ctx context.Context, job importJob) (*dto.ImportReport, error) {
+	userID, err := s.member(ctx, job.as)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.imports.ImportOKRs(ctx, services.ImportRequest{
+		CompanyID:  job.companyID,
+		ImporterID: userID,
+		Format:     job.format,
+		Body:       bytes.NewReader(job.document),
+		DryRun:     job.dryRun,
+	})
+}
+
+// ListCycles reads the cycles straight from the database, as companies list
+// does, so it needs no member to act as
+func (s *dbStore) ListCycles(ctx context.Context, companyID string) ([]models.Cycle, error) {
+	return s.companyRepo.ListCycles(ctx, companyID)
+}
+
+// CreateCycle acts as the admin named by -as
+func (s *dbStore) CreateCycle(ctx context.Context, companyID, as string, req dto.CreateCycleRequest) (*models.Cycle, error) {
+	userID, err := s.member(ctx, as)
+	if err != nil {
+		return nil, err
+	}
+	return s.companies.CreateCycle(ctx, userID, companyID, req)
+}
+
+// member resolves the -as flag, an email or user ID, to the user a write
+// runs as; the services then check their role in the company
+func (s *dbStore) member(ctx context.Context, as string) (string, error) {
+	if as == "" {
+		return "", errors.New("-as is required without -api: the email or ID of the member to act as")
+	}
+	identifier := "id = ?"
+	if strings.Contains(as, "@") {
+		identifier = "LOWER(email) = LOWER(?)"
+	}
+	user, err := s.users.GetByIdentifier(ctx, identifier, as)
+	if err != nil {
+		return "", fmt.Errorf("-as %s: %w", as, err)
+	}
+	return user.ID, nil
+}
+
+func (s *dbStore) ListObjectives(ctx context.Context, companyID string) ([]dto.ObjectiveListResponse, error) {
+	return s.objectives.ListObjectivesByCompany(ctx, companyID)
+}
+
+func (s *dbStore) GetObjective(ctx context.Context, id string) (*dto.ObjectiveResponse, error) {
+	return s.objectives.GetObjectiveWithKeyResults(ctx, id)
+}
@@ -1,67 +1,272 @@
 package config
 
 import (
-	"log"
-	"os"
-
-	"github.com/joho/godotenv"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
 )
 
+// Config is the application configuration. It is assembled by Load from
+// defaults, config files, environment variables and flags, in that order.
 type Config struct {
-	ServerPort         string
-	DBHost             string
-	DBPort             string
-	DBUser             string
-	DBPassword         string
-	DBName             string
-	JWTKey             string
-	SMTPUsername       string
-	SMTPPassword       string
-	SMTPHost           string
-	SMTPAddress        string
-	SMTPPort           string
-	GoogleClientID     string
-	GoogleClientSecret string
-	RabbitUser         string
-	RabbitPassword     string
-	RabbitHost         string
-	RabbitPort         string
-}
-
-var ENV = initConfig()
-
-func initConfig() Config {
-
-	err := godotenv.Load()
-	if err != nil {
-		log.Printf("unable to load .env")
-	}
+	Profile   string          `yaml:"-"`
+	Server    ServerConfig    `yaml:"server"`
+	DB        DBConfig        `yaml:"db"`
+	Auth      AuthConfig      `yaml:"auth"`
+	SMTP      SMTPConfig      `yaml:"smtp"`
+	Rabbit    RabbitConfig    `yaml:"rabbit"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Log       LogConfig       `yaml:"log"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+}
+
+type ServerConfig struct {
+	Port             string   `yaml:"port" env:"PORT" validate:"required,numeric"`
+	FrontendURL      string   `yaml:"frontend_url" env:"FRONTEND_URL" validate:"required,url"`
+	FrontendOrigins  []string `yaml:"frontend_origins" env:"FRONTEND_ORIGINS" validate:"dive,url"`
+	OAuthCallbackURL string   `yaml:"oauth_callback_url" env:"OAUTH_CALLBACK_URL" validate:"required,url"`
+
+	// CORSOrigins may call the API from a browser besides the frontend
+	// origins. TrustedProxies are the IPs or CIDR ranges whose
+	// X-Forwarded-For header is believed. An HSTSMaxAge of 0 omits HSTS.
+	// SecureCookies marks login cookies Secure; it cannot follow the request
+	// since TLS often ends at a proxy in front of the server.
+	CORSOrigins    []string      `yaml:"cors_origins" env:"CORS_ORIGINS" validate:"dive,url"`
+	TrustedProxies []string      `yaml:"trusted_proxies" env:"TRUSTED_PROXIES" validate:"dive,cidr|ip"`
+	HSTSMaxAge     time.Duration `yaml:"hsts_max_age" env:"HSTS_MAX_AGE" validate:"min=0"`
+	SecureCookies  bool          `yaml:"secure_cookies" env:"SECURE_COOKIES"`
+
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" validate:"min=1s"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" validate:"min=1s"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" validate:"min=1s"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" validate:"min=1s"`
+}
+
+type DBConfig struct {
+	Host     string `yaml:"host" env:"DB_HOST" validate:"required"`
+	Port     string `yaml:"port" env:"DB_PORT" validate:"required,numeric"`
+	User     string `yaml:"user" env:"DB_USER" validate:"required"`
+	Password string `yaml:"password" env:"DB_PASSWORD"`
+	Name     string `yaml:"name" env:"DB_NAME" validate:"required"`
+	SSLMode  string `yaml:"sslmode" env:"DB_SSLMODE" validate:"oneof=disable allow prefer require verify-ca verify-full"`
+}
+
+type AuthConfig struct {
+	JWT       JWTConfig         `yaml:"jwt"`
+	Google    OAuthClientConfig `yaml:"google" env:"GOOGLE"`
+	GitHub    OAuthClientConfig `yaml:"github" env:"GITHUB"`
+	Microsoft OAuthClientConfig `yaml:"microsoft" env:"MICROSOFT"`
+	SAML      SAMLConfig        `yaml:"saml"`
+}
+
+type JWTConfig struct {
+	KeysDir     string `yaml:"keys_dir" env:"JWT_KEYS_DIR" validate:"required"`
+	ActiveKeyID string `yaml:"active_key_id" env:"JWT_ACTIVE_KEY_ID"`
+	Issuer      string `yaml:"issuer" env:"JWT_ISSUER" validate:"required"`
+	Audience    string `yaml:"audience" env:"JWT_AUDIENCE" validate:"required"`
+}
+
+// OAuthClientConfig holds the credentials of a social login provider. The
+// provider is disabled while ClientID is empty.
+type OAuthClientConfig struct {
+	ClientID     string `yaml:"client_id" env:"CLIENT_ID"`
+	ClientSecret string `yaml:"client_secret" env:"CLIENT_SECRET" validate:"required_with=ClientID"`
+}
+
+type SAMLConfig struct {
+	CertFile string `yaml:"cert_file" env:"SAML_CERT_FILE" validate:"required_with=KeyFile"`
+	KeyFile  string `yaml:"key_file" env:"SAML_KEY_FILE" validate:"required_with=CertFile"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host" env:"SMTP_HOST"`
+	Port     string `yaml:"port" env:"SMTP_PORT" validate:"required,numeric"`
+	Username string `yaml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD"`
+	From     string `yaml:"from" env:"SMTP_FROM" validate:"omitempty,email"`
+}
+
+type RabbitConfig struct {
+	Host     string `yaml:"host" env:"RABBIT_HOST" validate:"required"`
+	Port     string `yaml:"port" env:"RABBIT_PORT" validate:"required,numeric"`
+	User     string `yaml:"user" env:"RABBIT_USER" validate:"required"`
+	Password string `yaml:"password" env:"RABBIT_PASS" validate:"required"`
+}
+
+type SchedulerConfig struct {
+	Enabled  bool          `yaml:"enabled" env:"SCHEDULER_ENABLED"`
+	Interval time.Duration `yaml:"interval" env:"SCHEDULER_INTERVAL" validate:"min=1m"`
+}
+
+// TracingConfig selects where OpenTelemetry spans are exported. The OTLP
+// exporter also honours the standard OTEL_EXPORTER_OTLP_* variables.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" validate:"oneof=none stdout otlp"`
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" validate:"omitempty,url"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME" validate:"required"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" validate:"min=0,max=1"`
+}
 
+// LogConfig controls the application logger. Outputs are zap sink URLs:
+// stdout, stderr or a file path. Sampling keeps the first Initial entries
+// with the same level and message each second, then every Thereafter-th;
+// an Initial of 0 disables sampling.
+type LogConfig struct {
+	Level    string            `yaml:"level" env:"LOG_LEVEL" validate:"oneof=debug info warn error"`
+	Format   string            `yaml:"format" env:"LOG_FORMAT" validate:"oneof=json console"`
+	Outputs  []string          `yaml:"outputs" env:"LOG_OUTPUTS" validate:"min=1"`
+	Sampling LogSamplingConfig `yaml:"sampling"`
+}
+
+type LogSamplingConfig struct {
+	Initial    int `yaml:"initial" env:"LOG_SAMPLING_INITIAL" validate:"min=0"`
+	Thereafter int `yaml:"thereafter" env:"LOG_SAMPLING_THEREAFTER" validate:"min=0"`
+}
+
+// RateLimitConfig sets the request budgets. Every client IP gets Default on
+// the API and Auth on the /auth routes; signed-in users also get User,
+// wherever they call from.
+type RateLimitConfig struct {
+	Enabled bool            `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	Default RateLimitBudget `yaml:"default" env:"RATE_LIMIT"`
+	Auth    RateLimitBudget `yaml:"auth" env:"RATE_LIMIT_AUTH"`
+	User    RateLimitBudget `yaml:"user" env:"RATE_LIMIT_USER"`
+}
+
+// RateLimitBudget allows Requests per Period on average, with bursts of up
+// to Burst requests
+type RateLimitBudget struct {
+	Requests int           `yaml:"requests" env:"REQUESTS" validate:"min=1"`
+	Period   time.Duration `yaml:"period" env:"PERIOD" validate:"min=1s"`
+	Burst    int           `yaml:"burst" env:"BURST" validate:"min=1"`
+}
+
+// ENV is the loaded configuration. It is empty until Load is called.
+var ENV Config
+
+// Default returns the built-in configuration. It only holds values that are
+// safe for local development; credentials must come from a file or the
+// environment.
+func Default() Config {
 	return Config{
-		ServerPort:         getEnv("PORT", "8080"),
-		DBPort:             getEnv("DB_PORT", "5432"),
-		DBHost:             getEnv("DB_HOST", "localhost"),
-		DBUser:             getEnv("DB_USER", "alexanderdomakyaareh"),
-		DBPassword:         getEnv("DB_PASSWORD", "mypassword"),
-		DBName:             getEnv("DB_NAME", "postgres"),
-		JWTKey:             getEnv("JWT_KEY", "someJWTKey"),
-		SMTPUsername:       getEnv("SMTP_USERNAME", "someEmail"),
-		SMTPPassword:       getEnv("SMTP_PASSWORD", "somePassword"),
-		SMTPHost:           getEnv("SMTP_HOST", "smtp.emailprovider.com"),
-		SMTPAddress:        getEnv("SMTP_ADDR", "smtp.gmail.com:587"),
-		SMTPPort:           getEnv("SMTP_PORT", "587"),
-		GoogleClientID:     getEnv("GOOGLE_CLIENT_ID", "some-client-id"),
-		GoogleClientSecret: getEnv("GOOGLE_CLIENT_SECRET", "some-client-secret"),
-		RabbitUser:         getEnv("RABBIT_USER", "guest"),
-		RabbitPassword:     getEnv("RABBIT_PASS", "guest"),
-		RabbitHost:         getEnv("RABBIT_HOST", "localhost"),
-		RabbitPort:         getEnv("RABBIT_PORT", "5672"),
+		Profile: "development",
+		Server: ServerConfig{
+			Port:             "8080",
+			FrontendURL:      "http://localhost:5173",
+			OAuthCallbackURL: "http://localhost:8080/api/v1/auth",
+			HSTSMaxAge:       365 * 24 * time.Hour,
+			SecureCookies:    true,
+			ReadTimeout:      15 * time.Second,
+			WriteTimeout:     30 * time.Second,
+			IdleTimeout:      time.Minute,
+			ShutdownTimeout:  20 * time.Second,
+		},
+		DB: DBConfig{
+			Host:    "localhost",
+			Port:    "5432",
+			Name:    "postgres",
+			SSLMode: "disable",
+		},
+		Auth: AuthConfig{
+			JWT: JWTConfig{
+				KeysDir:  "./keys",
+				Issuer:   "st-okr",
+				Audience: "st-okr-api",
+			},
+		},
+		SMTP: SMTPConfig{
+			Port: "587",
+		},
+		Rabbit: RabbitConfig{
+			Host: "localhost",
+			Port: "5672",
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:  true,
+			Interval: time.Hour,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "st-okr-api",
+			SampleRatio: 1,
+		},
+		Log: LogConfig{
+			Level:   "info",
+			Format:  "json",
+			Outputs: []string{"stderr"},
+			Sampling: LogSamplingConfig{
+				Initial:    100,
+				Thereafter: 100,
+			},
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Default: RateLimitBudget{Requests: 300, Period: time.Minute, Burst: 100},
+			Auth:    RateLimitBudget{Requests: 20, Period: time.Minute, Burst: 10},
+			User:    RateLimitBudget{Requests: 600, Period: time.Minute, Burst: 200},
+		},
+	}
+}
+
+// DSN returns the Postgres connection string
+func (c DBConfig) DSN() string {
+	u := url.URL{
+		Scheme:   "postgresql",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     c.Host + ":" + c.Port,
+		Path:     "/" + c.Name,
+		RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
 	}
+	return u.String()
+}
+
+// URL returns the AMQP connection URL
+func (c RabbitConfig) URL() string {
+	u := url.URL{
+		Scheme: "amqp",
+		User:   url.UserPassword(c.User, c.Password),
+		Host:   c.Host + ":" + c.Port,
+		Path:   "/",
+	}
+	return u.String()
+}
+
+// Address returns the SMTP server address
+func (c SMTPConfig) Address() string {
+	return fmt.Sprintf("%s:%s", c.Host, c.Port)
+}
+
+// Sender returns the From address, falling back to the SMTP username
+func (c SMTPConfig) Sender() string {
+	if c.From != "" {
+		return c.From
+	}
+	return c.Username
+}
+
+// AllowedFrontendOrigins returns the origins allowed to receive login
+// redirects: the origin of FrontendURL plus FrontendOrigins
+func (c ServerConfig) AllowedFrontendOrigins() []string {
+	return origins(append([]string{c.FrontendURL}, c.FrontendOrigins...))
+}
+
+// AllowedCORSOrigins returns the origins browsers may call the API from:
+// the frontend origins plus CORSOrigins
+func (c ServerConfig) AllowedCORSOrigins() []string {
+	return append(c.AllowedFrontendOrigins(), origins(c.CORSOrigins)...)
 }
 
-func getEnv(key string, fallback string) string {
-	if value, ok := os.LookupEnv(key); ok {
-		return value
+// origins reduces URLs to their scheme and host, skipping invalid ones
+func origins(urls []string) []string {
+	var result []string
+	for _, raw := range urls {
+		u, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			continue
+		}
+		result = append(result, u.Scheme+"://"+u.Host)
 	}
-	return fallback
+	return result
 }
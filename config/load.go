@@ -0,0 +1,196 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultConfigFile = "config.yaml"
+	profileEnv        = "APP_ENV"
+	configFileEnv     = "CONFIG_FILE"
+)
+
+// Load builds the configuration and stores it in ENV. Sources are applied in
+// order of increasing precedence:
+//
+//  1. built-in defaults
+//  2. the config file (config.yaml, or -config / CONFIG_FILE)
+//  3. the profile file next to it (config.<profile>.yaml), where the profile
+//     comes from -profile or APP_ENV and defaults to "development"
+//  4. environment variables, including those in .env
+//  5. command line flags
+//
+// The result is validated and Load returns every problem found.
+func Load(args []string) (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load .env: %w", err)
+	}
+
+	flags := flag.NewFlagSet("st-okr-api", flag.ContinueOnError)
+	configFile := flags.String("config", getEnv(configFileEnv, defaultConfigFile), "path to the YAML config file")
+	profile := flags.String("profile", getEnv(profileEnv, "development"), "configuration profile, e.g. development or production")
+	port := flags.String("port", "", "HTTP port, overrides server.port")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+	cfg.Profile = *profile
+
+	// The default file is optional; an explicitly chosen one is not
+	explicit := isFlagSet(flags, "config") || os.Getenv(configFileEnv) != ""
+	if err := loadFile(&cfg, *configFile, explicit); err != nil {
+		return nil, err
+	}
+	if err := loadFile(&cfg, profileFile(*configFile, cfg.Profile), false); err != nil {
+		return nil, err
+	}
+
+	if err := applyEnv(reflect.ValueOf(&cfg).Elem(), ""); err != nil {
+		return nil, err
+	}
+
+	if *port != "" {
+		cfg.Server.Port = *port
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	ENV = cfg
+	return &cfg, nil
+}
+
+// profileFile returns the profile overlay path, e.g. config.production.yaml
+func profileFile(base, profile string) string {
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "." + profile + ext
+}
+
+func loadFile(cfg *Config, path string, required bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !required {
+			return nil
+		}
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv overrides fields from their env tags. A struct's env tag is a
+// prefix for its fields, so auth.google.client_id reads GOOGLE_CLIENT_ID.
+func applyEnv(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := envName(prefix, field)
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := applyEnv(v.Field(i), name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if name == "" {
+			continue
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setValue(v.Field(i), value); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func envName(prefix string, field reflect.StructField) string {
+	tag := field.Tag.Get("env")
+	if tag == "" || prefix == "" {
+		return tag
+	}
+	return prefix + "_" + tag
+}
+
+func setValue(field reflect.Value, value string) error {
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(value)
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+	case []string:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func getEnv(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
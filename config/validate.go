@@ -0,0 +1,133 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Validate checks the configuration and reports every invalid setting with
+// its config file key and environment variable
+func (c Config) Validate() error {
+	var problems []string
+
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	if err := validate.Struct(c); err != nil {
+		var validationErrs validator.ValidationErrors
+		if !errors.As(err, &validationErrs) {
+			return err
+		}
+
+		envNames := map[string]string{}
+		collectEnvNames(reflect.TypeOf(c), "", "", envNames)
+
+		for _, fieldErr := range validationErrs {
+			key := fieldErr.Namespace()
+			key = key[strings.Index(key, ".")+1:]
+			// dive errors carry an index, e.g. server.frontend_origins[0]
+			base, _, _ := strings.Cut(key, "[")
+			problems = append(problems, fmt.Sprintf("%s (%s): %s", key, envNames[base], describe(fieldErr)))
+		}
+	}
+
+	problems = append(problems, c.profileProblems()...)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration for profile %q:\n  - %s", c.Profile, strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// profileProblems applies the stricter rules of the production profile
+func (c Config) profileProblems() []string {
+	if c.Profile != "production" {
+		return nil
+	}
+
+	var problems []string
+	if u, err := url.Parse(c.Server.FrontendURL); err == nil && u.Scheme != "https" {
+		problems = append(problems, "server.frontend_url (FRONTEND_URL): must use https in production")
+	}
+	if c.DB.Password == "" {
+		problems = append(problems, "db.password (DB_PASSWORD): is required in production")
+	}
+	if c.DB.SSLMode == "disable" {
+		problems = append(problems, "db.sslmode (DB_SSLMODE): must not be disable in production")
+	}
+	if c.SMTP.Host == "" || c.SMTP.Username == "" {
+		problems = append(problems, "smtp.host (SMTP_HOST) and smtp.username (SMTP_USERNAME): are required in production")
+	}
+	if c.Rabbit.User == "guest" {
+		problems = append(problems, "rabbit.user (RABBIT_USER): the guest account must not be used in production")
+	}
+	return problems
+}
+
+func describe(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "required_with":
+		return fmt.Sprintf("is required when %s is set", snakeCase(fieldErr.Param()))
+	case "numeric":
+		return fmt.Sprintf("must be a number, got %q", fieldErr.Value())
+	case "url":
+		return fmt.Sprintf("must be an absolute URL, got %q", fieldErr.Value())
+	case "email":
+		return fmt.Sprintf("must be an email address, got %q", fieldErr.Value())
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s], got %q", fieldErr.Param(), fieldErr.Value())
+	case "min":
+		return fmt.Sprintf("must be at least %s", fieldErr.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fieldErr.Param())
+	default:
+		return fmt.Sprintf("failed %q validation", fieldErr.Tag())
+	}
+}
+
+// collectEnvNames maps config keys such as "db.user" to their env variables
+func collectEnvNames(t reflect.Type, keyPrefix, envPrefix string, names map[string]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if key == "-" {
+			continue
+		}
+		if keyPrefix != "" {
+			key = keyPrefix + "." + key
+		}
+		env := envName(envPrefix, field)
+
+		if field.Type.Kind() == reflect.Struct {
+			collectEnvNames(field.Type, key, env, names)
+			continue
+		}
+		names[key] = env
+	}
+}
+
+// snakeCase turns a Go field name such as ClientID into client_id
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) && i > 0 && unicode.IsLower(rune(name[i-1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
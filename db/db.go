@@ -2,23 +2,43 @@ package db
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/Slightly-Techie/st-okr-api/config"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
 	"github.com/Slightly-Techie/st-okr-api/internal/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 )
 
+// InitDB connects to the database and migrates its schema
 func InitDB() (*gorm.DB, error) {
+	db, err := Open()
+	if err != nil {
+		return nil, err
+	}
+
+	// Run DB Migrations
+	err = db.AutoMigrate(&models.User{}, &models.UserIdentity{}, &models.AuthToken{}, &models.Company{}, &models.CompanySSOConfig{}, &models.CompanyDomain{}, &models.Membership{}, &models.Team{}, &models.TeamMember{}, &models.Objective{}, &models.KeyResult{}, &models.ProgressSnapshot{}, &models.ExchangeRate{}, &models.Cycle{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	logger.Info("Connected to database and migrations applied")
+	return db, nil
+}
+
+// Open connects to the database without touching its schema
+func Open() (*gorm.DB, error) {
 	// Create the DB Connection String from the config
-	dsn := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s", config.ENV.DBUser, config.ENV.DBPassword, config.ENV.DBHost, config.ENV.DBPort, config.ENV.DBName)
+	dsn := config.ENV.DB.DSN()
 
 	db, err := gorm.Open(postgres.New(postgres.Config{
 		DSN:                  dsn,
 		PreferSimpleProtocol: true,
 	}), &gorm.Config{
+		// Report unique violations as gorm.ErrDuplicatedKey
+		TranslateError: true,
 		NamingStrategy: schema.NamingStrategy{
 			SingularTable: false,
 		},
@@ -27,13 +47,14 @@ func InitDB() (*gorm.DB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	return db, nil
+}
 
-	// Run DB Migrations
-	err = db.AutoMigrate(&models.User{}, &models.Company{}, &models.Membership{}, &models.Team{}, &models.TeamMember{}, &models.Objective{}, &models.KeyResult{})
+// Close closes the connection pool
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
 	if err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
+		return fmt.Errorf("failed to get database handle: %w", err)
 	}
-
-	log.Print("Connected to database and migrations applied")
-	return db, nil
+	return sqlDB.Close()
 }
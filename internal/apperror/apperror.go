@@ -0,0 +1,80 @@
+package apperror
+
+import "errors"
+
+// Kind classifies an error by how the client should react to it
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindDatabase
+	KindBadRequest
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+)
+
+// Error is a domain error whose message is safe to show to clients.
+// Repositories and services declare them as sentinels and wrap them with
+// fmt.Errorf("%w: ...") to add internal detail.
+type Error struct {
+	Kind    Kind
+	Message string
+	// Details holds per-field messages for validation errors
+	Details map[string]string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// New creates an error of the given kind
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// NotFound creates an error for a missing resource
+func NotFound(message string) *Error {
+	return New(KindNotFound, message)
+}
+
+// Conflict creates an error for a request that clashes with existing state
+func Conflict(message string) *Error {
+	return New(KindConflict, message)
+}
+
+// BadRequest creates an error for a request that cannot be processed as sent
+func BadRequest(message string) *Error {
+	return New(KindBadRequest, message)
+}
+
+// Validation creates an error for invalid input with per-field details
+func Validation(message string, details map[string]string) *Error {
+	return &Error{Kind: KindValidation, Message: message, Details: details}
+}
+
+// Unauthorized creates an error for missing or invalid credentials
+func Unauthorized(message string) *Error {
+	return New(KindUnauthorized, message)
+}
+
+// Forbidden creates an error for an authenticated caller lacking permission
+func Forbidden(message string) *Error {
+	return New(KindForbidden, message)
+}
+
+// Database creates an error for a failed database operation
+func Database(message string) *Error {
+	return New(KindDatabase, message)
+}
+
+// As returns the first *Error in err's chain
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
@@ -1,15 +1,17 @@
 package controllers
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
-	"github.com/Slightly-Techie/st-okr-api/config"
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
 	"github.com/Slightly-Techie/st-okr-api/internal/logger"
 	"github.com/Slightly-Techie/st-okr-api/internal/response"
 	"github.com/Slightly-Techie/st-okr-api/internal/services"
+	auth "github.com/Slightly-Techie/st-okr-api/pkg"
 	"github.com/gin-gonic/gin"
+	"github.com/markbates/goth/gothic"
 )
 
 type AuthController struct {
@@ -17,13 +19,6 @@ type AuthController struct {
 }
 
 // Helper functions for logging
-func getRequestID(c *gin.Context) string {
-	if reqID, exists := c.Get("request_id"); exists {
-		return reqID.(string)
-	}
-	return "unknown"
-}
-
 func getUserID(c *gin.Context) string {
 	if userID, exists := c.Get("user_id"); exists {
 		return userID.(string)
@@ -63,21 +58,16 @@ func NewAuthController(authService services.AuthService) *AuthController {
 
 func (ctrl *AuthController) ContinueWithOAuth(c *gin.Context) {
 	provider := c.Param("provider")
-	requestID := getRequestID(c)
-	remoteIP := c.ClientIP()
+	log := logger.FromContext(c.Request.Context())
 	userAgent := c.Request.UserAgent()
 
-	logger.Info("OAuth authentication initiated",
-		"request_id", requestID,
+	log.Info("OAuth authentication initiated",
 		"provider", provider,
-		"remote_ip", remoteIP,
 		"user_agent", userAgent,
 	)
 
 	if provider == "" {
-		logger.Error("OAuth authentication failed - missing provider",
-			"request_id", requestID,
-			"remote_ip", remoteIP,
+		log.Error("OAuth authentication failed - missing provider",
 			"error", "provider parameter is empty",
 		)
 		response.BadRequest(c, "OAuth provider not specified", map[string]string{
@@ -86,34 +76,62 @@ func (ctrl *AuthController) ContinueWithOAuth(c *gin.Context) {
 		return
 	}
 
-	logger.Debug("Delegating to auth service",
-		"request_id", requestID,
-		"provider", provider,
-	)
+	state, _, err := auth.GenerateToken()
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if !beginBrowserLogin(c, state) {
+		return
+	}
+	if linkToken := c.Query("link_token"); linkToken != "" && !bindIdentityLink(c, state, linkToken) {
+		log.Warn("OAuth link rejected - link token was not issued to this browser",
+			"provider", provider,
+		)
+		response.BadRequest(c, "link_token was not issued to this browser", map[string]string{
+			"link_token": "Start the link with POST /api/v1/auth/identities/link from this browser",
+		})
+		return
+	}
 
-	ctrl.authService.AuthHandler(provider, c)
+	// gothic reads the provider and, when present, the state from the query
+	q := c.Request.URL.Query()
+	q.Set("state", state)
+	q.Set("provider", provider)
+	c.Request.URL.RawQuery = q.Encode()
+
+	gothic.BeginAuthHandler(c.Writer, c.Request)
 }
 
 func (ctrl *AuthController) GetOAuthCallback(c *gin.Context) {
 	provider := c.Param("provider")
-	requestID := getRequestID(c)
-	remoteIP := c.ClientIP()
+	log := logger.FromContext(c.Request.Context())
 
-	logger.Info("OAuth callback received",
-		"request_id", requestID,
+	log.Info("OAuth callback received",
 		"provider", provider,
-		"remote_ip", remoteIP,
 	)
 
-	authRes, err := ctrl.authService.GetAuthCallback(provider, c)
+	q := c.Request.URL.Query()
+	q.Set("provider", provider)
+	c.Request.URL.RawQuery = q.Encode()
+
+	authRes, err := ctrl.completeOAuthLogin(c, provider)
 	if err != nil {
-		logger.Error("OAuth authentication failed during callback",
-			"request_id", requestID,
+		log.Error("OAuth authentication failed during callback",
 			"provider", provider,
-			"remote_ip", remoteIP,
 			"error", err.Error(),
 		)
-		response.InternalError(c, "Authentication failed")
+
+		redirectURI, _ := loginRedirectURI(c, c.Query("state"))
+		if errors.Is(err, services.ErrSSORequired) {
+			failBrowserLogin(c, redirectURI, "sso_required", err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrAccountLinkRequired) || errors.Is(err, services.ErrIdentityLinkedElsewhere) {
+			failBrowserLogin(c, redirectURI, "account_link_required", err.Error())
+			return
+		}
+		failBrowserLogin(c, redirectURI, "access_denied", "Authentication failed")
 		return
 	}
 
@@ -125,84 +143,266 @@ func (ctrl *AuthController) GetOAuthCallback(c *gin.Context) {
 		userEmail = authRes.Email
 	}
 
-	logger.Info("OAuth authentication successful",
-		"request_id", requestID,
+	log.Info("OAuth authentication successful",
 		"provider", provider,
-		"remote_ip", remoteIP,
 		"user_id", userID,
 		"user_email", maskEmail(userEmail),
 	)
 
-	userData, err := json.Marshal(authRes)
+	completeBrowserLogin(c, ctrl.authService, c.Query("state"), authRes)
+}
+
+// completeOAuthLogin finishes the provider handshake and signs the user in,
+// first linking the provider to the user who started the login to link it
+func (ctrl *AuthController) completeOAuthLogin(c *gin.Context, provider string) (*dto.AuthResponse, error) {
+	gothUser, err := gothic.CompleteUserAuth(c.Writer, c.Request)
 	if err != nil {
-		logger.Error("Failed to marshal user data",
-			"request_id", requestID,
-			"provider", provider,
-			"user_id", userID,
-			"error", err.Error(),
-		)
-		c.Redirect(http.StatusTemporaryRedirect, fmt.Sprintf("%s/auth/error?message=failed to process user data", config.ENV.DBHost))
-		return
+		return nil, fmt.Errorf("failed to complete user auth: %w", err)
 	}
-
-	logger.Debug("Setting authentication cookie",
-		"request_id", requestID,
-		"user_id", userID,
-		"domain", config.ENV.DBHost,
-	)
-
-	c.SetCookie(
-		"user_data",       // cookie name
-		string(userData),  // cookie value
-		3600*24,           // expiration time (24 hours)
-		"/",               // path
-		config.ENV.DBHost, // domain
-		true,              // secure
-		true,              // httpOnly
-	)
-
-	logger.Info("User redirected to dashboard",
-		"request_id", requestID,
-		"user_id", userID,
-		"redirect_url", "http://localhost:5173/dashboard",
-	)
-
-	// Replace the url with the actual url
-	c.Redirect(http.StatusTemporaryRedirect, "http://localhost:5173/dashboard")
+	if linkToken, ok := identityLinkToken(c, c.Query("state")); ok {
+		return ctrl.authService.LinkOAuthIdentity(c.Request.Context(), linkToken, provider, gothUser)
+	}
+	return ctrl.authService.CompleteOAuthLogin(c.Request.Context(), provider, gothUser)
 }
 
 func (ctrl *AuthController) LogoutWithOAuth(c *gin.Context) {
 	provider := c.Param("provider")
-	requestID := getRequestID(c)
-	remoteIP := c.ClientIP()
+	log := logger.FromContext(c.Request.Context())
 	userID := getUserID(c)
 
-	logger.Info("User logout initiated",
-		"request_id", requestID,
+	log.Info("User logout initiated",
 		"provider", provider,
-		"remote_ip", remoteIP,
 		"user_id", userID,
 	)
 
-	err := ctrl.authService.Logout(provider, c)
+	q := c.Request.URL.Query()
+	q.Set("provider", provider)
+	c.Request.URL.RawQuery = q.Encode()
+
+	err := gothic.Logout(c.Writer, c.Request)
 	if err != nil {
-		logger.Error("Logout failed",
-			"request_id", requestID,
-			"provider", provider,
-			"user_id", userID,
-			"remote_ip", remoteIP,
-			"error", err.Error(),
-		)
-		response.InternalError(c, "Logout failed")
+		response.HandleError(c, err)
 		return
 	}
 
-	logger.Info("User logout successful",
-		"request_id", requestID,
+	log.Info("User logout successful",
 		"provider", provider,
 		"user_id", userID,
-		"remote_ip", remoteIP,
 	)
 
 	c.Redirect(http.StatusTemporaryRedirect, "/")
 }
+
+func (ctrl *AuthController) ListIdentities(c *gin.Context) {
+	userID := getUserID(c)
+
+	identities, err := ctrl.authService.ListIdentities(c.Request.Context(), userID)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, identities, "Linked accounts retrieved successfully")
+}
+
+// StartIdentityLink issues the token that links the next provider login to
+// the signed-in user. The token is also set in a cookie, and only a browser
+// holding it can start the link, so the request must be sent with
+// credentials.
+func (ctrl *AuthController) StartIdentityLink(c *gin.Context) {
+	token, err := ctrl.authService.IssueIdentityLinkToken(c.Request.Context(), getUserID(c))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	setLoginCookie(c, identityLinkRequestCookie, token, loginRedirectCookiePath, int(services.IdentityLinkTTL.Seconds()))
+
+	response.OK(c, dto.IdentityLinkResponse{
+		LinkToken: token,
+		ExpiresIn: int64(services.IdentityLinkTTL.Seconds()),
+	}, "Sign in with the provider to link it")
+}
+
+func (ctrl *AuthController) UnlinkIdentity(c *gin.Context) {
+	userID := getUserID(c)
+	identityID := c.Param("id")
+
+	err := ctrl.authService.UnlinkIdentity(c.Request.Context(), userID, identityID)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, nil, "Account unlinked successfully")
+}
+
+func (ctrl *AuthController) Register(c *gin.Context) {
+	var req dto.RegisterRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	if err := ctrl.authService.Register(c.Request.Context(), req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Created(c, nil, "Account created. Check your email to verify your address")
+}
+
+func (ctrl *AuthController) Login(c *gin.Context) {
+	var req dto.LoginRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	authRes, err := ctrl.authService.Login(c.Request.Context(), req)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, authRes, "Login successful")
+}
+
+func (ctrl *AuthController) VerifyEmail(c *gin.Context) {
+	var req dto.TokenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	if err := ctrl.authService.VerifyEmail(c.Request.Context(), req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, nil, "Email verified successfully")
+}
+
+func (ctrl *AuthController) ResendVerification(c *gin.Context) {
+	var req dto.EmailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	if err := ctrl.authService.ResendVerification(c.Request.Context(), req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, nil, "If the account exists and is unverified, a verification email has been sent")
+}
+
+func (ctrl *AuthController) ForgotPassword(c *gin.Context) {
+	var req dto.EmailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	if err := ctrl.authService.RequestPasswordReset(c.Request.Context(), req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, nil, "If the account exists, a password reset email has been sent")
+}
+
+func (ctrl *AuthController) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	if err := ctrl.authService.ResetPassword(c.Request.Context(), req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, nil, "Password updated successfully")
+}
+
+func (ctrl *AuthController) RequestMagicLink(c *gin.Context) {
+	var req dto.EmailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	if err := ctrl.authService.RequestMagicLink(c.Request.Context(), req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, nil, "If the account exists, a sign-in link has been sent")
+}
+
+func (ctrl *AuthController) VerifyMagicLink(c *gin.Context) {
+	var req dto.TokenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	authRes, err := ctrl.authService.LoginWithMagicLink(c.Request.Context(), req)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, authRes, "Login successful")
+}
+
+func (ctrl *AuthController) RefreshToken(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	authRes, err := ctrl.authService.Refresh(c.Request.Context(), req)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, authRes, "Token refreshed successfully")
+}
+
+// JWKS publishes the token verification keys for other services
+func (ctrl *AuthController) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, auth.Keys().JWKS())
+}
+
+// ExchangeCode trades the one-time code from a browser login redirect for tokens
+func (ctrl *AuthController) ExchangeCode(c *gin.Context) {
+	var req dto.AuthorizationCodeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	authRes, err := ctrl.authService.ExchangeAuthorizationCode(c.Request.Context(), req)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, authRes, "Login successful")
+}
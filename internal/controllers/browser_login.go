@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/Slightly-Techie/st-okr-api/config"
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
+	"github.com/Slightly-Techie/st-okr-api/internal/response"
+	"github.com/Slightly-Techie/st-okr-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	loginRedirectCookie       = "login_redirect"
+	identityLinkCookie        = "identity_link"
+	identityLinkRequestCookie = "identity_link_request"
+	loginRedirectCookiePath   = "/api/v1/auth"
+	loginRedirectMaxAge       = 600
+	defaultLoginRedirect      = "/auth/callback"
+)
+
+// defaultRedirectURI is where the frontend receives the authorization code
+// when the login did not ask for a specific redirect_uri
+func defaultRedirectURI() string {
+	return strings.TrimSuffix(config.ENV.Server.FrontendURL, "/") + defaultLoginRedirect
+}
+
+// allowedRedirectURI reports whether uri points at one of the configured
+// frontend origins
+func allowedRedirectURI(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" || u.Host == "" || u.User != nil || u.Fragment != "" {
+		return false
+	}
+	return slices.Contains(config.ENV.Server.AllowedFrontendOrigins(), u.Scheme+"://"+u.Host)
+}
+
+// beginBrowserLogin validates the redirect_uri query parameter and binds it to
+// the login state in a short-lived cookie. It writes a 400 response and returns
+// false when the redirect_uri is not allowed.
+func beginBrowserLogin(c *gin.Context, state string) bool {
+	log := logger.FromContext(c.Request.Context())
+
+	redirectURI := c.Query("redirect_uri")
+	if redirectURI == "" {
+		redirectURI = defaultRedirectURI()
+	}
+
+	if !allowedRedirectURI(redirectURI) {
+		log.Warn("Login rejected - redirect_uri not allowed",
+			"redirect_uri", redirectURI,
+		)
+		response.BadRequest(c, "redirect_uri is not allowed", map[string]string{
+			"redirect_uri": "Must point to a configured frontend origin",
+		})
+		return false
+	}
+
+	setLoginCookie(c, loginRedirectCookie, state+" "+redirectURI, loginRedirectCookiePath, loginRedirectMaxAge)
+	return true
+}
+
+// setLoginCookie sets or, with a negative maxAge, clears a cookie of a
+// browser login. SAML responses arrive as a cross-site POST, so login
+// cookies are SameSite=None. Whether they are Secure is configured rather
+// than read from the request, which arrives over plain HTTP behind a
+// TLS-terminating proxy.
+func setLoginCookie(c *gin.Context, name, value, path string, maxAge int) {
+	c.SetSameSite(http.SameSiteNoneMode)
+	c.SetCookie(name, value, maxAge, path, "", config.ENV.Server.SecureCookies, true)
+}
+
+// loginRedirectURI returns the redirect_uri bound to state and clears the
+// cookie. Logins without the cookie (e.g. IdP-initiated SAML) fall back to the
+// default redirect; a cookie bound to a different state is rejected.
+func loginRedirectURI(c *gin.Context, state string) (string, bool) {
+	value, err := c.Cookie(loginRedirectCookie)
+	if err != nil || value == "" {
+		return defaultRedirectURI(), true
+	}
+
+	setLoginCookie(c, loginRedirectCookie, "", loginRedirectCookiePath, -1)
+
+	boundState, redirectURI, found := strings.Cut(value, " ")
+	if !found || state == "" || subtle.ConstantTimeCompare([]byte(boundState), []byte(state)) != 1 {
+		return defaultRedirectURI(), false
+	}
+	if !allowedRedirectURI(redirectURI) {
+		return defaultRedirectURI(), false
+	}
+	return redirectURI, true
+}
+
+// bindIdentityLink checks that linkToken was issued to this browser by
+// StartIdentityLink, so a link token cannot be planted in someone else's
+// browser to link their provider login to another account, and binds it to
+// the login state
+func bindIdentityLink(c *gin.Context, state, linkToken string) bool {
+	issued, err := c.Cookie(identityLinkRequestCookie)
+	if err != nil || issued == "" || subtle.ConstantTimeCompare([]byte(issued), []byte(linkToken)) != 1 {
+		return false
+	}
+	setLoginCookie(c, identityLinkRequestCookie, "", loginRedirectCookiePath, -1)
+	setLoginCookie(c, identityLinkCookie, state+" "+linkToken, loginRedirectCookiePath, loginRedirectMaxAge)
+	return true
+}
+
+// identityLinkToken returns the token of a provider link bound to state and
+// clears its cookie
+func identityLinkToken(c *gin.Context, state string) (string, bool) {
+	value, err := c.Cookie(identityLinkCookie)
+	if err != nil || value == "" {
+		return "", false
+	}
+	setLoginCookie(c, identityLinkCookie, "", loginRedirectCookiePath, -1)
+
+	boundState, token, found := strings.Cut(value, " ")
+	if !found || state == "" || subtle.ConstantTimeCompare([]byte(boundState), []byte(state)) != 1 {
+		return "", false
+	}
+	return token, true
+}
+
+// redirectWithParams sends the browser to redirectURI with params added to its query
+func redirectWithParams(c *gin.Context, redirectURI string, params map[string]string) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		u, _ = url.Parse(defaultRedirectURI())
+	}
+
+	q := u.Query()
+	for key, value := range params {
+		q.Set(key, value)
+	}
+	u.RawQuery = q.Encode()
+
+	c.Redirect(http.StatusFound, u.String())
+}
+
+// completeBrowserLogin hands the signed-in user to the frontend after a
+// redirect-based login (OAuth or SSO). The frontend receives a one-time
+// authorization code and exchanges it at POST /auth/token.
+func completeBrowserLogin(c *gin.Context, authService services.AuthService, state string, authRes *dto.AuthResponse) {
+	log := logger.FromContext(c.Request.Context())
+
+	redirectURI, ok := loginRedirectURI(c, state)
+	if !ok {
+		log.Warn("Login state does not match redirect binding",
+			"user_id", authRes.ID,
+		)
+		failBrowserLogin(c, redirectURI, "invalid_state", "Login state is invalid or has expired")
+		return
+	}
+
+	code, err := authService.IssueAuthorizationCode(c.Request.Context(), authRes.ID)
+	if err != nil {
+		log.Error("Failed to issue authorization code",
+			"user_id", authRes.ID,
+			"error", err.Error(),
+		)
+		failBrowserLogin(c, redirectURI, "server_error", "Failed to complete login")
+		return
+	}
+
+	log.Info("User redirected to frontend",
+		"user_id", authRes.ID,
+		"redirect_uri", redirectURI,
+	)
+
+	redirectWithParams(c, redirectURI, map[string]string{"code": code})
+}
+
+// failBrowserLogin sends the browser back to the frontend with an error
+func failBrowserLogin(c *gin.Context, redirectURI, code, description string) {
+	redirectWithParams(c, redirectURI, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
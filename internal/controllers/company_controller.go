@@ -1,8 +1,6 @@
 package controllers
 
 import (
-	"fmt"
-
 	"github.com/Slightly-Techie/st-okr-api/internal/dto"
 	"github.com/Slightly-Techie/st-okr-api/internal/response"
 	"github.com/Slightly-Techie/st-okr-api/internal/services"
@@ -32,9 +30,7 @@ func (ctrl *CompanyController) CreateCompany(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&body); err != nil {
-		response.ValidationError(c, "Invalid request data", map[string]string{
-			"request": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -43,13 +39,9 @@ func (ctrl *CompanyController) CreateCompany(c *gin.Context) {
 		CreatorId: userID.(string),
 	}
 
-	fmt.Println(reqBody)
-
-	data, err := ctrl.companyService.CreateCompany(reqBody)
+	data, err := ctrl.companyService.CreateCompany(c.Request.Context(), reqBody)
 	if err != nil {
-		response.BadRequest(c, "Failed to create company", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 	response.Created(c, data, "Company created successfully")
@@ -58,9 +50,9 @@ func (ctrl *CompanyController) CreateCompany(c *gin.Context) {
 func (ctrl *CompanyController) GetCompany(c *gin.Context) {
 	id := c.Param("id")
 
-	data, err := ctrl.companyService.GetCompany("id", id)
+	data, err := ctrl.companyService.GetCompany(c.Request.Context(), "id", id)
 	if err != nil {
-		response.NotFound(c, "Company not found")
+		response.HandleError(c, err)
 		return
 	}
 
@@ -71,29 +63,138 @@ func (ctrl *CompanyController) UpdateCompany(c *gin.Context) {
 	var body dto.CreateCompanyRequest
 
 	if err := c.ShouldBindJSON(&body); err != nil {
-		response.ValidationError(c, "Invalid request data", map[string]string{
-			"request": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
-	data, err := ctrl.companyService.UpdateCompany(body)
+	data, err := ctrl.companyService.UpdateCompany(c.Request.Context(), body)
 	if err != nil {
-		response.BadRequest(c, "Failed to update company", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 	response.OK(c, data, "Company updated successfully")
 }
 
+// GetStatusThresholds returns how far below expected progress key results
+// may fall before they are at risk or behind
+func (ctrl *CompanyController) GetStatusThresholds(c *gin.Context) {
+	thresholds, err := ctrl.companyService.GetStatusThresholds(c.Request.Context(), getUserID(c), c.Param("id"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, thresholds, "Status thresholds retrieved successfully")
+}
+
+func (ctrl *CompanyController) UpdateStatusThresholds(c *gin.Context) {
+	var body dto.StatusThresholdsRequest
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	thresholds, err := ctrl.companyService.UpdateStatusThresholds(c.Request.Context(), getUserID(c), c.Param("id"), body)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, thresholds, "Status thresholds updated successfully")
+}
+
+// GetCurrencySettings returns the company's reporting currency and the
+// exchange rates currency key results are converted at
+func (ctrl *CompanyController) GetCurrencySettings(c *gin.Context) {
+	settings, err := ctrl.companyService.GetCurrencySettings(c.Request.Context(), getUserID(c), c.Param("id"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, settings, "Currency settings retrieved successfully")
+}
+
+func (ctrl *CompanyController) UpdateReportingCurrency(c *gin.Context) {
+	var body dto.ReportingCurrencyRequest
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	settings, err := ctrl.companyService.UpdateReportingCurrency(c.Request.Context(), getUserID(c), c.Param("id"), body)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, settings, "Reporting currency updated successfully")
+}
+
+func (ctrl *CompanyController) SetExchangeRate(c *gin.Context) {
+	var body dto.ExchangeRateRequest
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	rate, err := ctrl.companyService.SetExchangeRate(c.Request.Context(), getUserID(c), c.Param("id"), body)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, rate, "Exchange rate saved successfully")
+}
+
+func (ctrl *CompanyController) DeleteExchangeRate(c *gin.Context) {
+	err := ctrl.companyService.DeleteExchangeRate(c.Request.Context(), getUserID(c), c.Param("id"), c.Param("from"), c.Param("to"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, nil, "Exchange rate deleted successfully")
+}
+
+func (ctrl *CompanyController) ListCycles(c *gin.Context) {
+	cycles, err := ctrl.companyService.ListCycles(c.Request.Context(), getUserID(c), c.Param("id"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, cycles, "Cycles retrieved successfully")
+}
+
+// CreateCycle names a period of the company's OKRs, which exports,
+// dashboards and imports can then refer to by name
+func (ctrl *CompanyController) CreateCycle(c *gin.Context) {
+	var body dto.CreateCycleRequest
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	cycle, err := ctrl.companyService.CreateCycle(c.Request.Context(), getUserID(c), c.Param("id"), body)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.Created(c, cycle, "Cycle created successfully")
+}
+
+func (ctrl *CompanyController) DeleteCycle(c *gin.Context) {
+	err := ctrl.companyService.DeleteCycle(c.Request.Context(), getUserID(c), c.Param("id"), c.Param("cycle_id"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, nil, "Cycle deleted successfully")
+}
+
 func (ctrl *CompanyController) DeleteCompany(c *gin.Context) {
 	id := c.Param("id")
-	err := ctrl.companyService.DeleteCompany(id)
+	err := ctrl.companyService.DeleteCompany(c.Request.Context(), id)
 	if err != nil {
-		response.BadRequest(c, "Failed to delete company", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 	response.OK(c, nil, "Company deleted successfully")
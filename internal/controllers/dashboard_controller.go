@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/response"
+	"github.com/Slightly-Techie/st-okr-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type DashboardController struct {
+	dashboardService services.DashboardService
+}
+
+func NewDashboardController(dashboardService services.DashboardService) *DashboardController {
+	return &DashboardController{
+		dashboardService: dashboardService,
+	}
+}
+
+// GetDashboard summarises a company's OKRs, optionally for one team or
+// cycle. at_risk_limit caps the at-risk key results listed.
+func (ctrl *DashboardController) GetDashboard(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Unauthorized request")
+		return
+	}
+
+	limit := 0
+	if value := c.Query("at_risk_limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			response.BadRequest(c, "at_risk_limit must be a positive number", nil)
+			return
+		}
+		limit = parsed
+	}
+
+	dashboard, err := ctrl.dashboardService.GetDashboard(c.Request.Context(), services.DashboardRequest{
+		CompanyID:   c.Param("id"),
+		UserID:      userID.(string),
+		Team:        c.Query("team"),
+		Cycle:       c.Query("cycle"),
+		AtRiskLimit: limit,
+	})
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, dashboard, "Dashboard retrieved successfully")
+}
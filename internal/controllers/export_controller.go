@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"github.com/Slightly-Techie/st-okr-api/internal/response"
+	"github.com/Slightly-Techie/st-okr-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ExportController struct {
+	exportService services.ExportService
+}
+
+func NewExportController(exportService services.ExportService) *ExportController {
+	return &ExportController{
+		exportService: exportService,
+	}
+}
+
+// ExportOKRs streams a company's objectives and key results as a CSV (the
+// default), XLSX or JSON file. team, cycle and status narrow the export;
+// status takes a comma-separated list.
+func (ctrl *ExportController) ExportOKRs(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Unauthorized request")
+		return
+	}
+
+	format := services.ExportFormatCSV
+	if name := c.Query("format"); name != "" {
+		parsed, ok := services.ParseExportFormat(name)
+		if !ok {
+			response.BadRequest(c, "Unsupported export format; use csv, xlsx or json", nil)
+			return
+		}
+		format = parsed
+	}
+
+	var statuses []models.ObjectiveStatus
+	for _, status := range strings.Split(c.Query("status"), ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			statuses = append(statuses, models.ObjectiveStatus(status))
+		}
+	}
+
+	companyID := c.Param("id")
+	w := &downloadWriter{
+		c:           c,
+		contentType: format.ContentType(),
+		filename:    fmt.Sprintf("okrs-%s-%s.%s", companyID, time.Now().UTC().Format("20060102"), format),
+	}
+	err := ctrl.exportService.ExportOKRs(c.Request.Context(), services.ExportRequest{
+		CompanyID: companyID,
+		UserID:    userID.(string),
+		Format:    format,
+		Team:      c.Query("team"),
+		Cycle:     c.Query("cycle"),
+		Statuses:  statuses,
+	}, w)
+	if err == nil {
+		return
+	}
+	if !w.started {
+		response.HandleError(c, err)
+		return
+	}
+
+	// The status line is gone; all that is left is to cut the file short
+	logger.FromContext(c.Request.Context()).Error("Export failed part way", "company_id", companyID, "error", err.Error())
+	c.Abort()
+}
+
+// downloadWriter sends the download headers with the first write, so a
+// request rejected before any output still gets a JSON error
+type downloadWriter struct {
+	c           *gin.Context
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (w *downloadWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.c.Header("Content-Type", w.contentType)
+		w.c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", w.filename))
+		w.c.Header("Cache-Control", "no-store")
+	}
+	return w.c.Writer.Write(p)
+}
+
+func (w *downloadWriter) Flush() {
+	if w.started {
+		w.c.Writer.Flush()
+	}
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/message"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const readinessCheckTimeout = 2 * time.Second
+
+// HealthCheck reports whether a dependency is usable
+type HealthCheck func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// DependencyStatus is the readiness result for one dependency
+type DependencyStatus struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type HealthController struct {
+	mu     sync.RWMutex
+	checks []namedCheck
+}
+
+// NewHealthController registers the Postgres and RabbitMQ publisher checks.
+// Other dependencies are added with Register once they are started.
+func NewHealthController(db *gorm.DB) *HealthController {
+	ctrl := &HealthController{}
+
+	ctrl.Register("postgres", func(ctx context.Context) error {
+		if db == nil {
+			return errors.New("database is not configured")
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+
+	ctrl.Register("rabbitmq_publisher", func(ctx context.Context) error {
+		return message.DefaultPublisher().Ping(ctx)
+	})
+
+	return ctrl
+}
+
+// Register adds a dependency to the readiness report
+func (ctrl *HealthController) Register(name string, check HealthCheck) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+
+	ctrl.checks = append(ctrl.checks, namedCheck{name: name, check: check})
+}
+
+// Liveness reports that the process is running. It checks no dependencies so
+// an outage elsewhere does not get the pod restarted.
+func (ctrl *HealthController) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness checks every dependency concurrently and returns 503 if any is down
+func (ctrl *HealthController) Readiness(c *gin.Context) {
+	ctrl.mu.RLock()
+	checks := append([]namedCheck(nil), ctrl.checks...)
+	ctrl.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessCheckTimeout)
+	defer cancel()
+
+	results := make([]DependencyStatus, len(checks))
+	var wg sync.WaitGroup
+	for i, nc := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := runCheck(ctx, nc.check)
+			results[i] = DependencyStatus{
+				Status:    "up",
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				results[i].Status = "down"
+				results[i].Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	status := "ok"
+	code := http.StatusOK
+	dependencies := make(map[string]DependencyStatus, len(checks))
+	for i, nc := range checks {
+		dependencies[nc.name] = results[i]
+		if results[i].Status != "up" {
+			status = "unavailable"
+			code = http.StatusServiceUnavailable
+		}
+	}
+
+	c.JSON(code, gin.H{
+		"status":       status,
+		"dependencies": dependencies,
+	})
+}
+
+// runCheck stops waiting at the deadline even if the check ignores ctx
+func runCheck(ctx context.Context, check HealthCheck) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
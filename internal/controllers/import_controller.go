@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/response"
+	"github.com/Slightly-Techie/st-okr-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize bounds the size of an import document
+const maxImportSize = 10 << 20
+
+type ImportController struct {
+	importService services.ImportService
+}
+
+func NewImportController(importService services.ImportService) *ImportController {
+	return &ImportController{
+		importService: importService,
+	}
+}
+
+// ImportOKRs creates the objectives and key results of a CSV, JSON or YAML
+// document. The format comes from the format query parameter or the
+// Content-Type header and defaults to JSON; dry_run=true only checks the
+// document.
+func (ctrl *ImportController) ImportOKRs(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Unauthorized request")
+		return
+	}
+
+	format := services.ImportFormatJSON
+	if name := c.Query("format"); name != "" {
+		parsed, ok := services.ParseImportFormat(name)
+		if !ok {
+			response.BadRequest(c, "Unsupported import format; use csv, json or yaml", nil)
+			return
+		}
+		format = parsed
+	} else if parsed, ok := services.ParseImportFormat(c.ContentType()); ok {
+		format = parsed
+	}
+
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			response.BadRequest(c, "dry_run must be true or false", nil)
+			return
+		}
+		dryRun = parsed
+	}
+
+	report, err := ctrl.importService.ImportOKRs(c.Request.Context(), services.ImportRequest{
+		CompanyID:  c.Param("id"),
+		ImporterID: userID.(string),
+		Format:     format,
+		Body:       http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize),
+		DryRun:     dryRun,
+		Language:   c.GetHeader("Accept-Language"),
+	})
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	if dryRun {
+		response.OK(c, report, "Import document checked")
+		return
+	}
+	response.Created(c, report, "OKRs imported successfully")
+}
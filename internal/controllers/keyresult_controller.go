@@ -21,17 +21,13 @@ func (kctrl *KeyResultController) CreateKeyResult(c *gin.Context) {
 	var req dto.CreateKeyResultRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, "Invalid request data", map[string]string{
-			"request": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
-	kr, err := kctrl.keyResultService.CreateKeyResult(req)
+	kr, err := kctrl.keyResultService.CreateKeyResult(c.Request.Context(), req)
 	if err != nil {
-		response.BadRequest(c, "Failed to create key result", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -41,9 +37,9 @@ func (kctrl *KeyResultController) CreateKeyResult(c *gin.Context) {
 func (kctrl *KeyResultController) GetKeyResult(c *gin.Context) {
 	id := c.Param("id")
 
-	kr, err := kctrl.keyResultService.GetData("id", id)
+	kr, err := kctrl.keyResultService.GetData(c.Request.Context(), "id", id)
 	if err != nil {
-		response.NotFound(c, "Key result not found")
+		response.HandleError(c, err)
 		return
 	}
 
@@ -53,9 +49,9 @@ func (kctrl *KeyResultController) GetKeyResult(c *gin.Context) {
 func (kctrl *KeyResultController) ListObjKeyResults(c *gin.Context) {
 	objID := c.Param("id")
 
-	kr, err := kctrl.keyResultService.ListData("objective_id", objID)
+	kr, err := kctrl.keyResultService.ListData(c.Request.Context(), "objective_id", objID)
 	if err != nil {
-		response.NotFound(c, "Key results not found for objective")
+		response.HandleError(c, err)
 		return
 	}
 
@@ -65,9 +61,9 @@ func (kctrl *KeyResultController) ListObjKeyResults(c *gin.Context) {
 func (kctrl *KeyResultController) ListAssigneeKeyResults(c *gin.Context) {
 	assigneeID := c.Param("id")
 
-	kr, err := kctrl.keyResultService.ListData("assignee_id", assigneeID)
+	kr, err := kctrl.keyResultService.ListData(c.Request.Context(), "assignee_id", assigneeID)
 	if err != nil {
-		response.NotFound(c, "Key results not found for assignee")
+		response.HandleError(c, err)
 		return
 	}
 
@@ -78,17 +74,13 @@ func (kctrl *KeyResultController) UpdateKeyResult(c *gin.Context) {
 	var req dto.UpdateKeyResultRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, "Invalid request data", map[string]string{
-			"request": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
-	kr, err := kctrl.keyResultService.UpdateKeyResult(req)
+	kr, err := kctrl.keyResultService.UpdateKeyResult(c.Request.Context(), req)
 	if err != nil {
-		response.BadRequest(c, "Failed to update key result", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -98,11 +90,9 @@ func (kctrl *KeyResultController) UpdateKeyResult(c *gin.Context) {
 func (kctrl *KeyResultController) DeleteKeyResult(c *gin.Context) {
 	id := c.Param("id")
 
-	err := kctrl.keyResultService.DeleteKeyResult(id)
+	err := kctrl.keyResultService.DeleteKeyResult(c.Request.Context(), id)
 	if err != nil {
-		response.BadRequest(c, "Failed to delete key result", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -22,17 +22,13 @@ func (ctrl *MembershipController) CreateMembership(c *gin.Context) {
 	var body dto.CreateMembershipRequest
 
 	if err := c.ShouldBindJSON(&body); err != nil {
-		response.ValidationError(c, "Invalid request data", map[string]string{
-			"request": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
-	data, err := ctrl.membershipService.CreateMembership(body)
+	data, err := ctrl.membershipService.CreateMembership(c.Request.Context(), body)
 	if err != nil {
-		response.BadRequest(c, "Failed to create membership", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -42,9 +38,9 @@ func (ctrl *MembershipController) CreateMembership(c *gin.Context) {
 func (ctrl *MembershipController) GetMembership(c *gin.Context) {
 	id := c.Param("id")
 
-	data, err := ctrl.membershipService.GetMembership("id", id)
+	data, err := ctrl.membershipService.GetMembership(c.Request.Context(), "id", id)
 	if err != nil {
-		response.NotFound(c, "Membership not found")
+		response.HandleError(c, err)
 		return
 	}
 
@@ -55,17 +51,13 @@ func (ctrl *MembershipController) UpdateMembership(c *gin.Context) {
 	var body dto.UpdateMembershipRequest
 
 	if err := c.ShouldBindJSON(&body); err != nil {
-		response.ValidationError(c, "Invalid request data", map[string]string{
-			"request": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
-	data, err := ctrl.membershipService.UpdateMembership(body)
+	data, err := ctrl.membershipService.UpdateMembership(c.Request.Context(), body)
 	if err != nil {
-		response.BadRequest(c, "Failed to update membership", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -75,11 +67,9 @@ func (ctrl *MembershipController) UpdateMembership(c *gin.Context) {
 func (ctrl *MembershipController) DeleteMembership(c *gin.Context) {
 	id := c.Param("id")
 	
-	err := ctrl.membershipService.DeleteMembership(id)
+	err := ctrl.membershipService.DeleteMembership(c.Request.Context(), id)
 	if err != nil {
-		response.BadRequest(c, "Failed to delete membership", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -89,11 +79,9 @@ func (ctrl *MembershipController) DeleteMembership(c *gin.Context) {
 func (ctrl *MembershipController) GetCompanyMembers(c *gin.Context) {
 	companyID := c.Param("company_id")
 
-	members, err := ctrl.membershipService.GetCompanyMembers(companyID)
+	members, err := ctrl.membershipService.GetCompanyMembers(c.Request.Context(), companyID)
 	if err != nil {
-		response.BadRequest(c, "Failed to retrieve company members", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -107,17 +95,13 @@ func (ctrl *MembershipController) UpdateMembershipRole(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&body); err != nil {
-		response.ValidationError(c, "Invalid role data", map[string]string{
-			"role": "Must be one of: admin, member, viewer",
-		})
+		response.HandleError(c, err)
 		return
 	}
 
-	err := ctrl.membershipService.UpdateMembershipRole(id, body.Role)
+	err := ctrl.membershipService.UpdateMembershipRole(c.Request.Context(), id, body.Role)
 	if err != nil {
-		response.BadRequest(c, "Failed to update membership role", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -131,17 +115,13 @@ func (ctrl *MembershipController) UpdateMembershipStatus(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&body); err != nil {
-		response.ValidationError(c, "Invalid status data", map[string]string{
-			"status": "Must be one of: active, inactive, suspended",
-		})
+		response.HandleError(c, err)
 		return
 	}
 
-	err := ctrl.membershipService.UpdateMembershipStatus(id, body.Status)
+	err := ctrl.membershipService.UpdateMembershipStatus(c.Request.Context(), id, body.Status)
 	if err != nil {
-		response.BadRequest(c, "Failed to update membership status", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
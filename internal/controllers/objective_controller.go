@@ -21,17 +21,13 @@ func (ctrl *ObjectiveController) CreateObjective(c *gin.Context) {
 	var req dto.CreateObjectiveRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, "Invalid request data", map[string]string{
-			"request": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
-	objective, err := ctrl.objectiveService.CreateObjective(req)
+	objective, err := ctrl.objectiveService.CreateObjective(c.Request.Context(), req)
 	if err != nil {
-		response.BadRequest(c, "Failed to create objective", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -41,9 +37,9 @@ func (ctrl *ObjectiveController) CreateObjective(c *gin.Context) {
 func (ctrl *ObjectiveController) GetObjective(c *gin.Context) {
 	id := c.Param("id")
 
-	objective, err := ctrl.objectiveService.GetObjective("id", id)
+	objective, err := ctrl.objectiveService.GetObjective(c.Request.Context(), "id", id)
 	if err != nil {
-		response.NotFound(c, "Objective not found")
+		response.HandleError(c, err)
 		return
 	}
 
@@ -53,9 +49,9 @@ func (ctrl *ObjectiveController) GetObjective(c *gin.Context) {
 func (ctrl *ObjectiveController) GetObjectiveWithKeyResults(c *gin.Context) {
 	id := c.Param("id")
 
-	objective, err := ctrl.objectiveService.GetObjectiveWithKeyResults(id)
+	objective, err := ctrl.objectiveService.GetObjectiveWithKeyResults(c.Request.Context(), id)
 	if err != nil {
-		response.NotFound(c, "Objective not found")
+		response.HandleError(c, err)
 		return
 	}
 
@@ -66,20 +62,16 @@ func (ctrl *ObjectiveController) UpdateObjective(c *gin.Context) {
 	var req dto.UpdateObjectiveRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, "Invalid request data", map[string]string{
-			"request": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
 	id := c.Param("id")
 	req.ID = id
 
-	objective, err := ctrl.objectiveService.UpdateObjective(req)
+	objective, err := ctrl.objectiveService.UpdateObjective(c.Request.Context(), req)
 	if err != nil {
-		response.BadRequest(c, "Failed to update objective", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -89,11 +81,9 @@ func (ctrl *ObjectiveController) UpdateObjective(c *gin.Context) {
 func (ctrl *ObjectiveController) DeleteObjective(c *gin.Context) {
 	id := c.Param("id")
 
-	err := ctrl.objectiveService.DeleteObjective(id)
+	err := ctrl.objectiveService.DeleteObjective(c.Request.Context(), id)
 	if err != nil {
-		response.BadRequest(c, "Failed to delete objective", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -103,11 +93,9 @@ func (ctrl *ObjectiveController) DeleteObjective(c *gin.Context) {
 func (ctrl *ObjectiveController) ListCompanyObjectives(c *gin.Context) {
 	companyID := c.Param("company_id")
 
-	objectives, err := ctrl.objectiveService.ListObjectivesByCompany(companyID)
+	objectives, err := ctrl.objectiveService.ListObjectivesByCompany(c.Request.Context(), companyID)
 	if err != nil {
-		response.BadRequest(c, "Failed to retrieve company objectives", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -117,11 +105,9 @@ func (ctrl *ObjectiveController) ListCompanyObjectives(c *gin.Context) {
 func (ctrl *ObjectiveController) ListTeamObjectives(c *gin.Context) {
 	teamID := c.Param("team_id")
 
-	objectives, err := ctrl.objectiveService.ListObjectivesByTeam(teamID)
+	objectives, err := ctrl.objectiveService.ListObjectivesByTeam(c.Request.Context(), teamID)
 	if err != nil {
-		response.BadRequest(c, "Failed to retrieve team objectives", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -131,11 +117,9 @@ func (ctrl *ObjectiveController) ListTeamObjectives(c *gin.Context) {
 func (ctrl *ObjectiveController) ListOwnerObjectives(c *gin.Context) {
 	ownerID := c.Param("owner_id")
 
-	objectives, err := ctrl.objectiveService.ListObjectivesByOwner(ownerID)
+	objectives, err := ctrl.objectiveService.ListObjectivesByOwner(c.Request.Context(), ownerID)
 	if err != nil {
-		response.BadRequest(c, "Failed to retrieve owner objectives", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -145,11 +129,9 @@ func (ctrl *ObjectiveController) ListOwnerObjectives(c *gin.Context) {
 func (ctrl *ObjectiveController) UpdateObjectiveProgress(c *gin.Context) {
 	objectiveID := c.Param("id")
 
-	err := ctrl.objectiveService.UpdateObjectiveProgress(objectiveID)
+	err := ctrl.objectiveService.UpdateObjectiveProgress(c.Request.Context(), objectiveID)
 	if err != nil {
-		response.BadRequest(c, "Failed to update objective progress", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
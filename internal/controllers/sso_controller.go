@@ -0,0 +1,245 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"encoding/xml"
+	"net/http"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
+	"github.com/Slightly-Techie/st-okr-api/internal/response"
+	"github.com/Slightly-Techie/st-okr-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ssoCookiePath      = "/api/v1/auth/sso"
+	ssoCookieMaxAge    = 600
+	ssoStateCookie     = "sso_state"
+	ssoNonceCookie     = "sso_nonce"
+	ssoRequestIDCookie = "sso_request_id"
+)
+
+type SSOController struct {
+	ssoService  services.SSOService
+	authService services.AuthService
+}
+
+func NewSSOController(ssoService services.SSOService, authService services.AuthService) *SSOController {
+	return &SSOController{
+		ssoService:  ssoService,
+		authService: authService,
+	}
+}
+
+func (ctrl *SSOController) GetConfig(c *gin.Context) {
+	cfg, err := ctrl.ssoService.GetConfig(c.Request.Context(), getUserID(c), c.Param("id"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, cfg, "SSO configuration retrieved successfully")
+}
+
+func (ctrl *SSOController) UpsertConfig(c *gin.Context) {
+	var req dto.UpsertSSOConfigRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	cfg, err := ctrl.ssoService.UpsertConfig(c.Request.Context(), getUserID(c), c.Param("id"), req)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, cfg, "SSO configuration saved successfully")
+}
+
+func (ctrl *SSOController) DeleteConfig(c *gin.Context) {
+	if err := ctrl.ssoService.DeleteConfig(c.Request.Context(), getUserID(c), c.Param("id")); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, nil, "SSO configuration deleted successfully")
+}
+
+func (ctrl *SSOController) AddDomain(c *gin.Context) {
+	var req dto.CreateCompanyDomainRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	domain, err := ctrl.ssoService.AddDomain(c.Request.Context(), getUserID(c), c.Param("id"), req)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Created(c, domain, "Domain added. Publish the TXT record and verify it")
+}
+
+func (ctrl *SSOController) ListDomains(c *gin.Context) {
+	domains, err := ctrl.ssoService.ListDomains(c.Request.Context(), getUserID(c), c.Param("id"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, domains, "Domains retrieved successfully")
+}
+
+func (ctrl *SSOController) VerifyDomain(c *gin.Context) {
+	domain, err := ctrl.ssoService.VerifyDomain(c.Request.Context(), getUserID(c), c.Param("id"), c.Param("domain_id"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, domain, "Domain verified successfully")
+}
+
+func (ctrl *SSOController) RemoveDomain(c *gin.Context) {
+	if err := ctrl.ssoService.RemoveDomain(c.Request.Context(), getUserID(c), c.Param("id"), c.Param("domain_id")); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, nil, "Domain removed successfully")
+}
+
+func (ctrl *SSOController) Discover(c *gin.Context) {
+	var req dto.EmailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	res, err := ctrl.ssoService.Discover(c.Request.Context(), req.Email)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, res, "Single sign-on is available")
+}
+
+// Login redirects the browser to the company's identity provider
+func (ctrl *SSOController) Login(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	companyID := c.Param("company_id")
+
+	loginReq, err := ctrl.ssoService.BeginLogin(c.Request.Context(), companyID)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	if !beginBrowserLogin(c, loginReq.State) {
+		return
+	}
+
+	if loginReq.Nonce != "" {
+		setLoginCookie(c, ssoStateCookie, loginReq.State, ssoCookiePath, ssoCookieMaxAge)
+		setLoginCookie(c, ssoNonceCookie, loginReq.Nonce, ssoCookiePath, ssoCookieMaxAge)
+	}
+	if loginReq.RequestID != "" {
+		setLoginCookie(c, ssoRequestIDCookie, loginReq.RequestID, ssoCookiePath, ssoCookieMaxAge)
+	}
+
+	log.Info("SSO login initiated",
+		"company_id", companyID,
+	)
+
+	c.Redirect(http.StatusFound, loginReq.RedirectURL)
+}
+
+// OIDCCallback completes an OIDC authorization code flow
+func (ctrl *SSOController) OIDCCallback(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	companyID := c.Param("company_id")
+
+	state, err := c.Cookie(ssoStateCookie)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(c.Query("state"))) != 1 {
+		response.BadRequest(c, "Invalid or expired login state", nil)
+		return
+	}
+	nonce, _ := c.Cookie(ssoNonceCookie)
+
+	setLoginCookie(c, ssoStateCookie, "", ssoCookiePath, -1)
+	setLoginCookie(c, ssoNonceCookie, "", ssoCookiePath, -1)
+
+	if idpErr := c.Query("error"); idpErr != "" {
+		response.BadRequest(c, "Identity provider returned an error", map[string]string{
+			"error":             idpErr,
+			"error_description": c.Query("error_description"),
+		})
+		return
+	}
+
+	authRes, err := ctrl.ssoService.CompleteOIDCLogin(c.Request.Context(), companyID, c.Query("code"), nonce)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	log.Info("SSO authentication successful",
+		"company_id", companyID,
+		"user_id", authRes.ID,
+	)
+
+	completeBrowserLogin(c, ctrl.authService, state, authRes)
+}
+
+// SAMLAssertionConsumer completes a SAML login posted by the identity provider
+func (ctrl *SSOController) SAMLAssertionConsumer(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	companyID := c.Param("company_id")
+
+	var possibleRequestIDs []string
+	if requestID, err := c.Cookie(ssoRequestIDCookie); err == nil && requestID != "" {
+		possibleRequestIDs = append(possibleRequestIDs, requestID)
+	}
+
+	authRes, err := ctrl.ssoService.CompleteSAMLLogin(c.Request, companyID, possibleRequestIDs)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	setLoginCookie(c, ssoRequestIDCookie, "", ssoCookiePath, -1)
+
+	log.Info("SSO authentication successful",
+		"company_id", companyID,
+		"user_id", authRes.ID,
+	)
+
+	completeBrowserLogin(c, ctrl.authService, c.PostForm("RelayState"), authRes)
+}
+
+// SAMLMetadata serves the service provider metadata to configure in the IdP
+func (ctrl *SSOController) SAMLMetadata(c *gin.Context) {
+	metadata, err := ctrl.ssoService.ServiceProviderMetadata(c.Request.Context(), c.Param("company_id"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	body, err := xml.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/samlmetadata+xml", body)
+}
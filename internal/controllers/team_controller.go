@@ -21,17 +21,13 @@ func (tctrl *TeamController) CreateTeam(c *gin.Context) {
 	var teamDTO dto.CreateTeamRequest
 
 	if err := c.ShouldBindJSON(&teamDTO); err != nil {
-		response.ValidationError(c, "Invalid request data", map[string]string{
-			"request": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
-	team, err := tctrl.teamService.CreateTeam(teamDTO)
+	team, err := tctrl.teamService.CreateTeam(c.Request.Context(), teamDTO)
 	if err != nil {
-		response.BadRequest(c, "Failed to create team", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -41,9 +37,9 @@ func (tctrl *TeamController) CreateTeam(c *gin.Context) {
 func (tctrl *TeamController) GetTeam(c *gin.Context) {
 	id := c.Param("id")
 
-	team, err := tctrl.teamService.GetTeam("id", id)
+	team, err := tctrl.teamService.GetTeam(c.Request.Context(), "id", id)
 	if err != nil {
-		response.NotFound(c, "Team not found")
+		response.HandleError(c, err)
 		return
 	}
 
@@ -54,17 +50,13 @@ func (tctrl *TeamController) UpdateTeam(c *gin.Context) {
 	var teamDTO dto.UpdateTeamRequest
 
 	if err := c.ShouldBindJSON(&teamDTO); err != nil {
-		response.ValidationError(c, "Invalid request data", map[string]string{
-			"request": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
-	team, err := tctrl.teamService.UpdateTeam(teamDTO)
+	team, err := tctrl.teamService.UpdateTeam(c.Request.Context(), teamDTO)
 	if err != nil {
-		response.BadRequest(c, "Failed to update team", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -74,11 +66,9 @@ func (tctrl *TeamController) UpdateTeam(c *gin.Context) {
 func (tctrl *TeamController) DeleteTeam(c *gin.Context) {
 	id := c.Param("id")
 
-	err := tctrl.teamService.DeleteTeam(id)
+	err := tctrl.teamService.DeleteTeam(c.Request.Context(), id)
 	if err != nil {
-		response.BadRequest(c, "Failed to delete team", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -89,21 +79,13 @@ func (tctrl *TeamController) AddTeamMember(c *gin.Context) {
 	var addMemberDTO dto.TeamMemberRequest
 
 	if err := c.ShouldBindJSON(&addMemberDTO); err != nil {
-		response.ValidationError(c, "Invalid request data", map[string]string{
-			"request": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
-	member, err := tctrl.teamService.AddMember(&addMemberDTO)
+	member, err := tctrl.teamService.AddMember(c.Request.Context(), &addMemberDTO)
 	if err != nil {
-		if err.Error() == "user is already a member of the team" {
-			response.Conflict(c, "User is already a member of the team", nil)
-			return
-		}
-		response.BadRequest(c, "Failed to add team member", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -113,9 +95,9 @@ func (tctrl *TeamController) AddTeamMember(c *gin.Context) {
 func (tctrl *TeamController) ListTeamMembers(c *gin.Context) {
 	teamID := c.Param("id")
 
-	members, err := tctrl.teamService.ListMembers("team_id", teamID)
+	members, err := tctrl.teamService.ListMembers(c.Request.Context(), "team_id", teamID)
 	if err != nil {
-		response.NotFound(c, "Team members not found")
+		response.HandleError(c, err)
 		return
 	}
 
@@ -125,11 +107,9 @@ func (tctrl *TeamController) ListTeamMembers(c *gin.Context) {
 func (tctrl *TeamController) RemoveMember(c *gin.Context) {
 	id := c.Param("id")
 
-	err := tctrl.teamService.RemoveMember(id)
+	err := tctrl.teamService.RemoveMember(c.Request.Context(), id)
 	if err != nil {
-		response.BadRequest(c, "Failed to remove team member", map[string]string{
-			"service": err.Error(),
-		})
+		response.HandleError(c, err)
 		return
 	}
 
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"github.com/Slightly-Techie/st-okr-api/internal/response"
+	"github.com/Slightly-Techie/st-okr-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type TimeSeriesController struct {
+	timeSeriesService services.TimeSeriesService
+}
+
+func NewTimeSeriesController(timeSeriesService services.TimeSeriesService) *TimeSeriesController {
+	return &TimeSeriesController{
+		timeSeriesService: timeSeriesService,
+	}
+}
+
+// ObjectiveTimeSeries returns an objective's daily progress for burn-up charts
+func (ctrl *TimeSeriesController) ObjectiveTimeSeries(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Unauthorized request")
+		return
+	}
+
+	series, err := ctrl.timeSeriesService.ObjectiveTimeSeries(c.Request.Context(), userID.(string), c.Param("id"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, series, "Objective time series retrieved successfully")
+}
+
+// KeyResultTimeSeries returns a key result's daily progress and value
+func (ctrl *TimeSeriesController) KeyResultTimeSeries(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Unauthorized request")
+		return
+	}
+
+	series, err := ctrl.timeSeriesService.KeyResultTimeSeries(c.Request.Context(), userID.(string), c.Param("id"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.OK(c, series, "Key result time series retrieved successfully")
+}
@@ -1,6 +1,44 @@
 package dto
 
+import "github.com/Slightly-Techie/st-okr-api/internal/models"
+
 type CreateCompanyRequest struct {
 	Name      string `json:"name"`
 	CreatorId string `json:"creator_id"`
-}
\ No newline at end of file
+}
+
+// StatusThresholdsRequest sets how many points below its expected progress
+// a key result may fall before it is at risk, and before it is behind
+type StatusThresholdsRequest struct {
+	AtRisk float64 `json:"at_risk" validate:"min=0,max=100"`
+	Behind float64 `json:"behind" validate:"min=0,max=100,gtfield=AtRisk"`
+}
+
+// ReportingCurrencyRequest sets the ISO 4217 currency currency key results
+// are converted to; an empty currency stops converting them
+type ReportingCurrencyRequest struct {
+	ReportingCurrency string `json:"reporting_currency"`
+}
+
+// ExchangeRateRequest keeps how many units of To one unit of From buys
+type ExchangeRateRequest struct {
+	From string  `json:"from" validate:"required"`
+	To   string  `json:"to" validate:"required"`
+	Rate float64 `json:"rate" validate:"gt=0"`
+}
+
+// CurrencySettingsResponse is a company's reporting currency along with the
+// exchange rates it keeps
+type CurrencySettingsResponse struct {
+	ReportingCurrency string                `json:"reporting_currency"`
+	Rates             []models.ExchangeRate `json:"rates"`
+}
+
+// CreateCycleRequest names a period of the company's OKRs. The dates are
+// YYYY-MM-DD and the end date is the last day of the cycle; both may be left
+// out when the name is a calendar period such as 2025, 2025-H1 or 2025-Q3.
+type CreateCycleRequest struct {
+	Name      string `json:"name" validate:"required,max=100"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
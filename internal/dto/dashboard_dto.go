@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+)
+
+// DashboardResponse summarises a company's OKRs. Every status appears in the
+// counts, with zero when nothing is in it.
+type DashboardResponse struct {
+	GeneratedAt          time.Time                                `json:"generated_at"`
+	ObjectivesByStatus   map[models.ObjectiveStatus]int64         `json:"objectives_by_status"`
+	KeyResultsByStatus   map[models.KeyResultProgressStatus]int64 `json:"key_results_by_status"`
+	TeamProgress         []TeamProgressResponse                   `json:"team_progress"`
+	AtRiskKeyResults     []AtRiskKeyResultResponse                `json:"at_risk_key_results"`
+	CheckInFreshness     CheckInFreshnessResponse                 `json:"check_in_freshness"`
+	ProgressDistribution []ProgressBucketResponse                 `json:"progress_distribution"`
+}
+
+// TeamProgressResponse is the average progress of a team's objectives. The
+// entry without a team_id covers the objectives that belong to no team.
+type TeamProgressResponse struct {
+	TeamID          string  `json:"team_id,omitempty"`
+	TeamName        string  `json:"team_name,omitempty"`
+	Objectives      int64   `json:"objectives"`
+	AverageProgress float64 `json:"average_progress"`
+}
+
+type AtRiskKeyResultResponse struct {
+	ID             string                         `json:"id"`
+	Title          string                         `json:"title"`
+	ObjectiveID    string                         `json:"objective_id"`
+	ObjectiveTitle string                         `json:"objective_title"`
+	Status         models.KeyResultProgressStatus `json:"status"`
+	Progress       float64                        `json:"progress"`
+	AssigneeType   models.AssigneeType            `json:"assignee_type"`
+	AssigneeID     string                         `json:"assignee_id"`
+	DueDate        time.Time                      `json:"due_date"`
+}
+
+// CheckInFreshnessResponse counts the key results that are not yet complete
+// by when they were last updated
+type CheckInFreshnessResponse struct {
+	UpdatedWithinWeek  int64      `json:"updated_within_week"`
+	UpdatedWithinMonth int64      `json:"updated_within_month"`
+	Stale              int64      `json:"stale"`
+	LastCheckInAt      *time.Time `json:"last_check_in_at,omitempty"`
+}
+
+// ProgressBucketResponse counts the key results whose progress is in
+// Range: 0-25, 25-50, 50-75, 75-100 or 100 for the completed ones
+type ProgressBucketResponse struct {
+	Range string `json:"range"`
+	Count int64  `json:"count"`
+}
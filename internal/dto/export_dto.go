@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+)
+
+// ExportObjective is an objective as written by the export, with names
+// alongside the IDs so the file can be read without the API
+type ExportObjective struct {
+	ID          string                 `json:"id"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Type        models.ObjectiveType   `json:"type"`
+	Status      models.ObjectiveStatus `json:"status"`
+	Progress    float64                `json:"progress"`
+	OwnerID     string                 `json:"owner_id"`
+	OwnerName   string                 `json:"owner_name"`
+	OwnerEmail  string                 `json:"owner_email"`
+	TeamID      *string                `json:"team_id,omitempty"`
+	TeamName    string                 `json:"team_name,omitempty"`
+	StartDate   time.Time              `json:"start_date"`
+	EndDate     time.Time              `json:"end_date"`
+	KeyResults  []ExportKeyResult      `json:"key_results"`
+}
+
+type ExportKeyResult struct {
+	ID           string                         `json:"id"`
+	Title        string                         `json:"title"`
+	Description  string                         `json:"description"`
+	MetricType   models.MetricType              `json:"metric_type"`
+	CurrentValue float64                        `json:"current_value"`
+	TargetValue  float64                        `json:"target_value"`
+	StartValue   float64                        `json:"start_value"`
+	Direction    models.MetricDirection         `json:"direction,omitempty"`
+	RangeMin     float64                        `json:"range_min,omitempty"`
+	RangeMax     float64                        `json:"range_max,omitempty"`
+	Progress     float64                        `json:"progress"`
+	Status       models.KeyResultProgressStatus `json:"status"`
+	AssigneeType models.AssigneeType            `json:"assignee_type"`
+	AssigneeID   string                         `json:"assignee_id"`
+	AssigneeName string                         `json:"assignee_name"`
+	StartDate    time.Time                      `json:"start_date"`
+	DueDate      time.Time                      `json:"due_date"`
+	// Currency is the ISO 4217 code of a currency key result
+	Currency string `json:"currency,omitempty"`
+}
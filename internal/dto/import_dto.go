@@ -0,0 +1,63 @@
+package dto
+
+import "github.com/Slightly-Techie/st-okr-api/internal/models"
+
+// ImportDocument is the JSON and YAML form of a bulk import. People are
+// referred to by email and teams by name; IDs are accepted as well.
+type ImportDocument struct {
+	Objectives []ImportObjective `json:"objectives" yaml:"objectives"`
+}
+
+type ImportObjective struct {
+	Title       string               `json:"title" yaml:"title"`
+	Description string               `json:"description" yaml:"description"`
+	Type        models.ObjectiveType `json:"type" yaml:"type"`
+	// Owner is the email of a company member; the importing user when empty
+	Owner string `json:"owner" yaml:"owner"`
+	// Team is the name of a team in the company
+	Team       string            `json:"team" yaml:"team"`
+	StartDate  string            `json:"start_date" yaml:"start_date"`
+	EndDate    string            `json:"end_date" yaml:"end_date"`
+	KeyResults []ImportKeyResult `json:"key_results" yaml:"key_results"`
+	// Cycle is one of the company's cycles, or a calendar period such as
+	// 2025-Q3, whose dates fill in StartDate and EndDate when they are empty
+	Cycle string `json:"cycle" yaml:"cycle"`
+}
+
+type ImportKeyResult struct {
+	Title        string            `json:"title" yaml:"title"`
+	Description  string            `json:"description" yaml:"description"`
+	MetricType   models.MetricType `json:"metric_type" yaml:"metric_type"`
+	CurrentValue float64           `json:"current_value" yaml:"current_value"`
+	TargetValue  float64           `json:"target_value" yaml:"target_value"`
+	StartValue   float64           `json:"start_value" yaml:"start_value"`
+	// Direction is increase or decrease; the values decide it unless they are equal
+	Direction models.MetricDirection `json:"direction" yaml:"direction"`
+	RangeMin  float64                `json:"range_min" yaml:"range_min"`
+	RangeMax  float64                `json:"range_max" yaml:"range_max"`
+	// Currency is the ISO 4217 code of a currency key result; the company's
+	// reporting currency when empty
+	Currency string `json:"currency" yaml:"currency"`
+	// AssigneeType is inferred from Assignee when empty: an email is an
+	// individual, anything else a team name
+	AssigneeType models.AssigneeType `json:"assignee_type" yaml:"assignee_type"`
+	Assignee     string              `json:"assignee" yaml:"assignee"`
+	StartDate    string              `json:"start_date" yaml:"start_date"`
+	DueDate      string              `json:"due_date" yaml:"due_date"`
+}
+
+// ImportError is a problem with one field of an import. Row is the line in
+// a CSV file, or the position of the objective in a JSON or YAML document.
+type ImportError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportReport struct {
+	DryRun       bool          `json:"dry_run"`
+	Objectives   int           `json:"objectives"`
+	KeyResults   int           `json:"key_results"`
+	ObjectiveIDs []string      `json:"objective_ids,omitempty"`
+	Errors       []ImportError `json:"errors,omitempty"`
+}
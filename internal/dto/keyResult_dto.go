@@ -7,29 +7,51 @@ import (
 )
 
 type CreateKeyResultRequest struct {
-	ObjectiveID  string              `json:"objective_id" validate:"required,uuid"`
-	Title        string              `json:"title" validate:"required"`
-	Description  string              `json:"description"`
-	MetricType   models.MetricType   `json:"metric_type" validate:"required,metric_type"`
-	CurrentValue float64             `json:"current_value"`
-	TargetValue  float64             `json:"target_value" validate:"required"`
-	AssigneeType models.AssigneeType `json:"assignee_type" validate:"required,assignee_type"`
-	AssigneeID   string              `json:"assignee_id" validate:"required,uuid"`
-	StartDate    time.Time           `json:"start_date" validate:"required"`
-	DueDate      time.Time           `json:"due_date" validate:"due_date"`
+	ObjectiveID  string                 `json:"objective_id" validate:"required,uuid"`
+	Title        string                 `json:"title" validate:"required"`
+	Description  string                 `json:"description"`
+	MetricType   models.MetricType      `json:"metric_type" validate:"required,metric_type"`
+	CurrentValue float64                `json:"current_value"`
+	TargetValue  float64                `json:"target_value"`
+	StartValue   float64                `json:"start_value"`
+	Direction    models.MetricDirection `json:"direction" validate:"omitempty,metric_direction"`
+	RangeMin     float64                `json:"range_min"`
+	RangeMax     float64                `json:"range_max"`
+	AssigneeType models.AssigneeType    `json:"assignee_type" validate:"required,assignee_type"`
+	AssigneeID   string                 `json:"assignee_id" validate:"required,uuid"`
+	StartDate    time.Time              `json:"start_date" validate:"required"`
+	DueDate      time.Time              `json:"due_date" validate:"due_date"`
+	// Currency and the amounts apply to currency key results. Amounts are
+	// exact decimals such as "1234.50" and take precedence over the values.
+	// An empty currency takes the company's reporting currency.
+	Currency      string `json:"currency"`
+	CurrentAmount string `json:"current_amount"`
+	TargetAmount  string `json:"target_amount"`
+	StartAmount   string `json:"start_amount"`
 }
 
 type UpdateKeyResultRequest struct {
-	ID           string              `json:"id"`
-	Title        string              `json:"title"`
-	Description  string              `json:"description"`
-	CurrentValue float64             `json:"current_value"`
-	TargetValue  float64             `json:"target_value"`
-	MetricType   models.MetricType   `json:"metric_type"`
-	AssigneeType models.AssigneeType `json:"assignee_type" validate:"assignee_type"`
-	AssigneeID   string              `json:"assignee_id" validate:"uuid"`
-	StartDate    time.Time           `json:"start_date"`
-	DueDate      time.Time           `json:"due_date" validate:"due_date"`
+	ID           string                 `json:"id"`
+	Title        string                 `json:"title"`
+	Description  string                 `json:"description"`
+	CurrentValue float64                `json:"current_value"`
+	TargetValue  float64                `json:"target_value"`
+	StartValue   float64                `json:"start_value"`
+	Direction    models.MetricDirection `json:"direction" validate:"omitempty,metric_direction"`
+	RangeMin     float64                `json:"range_min"`
+	RangeMax     float64                `json:"range_max"`
+	MetricType   models.MetricType      `json:"metric_type"`
+	AssigneeType models.AssigneeType    `json:"assignee_type" validate:"assignee_type"`
+	AssigneeID   string                 `json:"assignee_id" validate:"uuid"`
+	StartDate    time.Time              `json:"start_date"`
+	DueDate      time.Time              `json:"due_date" validate:"due_date"`
+	// Currency and the amounts are as in CreateKeyResultRequest. An empty
+	// currency keeps the key result's own, or else takes the company's
+	// reporting currency.
+	Currency      string `json:"currency"`
+	CurrentAmount string `json:"current_amount"`
+	TargetAmount  string `json:"target_amount"`
+	StartAmount   string `json:"start_amount"`
 }
 
 type KeyResultResponse struct {
@@ -42,8 +64,16 @@ type KeyResultResponse struct {
 	MetricType   models.MetricType              `json:"metric_type"`
 	CurrentValue float64                        `json:"current_value"`
 	TargetValue  float64                        `json:"target_value"`
+	StartValue   float64                        `json:"start_value"`
+	Direction    models.MetricDirection         `json:"direction,omitempty"`
+	RangeMin     float64                        `json:"range_min,omitempty"`
+	RangeMax     float64                        `json:"range_max,omitempty"`
 	Progress     float64                        `json:"progress"`
 	StartDate    time.Time                      `json:"start_date"`
 	DueDate      time.Time                      `json:"due_date"`
 	Status       models.KeyResultProgressStatus `json:"status"`
+	// Currency and the amounts are set for currency key results only
+	Currency         string          `json:"currency,omitempty"`
+	Amounts          *models.Amounts `json:"amounts,omitempty"`
+	ReportingAmounts *models.Amounts `json:"reporting_amounts,omitempty"`
 }
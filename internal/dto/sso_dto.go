@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+)
+
+type UpsertSSOConfigRequest struct {
+	Protocol         models.SSOProtocol `json:"protocol" validate:"required,oneof=oidc saml"`
+	Enabled          bool               `json:"enabled"`
+	OIDCIssuer       string             `json:"oidc_issuer" validate:"required_if=Protocol oidc,omitempty,url"`
+	OIDCClientID     string             `json:"oidc_client_id" validate:"required_if=Protocol oidc"`
+	OIDCClientSecret string             `json:"oidc_client_secret"`
+	SAMLMetadataURL  string             `json:"saml_metadata_url" validate:"omitempty,url"`
+	SAMLMetadataXML  string             `json:"saml_metadata_xml"`
+	DefaultRole      models.RoleType    `json:"default_role" validate:"omitempty,oneof=admin member viewer"`
+	EnforceSSO       bool               `json:"enforce_sso"`
+}
+
+type SSOConfigResponse struct {
+	CompanyID        string             `json:"company_id"`
+	Protocol         models.SSOProtocol `json:"protocol"`
+	Enabled          bool               `json:"enabled"`
+	OIDCIssuer       string             `json:"oidc_issuer,omitempty"`
+	OIDCClientID     string             `json:"oidc_client_id,omitempty"`
+	HasClientSecret  bool               `json:"has_client_secret"`
+	SAMLMetadataURL  string             `json:"saml_metadata_url,omitempty"`
+	HasSAMLMetadata  bool               `json:"has_saml_metadata"`
+	DefaultRole      models.RoleType    `json:"default_role"`
+	EnforceSSO       bool               `json:"enforce_sso"`
+	LoginURL         string             `json:"login_url"`
+	CallbackURL      string             `json:"callback_url"`
+	SAMLMetadataPath string             `json:"saml_sp_metadata_url,omitempty"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+type CreateCompanyDomainRequest struct {
+	Domain string `json:"domain" validate:"required,fqdn"`
+}
+
+type CompanyDomainResponse struct {
+	ID          string     `json:"id"`
+	Domain      string     `json:"domain"`
+	Verified    bool       `json:"verified"`
+	VerifiedAt  *time.Time `json:"verified_at,omitempty"`
+	RecordType  string     `json:"record_type"`
+	RecordName  string     `json:"record_name"`
+	RecordValue string     `json:"record_value"`
+}
+
+type SSODiscoverResponse struct {
+	CompanyID string `json:"company_id"`
+	LoginURL  string `json:"login_url"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+)
+
+// TimeSeriesResponse is the daily progress of an objective or key result
+// from its start date to today, next to the ideal linear trajectory from 0%
+// on the start date to 100% on the end or due date. The ideal of a range key
+// result is 100% throughout.
+type TimeSeriesResponse struct {
+	SubjectType models.SnapshotSubject `json:"subject_type"`
+	SubjectID   string                 `json:"subject_id"`
+	StartDate   time.Time              `json:"start_date"`
+	EndDate     time.Time              `json:"end_date"`
+	StartValue  *float64               `json:"start_value,omitempty"`
+	TargetValue *float64               `json:"target_value,omitempty"`
+	RangeMin    *float64               `json:"range_min,omitempty"`
+	RangeMax    *float64               `json:"range_max,omitempty"`
+	Points      []TimeSeriesPoint      `json:"points"`
+	Ideal       []TimeSeriesPoint      `json:"ideal"`
+}
+
+// TimeSeriesPoint is the progress on Date (YYYY-MM-DD). Value is the key
+// result's current or, on the ideal line, expected value.
+type TimeSeriesPoint struct {
+	Date     string   `json:"date"`
+	Progress float64  `json:"progress"`
+	Value    *float64 `json:"value,omitempty"`
+}
@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that carries l
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx. Without one it falls back to
+// the global Custom logger, tagged with the trace of the active span if any.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		return Custom.With("trace_id", spanContext.TraceID().String())
+	}
+	return Custom
+}
+
+// With returns a copy of ctx whose logger adds the key-value pairs to every
+// entry
+func With(ctx context.Context, fields ...any) context.Context {
+	return NewContext(ctx, FromContext(ctx).With(fields...))
+}
@@ -1,10 +1,13 @@
 package logger
 
 import (
+	"fmt"
 	"log"
 	"os"
 
+	"github.com/Slightly-Techie/st-okr-api/config"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // Logger wraps zap.Logger to provide a consistent logging interface
@@ -44,6 +47,41 @@ func NewDevelopment() *Logger {
 	return &Logger{logger}
 }
 
+// NewFromConfig creates a new Logger with the level, encoding, sampling and
+// outputs from cfg
+func NewFromConfig(cfg config.LogConfig) (*Logger, error) {
+	level, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level: %w", err)
+	}
+
+	zapConfig := zap.NewProductionConfig()
+	if cfg.Format == "console" {
+		zapConfig = zap.NewDevelopmentConfig()
+		zapConfig.Development = false
+	}
+	zapConfig.Level = zap.NewAtomicLevelAt(level)
+	zapConfig.OutputPaths = cfg.Outputs
+	zapConfig.Sampling = nil
+	if cfg.Sampling.Initial > 0 {
+		zapConfig.Sampling = &zap.SamplingConfig{
+			Initial:    cfg.Sampling.Initial,
+			Thereafter: cfg.Sampling.Thereafter,
+		}
+	}
+
+	logger, err := zapConfig.Build(zap.AddCallerSkip(1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build logger: %w", err)
+	}
+	return &Logger{logger}, nil
+}
+
+// With returns a child logger that adds the key-value pairs to every entry
+func (l *Logger) With(fields ...any) *Logger {
+	return &Logger{l.Logger.Sugar().With(fields...).Desugar()}
+}
+
 // Info logs a message at Info level with structured key-value pairs
 func (l *Logger) Info(msg string, fields ...any) {
 	l.Logger.Sugar().Infow(msg, fields...)
@@ -89,6 +127,19 @@ func InitGlobal() {
 	Custom = New()
 }
 
+// Configure replaces the global Custom logger with one built from cfg
+func Configure(cfg config.LogConfig) error {
+	logger, err := NewFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if Custom != nil {
+		Custom.Sync()
+	}
+	Custom = logger
+	return nil
+}
+
 // InitGlobalProduction initializes the global Custom logger instance for production
 func InitGlobalProduction() {
 	Custom = NewProduction()
@@ -22,3 +22,30 @@ func LoadTemplate(templateName string, data any) (string, error) {
 	return body.String(), nil
 
 }
+
+func sendLinkEmail(templateName, subject, recipientEmail, userName, link, expiresIn string) error {
+	data := map[string]string{
+		"userName":  userName,
+		"link":      link,
+		"expiresIn": expiresIn,
+	}
+
+	body, err := LoadTemplate(templateName, data)
+	if err != nil {
+		return err
+	}
+
+	return sendEmail(recipientEmail, subject, body)
+}
+
+func SendVerificationEmail(recipientEmail, userName, link, expiresIn string) error {
+	return sendLinkEmail("verify_email", "Verify your email", recipientEmail, userName, link, expiresIn)
+}
+
+func SendPasswordResetEmail(recipientEmail, userName, link, expiresIn string) error {
+	return sendLinkEmail("password_reset", "Reset your password", recipientEmail, userName, link, expiresIn)
+}
+
+func SendMagicLinkEmail(recipientEmail, userName, link, expiresIn string) error {
+	return sendLinkEmail("magic_link", "Your sign-in link", recipientEmail, userName, link, expiresIn)
+}
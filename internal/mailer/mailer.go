@@ -11,13 +11,13 @@ import (
 
 
 func sendEmail(to, subject, body string) error {
-	smtpConfig := config.ENV
+	smtpConfig := config.ENV.SMTP
 
-	auth := smtp.PlainAuth("", smtpConfig.SMTPUsername, smtpConfig.SMTPPassword, smtpConfig.SMTPHost)
+	auth := smtp.PlainAuth("", smtpConfig.Username, smtpConfig.Password, smtpConfig.Host)
 
 	// Construct the email headers
 	headers := make(map[string]string)
-	headers["From"] = smtpConfig.SMTPUsername
+	headers["From"] = smtpConfig.Sender()
 	headers["To"] = to
 	headers["Subject"] = subject
 	headers["MIME-Version"] = "1.0"
@@ -30,7 +30,7 @@ func sendEmail(to, subject, body string) error {
 	}
 	message += "\r\n" + body
 
-	addr := fmt.Sprintf("%s:%s", smtpConfig.SMTPHost, smtpConfig.SMTPPort)
+	addr := smtpConfig.Address()
 
 	// Create a custom TLS config
 	tlsConfig := &tls.Config{
@@ -51,7 +51,7 @@ func sendEmail(to, subject, body string) error {
 		return err
 	}
 
-	if err = client.Mail(smtpConfig.SMTPUsername); err != nil {
+	if err = client.Mail(smtpConfig.Sender()); err != nil {
 		return err
 	}
 
@@ -1,20 +1,27 @@
 package message
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"slices"
+	"sync"
+	"sync/atomic"
 
 	"github.com/Slightly-Techie/st-okr-api/config"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
 	"github.com/Slightly-Techie/st-okr-api/internal/mailer"
+	"github.com/Slightly-Techie/st-okr-api/internal/metrics"
 	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel/baggage"
 )
 
-func TestRabbitMQConnection(cfg config.Config) error {
+func TestRabbitMQConnection(cfg config.RabbitConfig) error {
 
 	// Load configuration
 
-	url := fmt.Sprintf("amqp://%s:%s@%s:%s/", cfg.RabbitUser, cfg.RabbitPassword, cfg.RabbitHost, cfg.RabbitPort)
+	url := cfg.URL()
 
 	// Attempt to connect to RabbitMQ
 	conn, err := amqp.Dial(url)
@@ -29,36 +36,29 @@ func TestRabbitMQConnection(cfg config.Config) error {
 	return nil
 }
 
-func PublishMessage(eventType string, fields map[string]any) error {
+// queues lists the queues the consumer reads from
+var queues = []string{"sign_up", "verify_email", "password_reset", "magic_link"}
 
-	cfg := config.ENV
-
-	url := fmt.Sprintf("amqp://%s:%s@%s:%s/", cfg.RabbitUser, cfg.RabbitPassword, cfg.RabbitHost, cfg.RabbitPort)
-
-	// Attempt to connect to RabbitMQ
-	conn, err := amqp.Dial(url)
-	if err != nil {
-		return fmt.Errorf("failed to connect to RabbitMQ: %v", err)
-	}
-
-	defer conn.Close()
-
-	// Create a channel
-	ch, err := conn.Channel()
-	if err != nil {
-		return fmt.Errorf("failed to open a channel: %v", err)
-	}
-
-	defer ch.Close()
+// Queues returns the queues the consumer reads from
+func Queues() []string {
+	return slices.Clone(queues)
+}
 
-	// map event type to queue
-	queueName := getQueueName(eventType)
+// DeadLetterQueue names the queue that holds the messages rejected from
+// queueName
+func DeadLetterQueue(queueName string) string {
+	return queueName + ".dead"
+}
 
-	if queueName == "" {
-		return fmt.Errorf("invalid event type")
+// declareQueue declares queueName together with its dead-letter queue. Both
+// are declared without arguments, as the mail queues always have been, so
+// queues that already exist on the broker are declared again unchanged.
+func declareQueue(ch *amqp.Channel, queueName string) (amqp.Queue, error) {
+	deadLetters := DeadLetterQueue(queueName)
+	if _, err := ch.QueueDeclare(deadLetters, false, false, false, false, nil); err != nil {
+		return amqp.Queue{}, fmt.Errorf("failed to declare queue %s: %v", deadLetters, err)
 	}
 
-	// Declare a queue
 	q, err := ch.QueueDeclare(
 		queueName, // name
 		false,     // durable
@@ -67,89 +67,122 @@ func PublishMessage(eventType string, fields map[string]any) error {
 		false,     // no-wait
 		nil,       // arguments
 	)
-
 	if err != nil {
-		return fmt.Errorf("failed to declare a queue: %v", err)
+		return amqp.Queue{}, fmt.Errorf("failed to declare queue %s: %v", queueName, err)
 	}
+	return q, nil
+}
 
-	fields["event_type"] = eventType
+// Consumer reads the mail queues until it is shut down
+type Consumer struct {
+	conn     *amqp.Connection
+	ch       *amqp.Channel
+	wg       sync.WaitGroup
+	tags     []string
+	stopping atomic.Bool
+	active   atomic.Int32
+}
 
-	body, err := json.Marshal(fields)
+// NewConsumer connects to RabbitMQ
+func NewConsumer(cfg config.RabbitConfig) (*Consumer, error) {
+	conn, err := amqp.Dial(cfg.URL())
 	if err != nil {
-		return fmt.Errorf("failed to marshal fields: %v", err)
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %v", err)
 	}
 
-	log.Println("Publishing message to queue: ", queueName)
-
-	// Publish a message
-	err = ch.Publish(
-		"",     // exchange
-		q.Name, // routing key
-		false,  // mandatory
-		false,  // immediate
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		})
-
+	ch, err := conn.Channel()
 	if err != nil {
-		return fmt.Errorf("failed to publish a message: %v", err)
+		conn.Close()
+		return nil, fmt.Errorf("failed to open a channel: %v", err)
 	}
 
-	return nil
-}
-
-func ConsumeMessages() {
-	cfg := config.ENV
-
-	url := fmt.Sprintf("amqp://%s:%s@%s:%s/", cfg.RabbitUser, cfg.RabbitPassword, cfg.RabbitHost, cfg.RabbitPort)
-
-	conn, err := amqp.Dial(url)
-	if err != nil {
-		log.Fatalf("failed to connect to RabbitMQ: %v", err)
+	// Hold one unacknowledged message per queue so shutdown only waits for it
+	if err := ch.Qos(1, 0, false); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set prefetch: %v", err)
 	}
 
-	defer conn.Close()
+	return &Consumer{conn: conn, ch: ch}, nil
+}
 
-	ch, err := conn.Channel()
-	if err != nil {
-		log.Fatalf("failed to open a channel: %v", err)
+// Start registers a consumer on every queue and processes messages in the background
+func (c *Consumer) Start() error {
+	for _, queueName := range queues {
+		msgs, err := c.consumeFromQueue(queueName)
+		if err != nil {
+			return err
+		}
+
+		c.wg.Add(1)
+		c.active.Add(1)
+		go func() {
+			defer c.wg.Done()
+			defer c.active.Add(-1)
+			for msg := range msgs {
+				handleMessage(msg, queueName)
+			}
+		}()
 	}
 
-	defer ch.Close()
+	logger.Info("Consuming from queues", "queues", queues)
+	return nil
+}
 
-	queues := []string{"sign_up"} // list of queues to consume messages from
+// Shutdown stops delivery, waits for the messages being handled and closes the
+// connection. Messages not yet delivered stay in their queues.
+func (c *Consumer) Shutdown(ctx context.Context) error {
+	c.stopping.Store(true)
 
-	for _, queue := range queues {
-		go ConsumeFromQueue(ch, queue)
+	for _, tag := range c.tags {
+		if err := c.ch.Cancel(tag, false); err != nil {
+			logger.Warn("Failed to cancel consumer", "consumer_tag", tag, "error", err.Error())
+		}
 	}
 
-	select {}
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("consumers did not finish in time: %w", ctx.Err())
+	}
 
+	c.ch.Close()
+	c.conn.Close()
+	return err
 }
 
-func ConsumeFromQueue(ch *amqp.Channel, queueName string) {
-	// Declare the queue
-	q, err := ch.QueueDeclare(
-		queueName, // name
-		false,     // durable
-		false,     // delete when unused
-		false,     // exclusive
-		false,     // no-wait
-		nil,       // arguments
-	)
+// Check reports whether the consumer is receiving from every queue
+func (c *Consumer) Check(ctx context.Context) error {
+	switch {
+	case c.stopping.Load():
+		return errors.New("consumer is shutting down")
+	case c.conn.IsClosed():
+		return errors.New("consumer connection is closed")
+	case int(c.active.Load()) != len(queues):
+		return fmt.Errorf("consuming %d of %d queues", c.active.Load(), len(queues))
+	}
+	return nil
+}
 
+func (c *Consumer) consumeFromQueue(queueName string) (<-chan amqp.Delivery, error) {
+	q, err := declareQueue(c.ch, queueName)
 	if err != nil {
-		log.Fatalf("Failed to declare queue %s: %v", queueName, err)
+		return nil, err
 	}
 
-	log.Println("Consuming from queues")
+	tag := "st-okr-api-" + queueName
 
-	// Consume messages
-	msgs, err := ch.Consume(
+	// Consume messages; handlers ack or nack each one
+	msgs, err := c.ch.Consume(
 		q.Name, // queue
-		"",     // consumer
-		true,   // auto-ack
+		tag,    // consumer
+		false,  // auto-ack
 		false,  // exclusive
 		false,  // no-local
 		false,  // no-wait
@@ -157,71 +190,161 @@ func ConsumeFromQueue(ch *amqp.Channel, queueName string) {
 	)
 
 	if err != nil {
-		log.Fatalf("Failed to register consumer for queue %s: %v", queueName, err)
+		return nil, fmt.Errorf("failed to register consumer for queue %s: %v", queueName, err)
 	}
 
-	// Process messages in a separate goroutine
-	for msg := range msgs {
-		handleMessage(msg, queueName)
-	}
+	c.tags = append(c.tags, tag)
+	return msgs, nil
 }
 
 func handleMessage(msg amqp.Delivery, queueName string) {
-	log.Printf("Received message")
+	ctx, span := startConsumeSpan(msg, queueName)
+	defer span.End()
+
+	// The publisher's request ID travels in the message baggage
+	ctx = logger.With(ctx,
+		"queue", queueName,
+		"request_id", baggage.FromContext(ctx).Member("request_id").Value(),
+	)
+	log := logger.FromContext(ctx)
+
+	metrics.QueueMessages.WithLabelValues(queueName, "consume").Inc()
 
 	var fields map[string]any
 	if err := json.Unmarshal(msg.Body, &fields); err != nil {
-		log.Println("Failed to unmarshal message from queue %s: %v", queueName, err)
-		msg.Nack(false, false) // Nack the message and don't requeue
+		log.Error("Failed to unmarshal message", "error", err.Error())
+		nack(ctx, msg) // Nack the message and don't requeue
 		return
 	}
 
-	log.Println("Message received from queue: ", queueName)
-
 	eventType, ok := fields["event_type"].(string)
 	if !ok {
-		log.Println("Event type missing or invalid in message from queue %s", queueName)
-		msg.Nack(false, false)
+		log.Warn("Event type missing or invalid in message")
+		nack(ctx, msg)
 		return
 	}
 
+	log.Info("Message received", "event_type", eventType)
+
 	switch eventType {
 	case "sign_up":
-		handleSignUpMailer(fields, msg)
+		handleSignUpMailer(ctx, fields, msg)
+	case "verify_email":
+		handleLinkMailer(ctx, fields, msg, mailer.SendVerificationEmail)
+	case "password_reset":
+		handleLinkMailer(ctx, fields, msg, mailer.SendPasswordResetEmail)
+	case "magic_link":
+		handleLinkMailer(ctx, fields, msg, mailer.SendMagicLinkEmail)
 	default:
-		log.Println("Unknown event type: %s in queue %s", eventType, queueName)
-		msg.Nack(false, false)
+		log.Warn("Unknown event type", "event_type", eventType)
+		nack(ctx, msg)
 	}
 
 }
 
-func handleSignUpMailer(fields map[string]any, msg amqp.Delivery) {
+func handleSignUpMailer(ctx context.Context, fields map[string]any, msg amqp.Delivery) {
+	log := logger.FromContext(ctx)
+
 	userName, ok := fields["user_name"].(string)
 	if !ok {
-		log.Println("User name missing or invalid in message from queue sign_up")
-		msg.Nack(false, false)
+		log.Warn("User name missing or invalid in message")
+		nack(ctx, msg)
 		return
 	}
 
 	userEmail, ok := fields["email"].(string)
 	if !ok {
-		log.Println("Email missing or invalid in message from queue sign_up")
-		msg.Nack(false, false)
+		log.Warn("Email missing or invalid in message")
+		nack(ctx, msg)
 		return
 	}
 
 	if err := mailer.SendWelcomeEmail(userEmail, userName); err != nil {
-		log.Println("Failed to send welcome email to user %s: %v", userName, err)
+		log.Error("Failed to send welcome email", "error", err.Error())
+		nack(ctx, msg)
+		return
 	}
 
-	msg.Ack(false)
+	ack(ctx, msg)
+}
+
+// handleLinkMailer sends the emails that carry a one-time link (verification, reset, magic link)
+func handleLinkMailer(ctx context.Context, fields map[string]any, msg amqp.Delivery, send func(email, userName, link, expiresIn string) error) {
+	log := logger.FromContext(ctx)
+
+	userEmail, ok := fields["email"].(string)
+	if !ok {
+		log.Warn("Email missing or invalid in message")
+		nack(ctx, msg)
+		return
+	}
+
+	link, ok := fields["link"].(string)
+	if !ok {
+		log.Warn("Link missing or invalid in message")
+		nack(ctx, msg)
+		return
+	}
+
+	userName, _ := fields["user_name"].(string)
+	expiresIn, _ := fields["expires_in"].(string)
+
+	if err := send(userEmail, userName, link, expiresIn); err != nil {
+		log.Error("Failed to send email", "error", err.Error())
+		nack(ctx, msg)
+		return
+	}
+
+	ack(ctx, msg)
 }
 
 func getQueueName(eventType string) string {
 	switch eventType {
-	case "sign_up":
-		return "sign_up"
+	case "sign_up", "verify_email", "password_reset", "magic_link":
+		return eventType
 	default:
 		return ""
 	}
 }
+
+func ack(ctx context.Context, msg amqp.Delivery) {
+	metrics.QueueMessages.WithLabelValues(msg.RoutingKey, "ack").Inc()
+	if err := msg.Ack(false); err != nil {
+		logger.FromContext(ctx).Error("Failed to ack message", "error", err.Error())
+	}
+}
+
+// nack moves a message that cannot be handled to its queue's dead-letter
+// queue. The copy is published on the channel the message arrived on before
+// the message is acked, so the broker sees the two in that order. If the copy
+// cannot be published the message is requeued instead of being dropped.
+func nack(ctx context.Context, msg amqp.Delivery) {
+	log := logger.FromContext(ctx)
+	metrics.QueueMessages.WithLabelValues(msg.RoutingKey, "nack").Inc()
+
+	// Deliveries carry the channel they came from as their acknowledger
+	ch, ok := msg.Acknowledger.(*amqp.Channel)
+	if !ok {
+		if err := msg.Nack(false, false); err != nil {
+			log.Error("Failed to nack message", "error", err.Error())
+		}
+		return
+	}
+
+	err := ch.Publish("", DeadLetterQueue(msg.RoutingKey), false, false, amqp.Publishing{
+		ContentType: msg.ContentType,
+		Headers:     msg.Headers,
+		Body:        msg.Body,
+	})
+	if err != nil {
+		log.Error("Failed to dead-letter message", "error", err.Error())
+		if err := msg.Nack(false, true); err != nil {
+			log.Error("Failed to nack message", "error", err.Error())
+		}
+		return
+	}
+
+	if err := msg.Ack(false); err != nil {
+		log.Error("Failed to ack message", "error", err.Error())
+	}
+}
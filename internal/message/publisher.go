@@ -0,0 +1,196 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Slightly-Techie/st-okr-api/config"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
+	"github.com/Slightly-Techie/st-okr-api/internal/metrics"
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Publisher keeps one connection to RabbitMQ for publishing and reconnects
+// when it drops
+type Publisher struct {
+	url      string
+	mu       sync.Mutex
+	conn     *amqp.Connection
+	ch       *amqp.Channel
+	declared map[string]bool
+	closed   bool
+}
+
+var (
+	defaultPublisher   *Publisher
+	defaultPublisherMu sync.Mutex
+)
+
+func NewPublisher(cfg config.RabbitConfig) *Publisher {
+	return &Publisher{
+		url:      cfg.URL(),
+		declared: make(map[string]bool),
+	}
+}
+
+// DefaultPublisher returns the shared publisher, connecting with the loaded
+// configuration on first use
+func DefaultPublisher() *Publisher {
+	defaultPublisherMu.Lock()
+	defer defaultPublisherMu.Unlock()
+
+	if defaultPublisher == nil {
+		defaultPublisher = NewPublisher(config.ENV.Rabbit)
+	}
+	return defaultPublisher
+}
+
+// PublishMessage publishes an event on the shared publisher
+func PublishMessage(ctx context.Context, eventType string, fields map[string]any) error {
+	return DefaultPublisher().Publish(ctx, eventType, fields)
+}
+
+// channel returns an open channel, reconnecting if needed. Callers hold p.mu.
+func (p *Publisher) channel() (*amqp.Channel, error) {
+	if p.closed {
+		return nil, errors.New("publisher is closed")
+	}
+	if p.conn != nil && !p.conn.IsClosed() && p.ch != nil {
+		return p.ch, nil
+	}
+
+	p.reset()
+
+	conn, err := amqp.Dial(p.url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %v", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open a channel: %v", err)
+	}
+
+	// A channel error (e.g. a failed declare) closes only the channel
+	closed := ch.NotifyClose(make(chan *amqp.Error, 1))
+	go func() {
+		if err := <-closed; err != nil {
+			logger.Warn("Publisher channel closed", "error", err.Error())
+			p.mu.Lock()
+			if p.ch == ch {
+				p.ch = nil
+			}
+			p.mu.Unlock()
+		}
+	}()
+
+	p.conn = conn
+	p.ch = ch
+	p.declared = make(map[string]bool)
+	return ch, nil
+}
+
+func (p *Publisher) reset() {
+	if p.ch != nil {
+		p.ch.Close()
+		p.ch = nil
+	}
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+	}
+}
+
+// Publish sends an event to the queue for its type. The trace context in ctx
+// travels in the message headers.
+func (p *Publisher) Publish(ctx context.Context, eventType string, fields map[string]any) (err error) {
+	// map event type to queue
+	queueName := getQueueName(eventType)
+
+	if queueName == "" {
+		return fmt.Errorf("invalid event type")
+	}
+
+	fields["event_type"] = eventType
+
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal fields: %v", err)
+	}
+
+	_, span, headers := startPublishSpan(ctx, queueName, eventType)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch, err := p.channel()
+	if err != nil {
+		return err
+	}
+
+	if !p.declared[queueName] {
+		if _, err := declareQueue(ch, queueName); err != nil {
+			return err
+		}
+		p.declared[queueName] = true
+	}
+
+	logger.FromContext(ctx).Debug("Publishing message", "queue", queueName, "event_type", eventType)
+
+	// Publish a message
+	err = ch.Publish(
+		"",        // exchange
+		queueName, // routing key
+		false,     // mandatory
+		false,     // immediate
+		amqp.Publishing{
+			ContentType: "application/json",
+			Headers:     headers,
+			Body:        body,
+		})
+
+	if err != nil {
+		metrics.QueueMessages.WithLabelValues(queueName, "publish_error").Inc()
+		// Drop the connection so the next publish starts fresh
+		p.reset()
+		return fmt.Errorf("failed to publish a message: %v", err)
+	}
+
+	metrics.QueueMessages.WithLabelValues(queueName, "publish").Inc()
+	return nil
+}
+
+// Ping checks that the publisher connection is open, reconnecting if needed
+func (p *Publisher) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err := p.channel()
+	return err
+}
+
+// Close closes the connection; later publishes fail
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	p.reset()
+	return nil
+}
@@ -0,0 +1,122 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Slightly-Techie/st-okr-api/config"
+	"github.com/Slightly-Techie/st-okr-api/internal/metrics"
+	"github.com/streadway/amqp"
+)
+
+// DeadLetters counts the messages waiting in each queue's dead-letter queue
+func DeadLetters(cfg config.RabbitConfig) (map[string]int, error) {
+	conn, ch, err := dial(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	defer ch.Close()
+
+	counts := make(map[string]int, len(queues))
+	for _, queueName := range queues {
+		if _, err := declareQueue(ch, queueName); err != nil {
+			return nil, err
+		}
+		q, err := ch.QueueInspect(DeadLetterQueue(queueName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect queue %s: %v", DeadLetterQueue(queueName), err)
+		}
+		counts[queueName] = q.Messages
+	}
+	return counts, nil
+}
+
+// Replay moves messages from the dead-letter queue of queueName back onto
+// queueName and returns how many it moved. At most limit messages are moved,
+// or every message waiting when the replay starts if limit is zero, so
+// messages that fail again are not replayed in a loop. A message leaves the
+// dead-letter queue only once the broker has confirmed its republication.
+func Replay(ctx context.Context, cfg config.RabbitConfig, queueName string, limit int) (int, error) {
+	if !slices.Contains(queues, queueName) {
+		return 0, fmt.Errorf("unknown queue %q", queueName)
+	}
+
+	conn, ch, err := dial(cfg)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	defer ch.Close()
+
+	if _, err := declareQueue(ch, queueName); err != nil {
+		return 0, err
+	}
+	deadLetters := DeadLetterQueue(queueName)
+	q, err := ch.QueueInspect(deadLetters)
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect queue %s: %v", deadLetters, err)
+	}
+	if limit <= 0 || limit > q.Messages {
+		limit = q.Messages
+	}
+
+	if err := ch.Confirm(false); err != nil {
+		return 0, fmt.Errorf("failed to enable publisher confirms: %v", err)
+	}
+	confirms := ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+
+	replayed := 0
+	for replayed < limit {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+
+		msg, ok, err := ch.Get(deadLetters, false)
+		if err != nil {
+			return replayed, fmt.Errorf("failed to read from %s: %v", deadLetters, err)
+		}
+		if !ok {
+			break
+		}
+
+		err = ch.Publish("", queueName, false, false, amqp.Publishing{
+			ContentType: msg.ContentType,
+			Headers:     msg.Headers,
+			Body:        msg.Body,
+		})
+		if err == nil {
+			if confirmation := <-confirms; !confirmation.Ack {
+				err = fmt.Errorf("broker rejected the message")
+			}
+		}
+		if err != nil {
+			// Leave the message where it was
+			_ = msg.Nack(false, true)
+			return replayed, fmt.Errorf("failed to republish to %s: %v", queueName, err)
+		}
+
+		if err := msg.Ack(false); err != nil {
+			return replayed, fmt.Errorf("failed to remove message from %s: %v", deadLetters, err)
+		}
+		metrics.QueueMessages.WithLabelValues(queueName, "replay").Inc()
+		replayed++
+	}
+
+	return replayed, nil
+}
+
+func dial(cfg config.RabbitConfig) (*amqp.Connection, *amqp.Channel, error) {
+	conn, err := amqp.Dial(cfg.URL())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to RabbitMQ: %v", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to open a channel: %v", err)
+	}
+	return conn, ch, nil
+}
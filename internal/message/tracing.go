@@ -0,0 +1,70 @@
+package message
+
+import (
+	"context"
+
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/Slightly-Techie/st-okr-api/internal/message"
+
+// headerCarrier adapts AMQP headers to the OpenTelemetry propagators
+type headerCarrier amqp.Table
+
+func (h headerCarrier) Get(key string) string {
+	value, _ := h[key].(string)
+	return value
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h[key] = value
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// startPublishSpan starts a producer span and returns headers carrying its
+// trace context and baggage (including the request ID)
+func startPublishSpan(ctx context.Context, queueName, eventType string) (context.Context, trace.Span, amqp.Table) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "publish "+queueName,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.destination.name", queueName),
+			attribute.String("messaging.operation.type", "publish"),
+			attribute.String("event_type", eventType),
+		),
+	)
+
+	headers := amqp.Table{}
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(headers))
+	return ctx, span, headers
+}
+
+// startConsumeSpan continues the publisher's trace for a delivered message
+func startConsumeSpan(msg amqp.Delivery, queueName string) (context.Context, trace.Span) {
+	headers := msg.Headers
+	if headers == nil {
+		headers = amqp.Table{}
+	}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), headerCarrier(headers))
+
+	return otel.Tracer(tracerName).Start(ctx, "process "+queueName,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.destination.name", queueName),
+			attribute.String("messaging.operation.type", "process"),
+			attribute.String("request.id", baggage.FromContext(ctx).Member("request_id").Value()),
+		),
+	)
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+const okrCollectTimeout = 5 * time.Second
+
+// OKRCollector reports per-company OKR gauges, queried from the database at
+// scrape time so they never drift from the stored data
+type OKRCollector struct {
+	db               *gorm.DB
+	activeObjectives *prometheus.Desc
+	atRiskKeyResults *prometheus.Desc
+}
+
+func NewOKRCollector(db *gorm.DB) *OKRCollector {
+	return &OKRCollector{
+		db: db,
+		activeObjectives: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "objectives_active"),
+			"Objectives in the active status per company.",
+			[]string{"company_id"}, nil,
+		),
+		atRiskKeyResults: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "key_results_at_risk"),
+			"Key results in the at_risk status per company.",
+			[]string{"company_id"}, nil,
+		),
+	}
+}
+
+func (c *OKRCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.activeObjectives
+	ch <- c.atRiskKeyResults
+}
+
+type companyCount struct {
+	CompanyID string
+	Count     int64
+}
+
+func (c *OKRCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), okrCollectTimeout)
+	defer cancel()
+
+	db := c.db.WithContext(ctx)
+
+	var objectives []companyCount
+	err := db.Model(&models.Objective{}).
+		Select("company_id, COUNT(*) AS count").
+		Where("status = ?", models.ObjectiveStatusActive).
+		Group("company_id").
+		Scan(&objectives).Error
+	if err != nil {
+		logger.Warn("Failed to collect active objectives metric", "error", err.Error())
+	}
+	for _, row := range objectives {
+		ch <- prometheus.MustNewConstMetric(c.activeObjectives, prometheus.GaugeValue, float64(row.Count), row.CompanyID)
+	}
+
+	var keyResults []companyCount
+	err = db.Table("key_results").
+		Select("objectives.company_id, COUNT(*) AS count").
+		Joins("JOIN objectives ON objectives.id = key_results.objective_id").
+		Where("key_results.status = ?", models.StatusRisk).
+		Group("objectives.company_id").
+		Scan(&keyResults).Error
+	if err != nil {
+		logger.Warn("Failed to collect at-risk key results metric", "error", err.Error())
+	}
+	for _, row := range keyResults {
+		ch <- prometheus.MustNewConstMetric(c.atRiskKeyResults, prometheus.GaugeValue, float64(row.Count), row.CompanyID)
+	}
+}
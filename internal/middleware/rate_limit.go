@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
+	"github.com/Slightly-Techie/st-okr-api/internal/metrics"
+	"github.com/Slightly-Techie/st-okr-api/internal/ratelimit"
+	"github.com/Slightly-Techie/st-okr-api/internal/response"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit spends a token from the bucket of the key returned by keyFunc
+// and answers 429 once the bucket is empty. Requests without a key, such as
+// anonymous calls on a per-user limit, are not limited. If the store fails
+// the request is let through rather than taking the API down with it.
+func RateLimit(store ratelimit.Store, scope string, limit ratelimit.Limit, keyFunc func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := keyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		log := logger.FromContext(c.Request.Context())
+
+		result, err := store.Take(c.Request.Context(), scope+":"+key, limit)
+		if err != nil {
+			log.Warn("Rate limit store unavailable", "scope", scope, "error", err.Error())
+			c.Next()
+			return
+		}
+
+		setRateLimitHeaders(c, limit, result)
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+			metrics.RateLimited.WithLabelValues(scope).Inc()
+			log.Warn("Rate limit exceeded", "scope", scope)
+			response.TooManyRequests(c, "Too many requests. Retry later")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ClientIP keys a rate limit by the caller's address
+func ClientIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// UserID keys a rate limit by the signed-in user; it must run after RequireAuth
+func UserID(c *gin.Context) string {
+	return c.GetString("user_id")
+}
+
+// setRateLimitHeaders reports the bucket closest to running out when more
+// than one limit applies to a route
+func setRateLimitHeaders(c *gin.Context, limit ratelimit.Limit, result ratelimit.Result) {
+	header := c.Writer.Header()
+	if current, err := strconv.Atoi(header.Get("X-RateLimit-Remaining")); err == nil && current < result.Remaining {
+		return
+	}
+
+	header.Set("X-RateLimit-Limit", strconv.Itoa(limit.Burst))
+	header.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("X-RateLimit-Reset", strconv.Itoa(seconds(result.ResetAfter)))
+}
+
+// seconds rounds d up to whole seconds, as the headers expect
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -7,6 +7,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // generateRequestID generates a unique request ID
@@ -28,6 +31,16 @@ func RequestID() gin.HandlerFunc {
 		
 		c.Set("request_id", requestID)
 		c.Header("X-Request-ID", requestID)
+
+		// Tag the request span and carry the ID to queue consumers
+		ctx := c.Request.Context()
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("request.id", requestID))
+		if member, err := baggage.NewMember("request_id", requestID); err == nil {
+			if bag, err := baggage.FromContext(ctx).SetMember(member); err == nil {
+				c.Request = c.Request.WithContext(baggage.ContextWithBaggage(ctx, bag))
+			}
+		}
+
 		c.Next()
 	}
 }
\ No newline at end of file
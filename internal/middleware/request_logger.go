@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestLogger attaches a logger carrying the request ID, route and trace ID
+// to the request context and logs each completed request. It must run after
+// RequestID.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		ctx := c.Request.Context()
+
+		log := logger.Custom.With(
+			"request_id", c.GetString("request_id"),
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+			"remote_ip", c.ClientIP(),
+		)
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			log = log.With("trace_id", spanContext.TraceID().String())
+		}
+		c.Request = c.Request.WithContext(logger.NewContext(ctx, log))
+
+		c.Next()
+
+		// Handlers may have added fields such as the user ID
+		log = logger.FromContext(c.Request.Context())
+		fields := []any{
+			"status", c.Writer.Status(),
+			"latency_ms", time.Since(start).Milliseconds(),
+			"bytes", c.Writer.Size(),
+		}
+		switch status := c.Writer.Status(); {
+		case status >= 500:
+			log.Error("Request completed", append(fields, "errors", c.Errors.String())...)
+		case status >= 400:
+			log.Warn("Request completed", fields...)
+		default:
+			log.Info("Request completed", fields...)
+		}
+	}
+}
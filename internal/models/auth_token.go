@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// AuthTokenPurpose defines what a one-time auth token can be redeemed for
+type AuthTokenPurpose string
+
+const (
+	AuthTokenEmailVerification AuthTokenPurpose = "email_verification"
+	AuthTokenPasswordReset     AuthTokenPurpose = "password_reset"
+	AuthTokenMagicLink         AuthTokenPurpose = "magic_link"
+	AuthTokenAuthorizationCode AuthTokenPurpose = "authorization_code"
+	AuthTokenIdentityLink      AuthTokenPurpose = "identity_link"
+)
+
+// AuthToken is a single-use token sent to a user by email or handed to the
+// frontend after a browser login. Only the SHA-256 hash of the token is
+// stored; the raw value exists only in the link or redirect.
+type AuthToken struct {
+	ID        string           `gorm:"column:id;primaryKey;not null" json:"id,omitempty"`
+	UserID    string           `gorm:"column:user_id;not null;index" json:"user_id,omitempty"`
+	Purpose   AuthTokenPurpose `gorm:"column:purpose;type:varchar(50);not null" json:"purpose,omitempty"`
+	TokenHash string           `gorm:"column:token_hash;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time        `gorm:"column:expires_at;not null" json:"expires_at,omitempty"`
+	UsedAt    *time.Time       `gorm:"column:used_at" json:"used_at,omitempty"`
+	CreatedAt time.Time        `gorm:"column:created_at;not null;default:current_timestamp" json:"created_at,omitempty"`
+}
@@ -10,4 +10,14 @@ type Company struct {
 	Memberships []Membership `gorm:"foreignKey:CompanyID" json:"-"`
 	CreatedAt   time.Time    `gorm:"column:created_at" json:"created_at,omitempty"`
 	UpdatedAt   time.Time    `gorm:"column:updated_at" json:"updated_at,omitempty"`
+	// AtRiskThreshold and BehindThreshold are the company's StatusThresholds
+	AtRiskThreshold float64 `gorm:"column:at_risk_threshold;not null;default:10" json:"at_risk_threshold"`
+	BehindThreshold float64 `gorm:"column:behind_threshold;not null;default:25" json:"behind_threshold"`
+	// ReportingCurrency is the ISO 4217 code currency key results are
+	// converted to, if the company has one
+	ReportingCurrency string `gorm:"column:reporting_currency;type:varchar(3)" json:"reporting_currency,omitempty"`
+}
+
+func (c *Company) StatusThresholds() StatusThresholds {
+	return StatusThresholds{AtRisk: c.AtRiskThreshold, Behind: c.BehindThreshold}
 }
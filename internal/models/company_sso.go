@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// SSOProtocol defines the single sign-on protocols a company can configure
+type SSOProtocol string
+
+const (
+	SSOProtocolOIDC SSOProtocol = "oidc"
+	SSOProtocolSAML SSOProtocol = "saml"
+)
+
+// CompanySSOConfig holds a company's identity provider settings. Users whose
+// email domain is one of the company's verified domains can sign in through it
+// and are provisioned just in time with DefaultRole.
+type CompanySSOConfig struct {
+	ID               string      `gorm:"column:id;primaryKey;not null" json:"id,omitempty"`
+	CompanyID        string      `gorm:"column:company_id;not null;uniqueIndex" json:"company_id,omitempty"`
+	Protocol         SSOProtocol `gorm:"column:protocol;type:varchar(20);not null" json:"protocol,omitempty" validate:"oneof=oidc saml"`
+	Enabled          bool        `gorm:"column:enabled;not null;default:false" json:"enabled"`
+	OIDCIssuer       string      `gorm:"column:oidc_issuer" json:"oidc_issuer,omitempty"`
+	OIDCClientID     string      `gorm:"column:oidc_client_id" json:"oidc_client_id,omitempty"`
+	OIDCClientSecret string      `gorm:"column:oidc_client_secret" json:"-"`
+	SAMLMetadataURL  string      `gorm:"column:saml_metadata_url" json:"saml_metadata_url,omitempty"`
+	SAMLMetadataXML  string      `gorm:"column:saml_metadata_xml;type:text" json:"saml_metadata_xml,omitempty"`
+	DefaultRole      RoleType    `gorm:"column:default_role;type:varchar(50);not null;default:'member'" json:"default_role,omitempty" validate:"oneof=admin member viewer"`
+	EnforceSSO       bool        `gorm:"column:enforce_sso;not null;default:false" json:"enforce_sso"`
+	CreatedAt        time.Time   `gorm:"column:created_at;not null;default:current_timestamp" json:"created_at,omitempty"`
+	UpdatedAt        time.Time   `gorm:"column:updated_at;not null;default:current_timestamp;autoUpdateTime" json:"updated_at,omitempty"`
+}
+
+// CompanyDomain is an email domain claimed by a company. It is only trusted
+// for SSO once ownership is proven through a DNS TXT record. Any number of
+// companies may claim a domain, but only one can verify it.
+type CompanyDomain struct {
+	ID                string     `gorm:"column:id;primaryKey;not null" json:"id,omitempty"`
+	CompanyID         string     `gorm:"column:company_id;not null;index;uniqueIndex:idx_company_domains_company_domain" json:"company_id,omitempty"`
+	Domain            string     `gorm:"column:domain;not null;uniqueIndex:idx_company_domains_company_domain;uniqueIndex:idx_company_domains_verified_domain,where:verified_at IS NOT NULL" json:"domain,omitempty"`
+	VerificationToken string     `gorm:"column:verification_token;not null" json:"verification_token,omitempty"`
+	VerifiedAt        *time.Time `gorm:"column:verified_at" json:"verified_at,omitempty"`
+	CreatedAt         time.Time  `gorm:"column:created_at;not null;default:current_timestamp" json:"created_at,omitempty"`
+	UpdatedAt         time.Time  `gorm:"column:updated_at;not null;default:current_timestamp;autoUpdateTime" json:"updated_at,omitempty"`
+}
+
+// DomainVerificationRecord is the TXT record value that proves domain ownership
+func (d *CompanyDomain) DomainVerificationRecord() string {
+	return "st-okr-verification=" + d.VerificationToken
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Cycle is a named period a company plans its OKRs in, such as a fiscal
+// quarter. Objectives are not tied to a cycle; they belong to every cycle
+// their dates overlap. EndDate is the last day of the cycle.
+type Cycle struct {
+	ID        string    `gorm:"column:id;primaryKey;not null" json:"id"`
+	CompanyID string    `gorm:"column:company_id;not null;uniqueIndex:idx_cycles_company_name" json:"company_id"`
+	Name      string    `gorm:"column:name;not null;uniqueIndex:idx_cycles_company_name" json:"name"`
+	StartDate time.Time `gorm:"column:start_date;type:date;not null" json:"start_date"`
+	EndDate   time.Time `gorm:"column:end_date;type:date;not null" json:"end_date"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+// Range is the half-open range of times the cycle covers
+func (c *Cycle) Range() (time.Time, time.Time) {
+	return c.StartDate, c.EndDate.AddDate(0, 0, 1)
+}
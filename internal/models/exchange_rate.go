@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// ExchangeRate is how many units of ToCurrency one unit of FromCurrency
+// buys, as kept by a company's admins to convert currency key results into
+// its reporting currency
+type ExchangeRate struct {
+	CompanyID    string    `gorm:"column:company_id;primaryKey" json:"-"`
+	FromCurrency string    `gorm:"column:from_currency;primaryKey;type:varchar(3)" json:"from"`
+	ToCurrency   string    `gorm:"column:to_currency;primaryKey;type:varchar(3)" json:"to"`
+	Rate         float64   `gorm:"column:rate;not null" json:"rate"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;not null;default:current_timestamp;autoUpdateTime" json:"updated_at"`
+}
+
+type ExchangeRates []ExchangeRate
+
+// Rate finds the rate from one currency to another, inverting the rate the
+// other way round when only that one is kept
+func (r ExchangeRates) Rate(from, to string) (float64, bool) {
+	for _, rate := range r {
+		if rate.FromCurrency == from && rate.ToCurrency == to && rate.Rate > 0 {
+			return rate.Rate, true
+		}
+	}
+	for _, rate := range r {
+		if rate.FromCurrency == to && rate.ToCurrency == from && rate.Rate > 0 {
+			return 1 / rate.Rate, true
+		}
+	}
+	return 0, false
+}
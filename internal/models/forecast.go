@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// StatusThresholds decide, in percentage points below the expected
+// progress, when a key result is at risk and when it is behind
+type StatusThresholds struct {
+	AtRisk float64 `json:"at_risk"`
+	Behind float64 `json:"behind"`
+}
+
+// DefaultStatusThresholds apply to companies that have not set their own
+var DefaultStatusThresholds = StatusThresholds{AtRisk: 10, Behind: 25}
+
+// ExpectedProgress is the progress of something running from start to end
+// at a steady pace by now: 0 until start, 100 from end
+func ExpectedProgress(start, end, now time.Time) float64 {
+	switch {
+	case !now.After(start):
+		return 0
+	case !now.Before(end):
+		return 100
+	}
+	return float64(now.Sub(start)) / float64(end.Sub(start)) * 100
+}
+
+// ProgressSample is the progress recorded at one time
+type ProgressSample struct {
+	At       time.Time
+	Progress float64
+}
+
+// ProjectCompletion extends the least-squares trend of samples to 100% and
+// returns when it gets there. It returns nil when there is no trend to
+// follow: fewer than two distinct times, or progress that is not rising.
+func ProjectCompletion(samples []ProgressSample) *time.Time {
+	if len(samples) < 2 {
+		return nil
+	}
+
+	// Times are in days since the first sample to keep the sums small
+	origin := samples[0].At
+	var sumX, sumY, sumXY, sumXX float64
+	latest := samples[0]
+	for _, s := range samples {
+		x := s.At.Sub(origin).Hours() / 24
+		sumX += x
+		sumY += s.Progress
+		sumXY += x * s.Progress
+		sumXX += x * x
+		if s.At.After(latest.At) {
+			latest = s
+		}
+	}
+	if latest.Progress >= 100 {
+		return nil
+	}
+
+	n := float64(len(samples))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return nil
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	if slope <= 0 {
+		return nil
+	}
+	intercept := (sumY - slope*sumX) / n
+
+	days := (100 - intercept) / slope
+	projected := origin.Add(time.Duration(days * 24 * float64(time.Hour)))
+	// The trend may have passed 100% already while the latest value has not
+	if projected.Before(latest.At) {
+		projected = latest.At
+	}
+	return &projected
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpectedProgress(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 10)
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		now        time.Time
+		want       float64
+	}{
+		{"before start", start, end, start.Add(-time.Hour), 0},
+		{"at start", start, end, start, 0},
+		{"halfway", start, end, start.AddDate(0, 0, 5), 50},
+		{"a quarter in", start, end, start.Add(60 * time.Hour), 25},
+		{"at the due date", start, end, end, 100},
+		{"past due", start, end, end.AddDate(0, 1, 0), 100},
+		{"no time to run", start, start, start.Add(time.Minute), 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpectedProgress(tt.start, tt.end, tt.now); got != tt.want {
+				t.Errorf("ExpectedProgress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectCompletion(t *testing.T) {
+	origin := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return origin.AddDate(0, 0, n) }
+
+	tests := []struct {
+		name    string
+		samples []ProgressSample
+		// want is nil when no completion should be projected
+		want *time.Time
+	}{
+		{"no samples", nil, nil},
+		{"one sample", []ProgressSample{{day(0), 10}}, nil},
+		{"samples at one time", []ProgressSample{{day(0), 10}, {day(0), 20}}, nil},
+		{"zero slope", []ProgressSample{{day(0), 40}, {day(1), 40}, {day(2), 40}}, nil},
+		{"falling", []ProgressSample{{day(0), 60}, {day(1), 50}, {day(2), 40}}, nil},
+		{"already complete", []ProgressSample{{day(0), 50}, {day(1), 100}}, nil},
+		{"steady rise", []ProgressSample{{day(0), 10}, {day(1), 20}, {day(2), 30}}, timePtr(day(9))},
+		{"out of order", []ProgressSample{{day(0), 10}, {day(2), 30}, {day(1), 20}}, timePtr(day(9))},
+		{"trend already past 100", []ProgressSample{{day(0), 0}, {day(1), 90}, {day(2), 90}}, timePtr(day(2))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ProjectCompletion(tt.samples)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil:
+				t.Errorf("ProjectCompletion() = %v, want %v", got, tt.want)
+			case !got.Equal(*tt.want):
+				t.Errorf("ProjectCompletion() = %v, want %v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
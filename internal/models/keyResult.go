@@ -2,9 +2,12 @@ package models
 
 import (
 	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/money"
 )
 
 type MetricType string
+type MetricDirection string
 type KeyResultProgressStatus string
 type AssigneeType string
 
@@ -13,6 +16,14 @@ const (
 	MetricTypePercentage MetricType = "percentage"
 	MetricTypeBinary     MetricType = "binary"
 	MetrictTypeCurrency  MetricType = "currency"
+	// MetricTypeRange is met while the value stays between RangeMin and
+	// RangeMax
+	MetricTypeRange MetricType = "range"
+)
+
+const (
+	DirectionIncrease MetricDirection = "increase"
+	DirectionDecrease MetricDirection = "decrease"
 )
 
 const (
@@ -36,6 +47,10 @@ type KeyResult struct {
 	MetricType   MetricType              `gorm:"column:metric_type;type:varchar(50);not null;default:'percentage'" json:"metric_type,omitempty" validate:"required,metric_type"`
 	TargetValue  float64                 `gorm:"column:target_value;not null" json:"target_value"`
 	CurrentValue float64                 `gorm:"column:current_value;" json:"current_value"`
+	StartValue   float64                 `gorm:"column:start_value;not null;default:0" json:"start_value"`
+	Direction    MetricDirection         `gorm:"column:direction;type:varchar(20);not null;default:'increase'" json:"direction,omitempty"`
+	RangeMin     float64                 `gorm:"column:range_min;not null;default:0" json:"range_min,omitempty"`
+	RangeMax     float64                 `gorm:"column:range_max;not null;default:0" json:"range_max,omitempty"`
 	Progress     float64                 `gorm:"column:progress;default:0" json:"progress_percentage"`
 	Status       KeyResultProgressStatus `gorm:"column:status;type:varchar(50);default:'not_started'" json:"status,omitempty" validate:"oneof=not_started on_track at_risk behind completed"`
 	AssigneeType AssigneeType            `gorm:"column:assignee_type;type:varchar(50);not null;default:'team'" json:"assignee_type,omitempty" validate:"assignee_type"`
@@ -44,51 +59,188 @@ type KeyResult struct {
 	DueDate      time.Time               `gorm:"column:due_date; not null" json:"due_date,omitempty" validate:"due_date"`
 	CreatedAt    time.Time               `gorm:"column:created_at;not null;default:current_timestamp" json:"created_at,omitempty"`
 	UpdatedAt    time.Time               `gorm:"column:updated_at;not null;default:current_timestamp;autoUpdateTime" json:"updated_at,omitempty"`
+	// ProjectedCompletionAt is when the recent trend reaches 100%, if it rises
+	ProjectedCompletionAt *time.Time `gorm:"column:projected_completion_at" json:"projected_completion_at,omitempty"`
+	// Currency is the ISO 4217 code of a currency key result. Its values are
+	// the minor unit amounts, which progress is measured in; SetAmounts
+	// derives the float values from them on every write, for the readers
+	// that treat all metric types alike, such as snapshots and exports.
+	Currency     string `gorm:"column:currency;type:varchar(3)" json:"currency,omitempty"`
+	CurrentMinor int64  `gorm:"column:current_minor;not null;default:0" json:"-"`
+	TargetMinor  int64  `gorm:"column:target_minor;not null;default:0" json:"-"`
+	StartMinor   int64  `gorm:"column:start_minor;not null;default:0" json:"-"`
+	// Amounts and ReportingAmounts are filled in for responses only
+	Amounts          *Amounts `gorm:"-" json:"amounts,omitempty"`
+	ReportingAmounts *Amounts `gorm:"-" json:"reporting_amounts,omitempty"`
 	// UpdatedBy []string  `gorm:"column:updated_by;type:jsonb;index" json:"user_id,omitempty"`
 }
 
-// func (k *KeyResult) UpdateProgress() {
-// 	if k.TargetValue == 0 {
-// 		k.Progress = 0
-// 	}
-// 	k.Progress = (k.CurrentValue / k.TargetValue) * 100
-// }
-
+// UpdateProgress measures how far the current value has moved from the
+// start value towards the target, whichever way the key result goes, in
+// exact minor units for a currency key result. A range key result is 100%
+// inside its range and loses progress with its distance outside, reaching 0
+// a whole range width away.
 func (k *KeyResult) UpdateProgress() {
 	switch k.MetricType {
 	case MetricTypeBinary:
 		if k.CurrentValue == 1 {
 			k.TargetValue = 1
 			k.Progress = 100
+		} else {
+			k.Progress = 0
 		}
 	case MetricTypeNumeric, MetrictTypeCurrency, MetricTypePercentage:
-		if k.TargetValue == 0 {
+		start, target, current := k.StartValue, k.TargetValue, k.CurrentValue
+		if k.MetricType == MetrictTypeCurrency && k.Currency != "" {
+			start, target, current = float64(k.StartMinor), float64(k.TargetMinor), float64(k.CurrentMinor)
+		}
+		span := target - start
+		if span == 0 {
 			k.Progress = 0
+			return
+		}
+		k.Progress = max((current-start)/span*100, 0)
+	case MetricTypeRange:
+		var distance float64
+		switch {
+		case k.CurrentValue < k.RangeMin:
+			distance = k.RangeMin - k.CurrentValue
+		case k.CurrentValue > k.RangeMax:
+			distance = k.CurrentValue - k.RangeMax
 		}
-		if k.TargetValue > 0 {
-			k.Progress = (k.CurrentValue / k.TargetValue) * 100
+		width := k.RangeMax - k.RangeMin
+		switch {
+		case distance == 0:
+			k.Progress = 100
+		case width <= 0:
+			k.Progress = 0
+		default:
+			k.Progress = max(100-distance/width*100, 0)
 		}
+	}
+}
 
+// SetDirection derives the direction from the values: a target above the
+// start value is an increase and one below is a decrease. Only when they are
+// equal, as in a key result without values yet, is a given direction kept.
+func (k *KeyResult) SetDirection() {
+	if k.MetricType == MetricTypeRange {
+		return
+	}
+	switch {
+	case k.TargetValue > k.StartValue:
+		k.Direction = DirectionIncrease
+	case k.TargetValue < k.StartValue:
+		k.Direction = DirectionDecrease
+	case k.Direction == "":
+		k.Direction = DirectionIncrease
 	}
 }
 
-func (k *KeyResult) UpdateStatus() {
-	now := time.Now()
+// UpdateStatus compares the progress with the progress expected by now
+// from a steady pace between the start and due dates. Falling more than
+// thresholds.AtRisk points short is at risk and more than thresholds.Behind
+// is behind, as is missing the due date.
+func (k *KeyResult) UpdateStatus(thresholds StatusThresholds, now time.Time) {
+	if k.MetricType == MetricTypeRange {
+		k.updateRangeStatus(thresholds, now)
+		return
+	}
+
+	gap := ExpectedProgress(k.StartDate, k.DueDate, now) - k.Progress
 
 	switch {
-	case k.Progress == 0 && now.Before(k.StartDate):
-		k.Status = "not_started"
+	case k.Progress >= 100:
+		k.Status = StatusCompleted
+	case now.After(k.DueDate):
+		k.Status = StatusBehind
+	case gap > thresholds.Behind:
+		k.Status = StatusBehind
+	case gap > thresholds.AtRisk:
+		k.Status = StatusRisk
+	case k.Progress == 0:
+		k.Status = StatusNotStarted
+	default:
+		k.Status = StatusInProgress
+	}
+}
 
-	case k.Progress == 100:
-		k.Status = "completed"
+// updateRangeStatus expects a range key result inside its range the whole
+// time, and only completes it once the due date has passed inside the range
+func (k *KeyResult) updateRangeStatus(thresholds StatusThresholds, now time.Time) {
+	gap := 100 - k.Progress
 
-	case k.Progress > 0 && k.Progress < 100:
-		if now.After(k.DueDate) || (k.DueDate.Sub(now).Hours() < 24*7 && k.Progress < 50) {
-			k.Status = "at_risk"
-		} else {
-			k.Status = "on_track"
-		}
+	switch {
+	case now.Before(k.StartDate):
+		k.Status = StatusNotStarted
+	case now.After(k.DueDate) && gap == 0:
+		k.Status = StatusCompleted
+	case now.After(k.DueDate), gap > thresholds.Behind:
+		k.Status = StatusBehind
+	case gap > thresholds.AtRisk:
+		k.Status = StatusRisk
 	default:
-		k.Status = "behind"
+		k.Status = StatusInProgress
+	}
+}
+
+// Amounts are the values of a currency key result in one currency, in minor
+// units and formatted for display
+type Amounts struct {
+	Currency       string `json:"currency"`
+	Current        int64  `json:"current_minor"`
+	Target         int64  `json:"target_minor"`
+	Start          int64  `json:"start_minor"`
+	CurrentDisplay string `json:"current_display"`
+	TargetDisplay  string `json:"target_display"`
+	StartDisplay   string `json:"start_display"`
+	// Rate is the exchange rate the amounts were converted at, if they were
+	Rate float64 `json:"rate,omitempty"`
+}
+
+func newAmounts(currency money.Currency, current, target, start int64) *Amounts {
+	return &Amounts{
+		Currency:       currency.Code,
+		Current:        current,
+		Target:         target,
+		Start:          start,
+		CurrentDisplay: currency.Format(current),
+		TargetDisplay:  currency.Format(target),
+		StartDisplay:   currency.Format(start),
+	}
+}
+
+// SetAmounts sets the values of a currency key result in minor units of its
+// currency, and overwrites the float values with what they amount to
+func (k *KeyResult) SetAmounts(current, target, start int64) {
+	currency, _ := money.Lookup(k.Currency)
+	k.CurrentMinor, k.TargetMinor, k.StartMinor = current, target, start
+	k.CurrentValue = currency.ToMajor(current)
+	k.TargetValue = currency.ToMajor(target)
+	k.StartValue = currency.ToMajor(start)
+}
+
+// FormatAmounts fills in Amounts for a currency key result, and
+// ReportingAmounts when it can be converted into the reporting currency at
+// one of rates. Other key results are left without either.
+func (k *KeyResult) FormatAmounts(reporting string, rates ExchangeRates) {
+	currency, ok := money.Lookup(k.Currency)
+	if k.MetricType != MetrictTypeCurrency || !ok {
+		return
+	}
+	k.Amounts = newAmounts(currency, k.CurrentMinor, k.TargetMinor, k.StartMinor)
+
+	to, ok := money.Lookup(reporting)
+	if !ok || to.Code == currency.Code {
+		return
+	}
+	rate, ok := rates.Rate(currency.Code, to.Code)
+	if !ok {
+		return
 	}
+	k.ReportingAmounts = newAmounts(to,
+		currency.Convert(k.CurrentMinor, to, rate),
+		currency.Convert(k.TargetMinor, to, rate),
+		currency.Convert(k.StartMinor, to, rate))
+	k.ReportingAmounts.Rate = rate
 }
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+type SnapshotSubject string
+
+const (
+	SnapshotSubjectObjective SnapshotSubject = "objective"
+	SnapshotSubjectKeyResult SnapshotSubject = "key_result"
+)
+
+// ProgressSnapshot records the progress of an objective or key result on one
+// day. Later snapshots of the same day replace earlier ones.
+type ProgressSnapshot struct {
+	SubjectType SnapshotSubject `gorm:"column:subject_type;type:varchar(20);primaryKey" json:"subject_type"`
+	SubjectID   string          `gorm:"column:subject_id;primaryKey" json:"subject_id"`
+	Day         time.Time       `gorm:"column:day;type:date;primaryKey" json:"day"`
+	Progress    float64         `gorm:"column:progress;not null" json:"progress"`
+	// Value is the key result's current value; it is nil for objectives
+	Value     *float64  `gorm:"column:value" json:"value,omitempty"`
+	CreatedAt time.Time `gorm:"column:created_at;not null;default:current_timestamp" json:"created_at,omitempty"`
+	UpdatedAt time.Time `gorm:"column:updated_at;not null;default:current_timestamp;autoUpdateTime" json:"updated_at,omitempty"`
+}
+
+// SnapshotDay truncates t to the UTC day its snapshot is filed under
+func SnapshotDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -3,13 +3,34 @@ package models
 import "time"
 
 type User struct {
-	ID         string    `gorm:"column:id;primaryKey;not null" json:"id,omitempty"`
-	ProviderID string    `gorm:"column:provider_id;not null" json:"provider_id,omitempty"`
-	FirstName  string    `gorm:"column:first_name;not null" json:"first_name,omitempty"`
-	LastName   string    `gorm:"column:last_name;not null" json:"last_name,omitempty"`
-	UserName   string    `gorm:"column:user_name;not null" json:"user_name,omitempty"`
-	Email      string    `gorm:"column:email;unique;not null" json:"email,omitempty"`
-	AvatarURL  string    `gorm:"column:avatar_url;unique;not null" json:"avatar_url,omitempty"`
-	CreatedAt  time.Time `gorm:"column:created_at" json:"created_at,omitempty"`
-	UpdatedAt  time.Time `gorm:"column:updated_at" json:"updated_at,omitempty"`
-}
\ No newline at end of file
+	ID string `gorm:"column:id;primaryKey;not null" json:"id,omitempty"`
+	// Deprecated: provider identities live in UserIdentity. Kept so accounts
+	// created before account linking can be matched and backfilled.
+	ProviderID      string         `gorm:"column:provider_id" json:"provider_id,omitempty"`
+	FirstName       string         `gorm:"column:first_name;not null" json:"first_name,omitempty"`
+	LastName        string         `gorm:"column:last_name;not null" json:"last_name,omitempty"`
+	UserName        string         `gorm:"column:user_name;not null" json:"user_name,omitempty"`
+	Email           string         `gorm:"column:email;unique;not null" json:"email,omitempty"`
+	AvatarURL       string         `gorm:"column:avatar_url" json:"avatar_url,omitempty"`
+	PasswordHash    string         `gorm:"column:password_hash" json:"-"`
+	EmailVerifiedAt *time.Time     `gorm:"column:email_verified_at" json:"email_verified_at,omitempty"`
+	TokensRevokedAt *time.Time     `gorm:"column:tokens_revoked_at" json:"-"`
+	Identities      []UserIdentity `gorm:"foreignKey:UserID" json:"identities,omitempty"`
+	CreatedAt       time.Time      `gorm:"column:created_at" json:"created_at,omitempty"`
+	UpdatedAt       time.Time      `gorm:"column:updated_at" json:"updated_at,omitempty"`
+}
+
+// UserIdentity links an external provider account (google, github, ...) to a User.
+// A user can have several identities, one per provider account. EmailVerified
+// records whether the provider vouched for the user's email when it was linked;
+// other identities are dropped once the owner of the email proves it.
+type UserIdentity struct {
+	ID             string    `gorm:"column:id;primaryKey;not null" json:"id,omitempty"`
+	UserID         string    `gorm:"column:user_id;not null;index" json:"user_id,omitempty"`
+	Provider       string    `gorm:"column:provider;not null;uniqueIndex:idx_user_identities_provider_user" json:"provider,omitempty"`
+	ProviderUserID string    `gorm:"column:provider_user_id;not null;uniqueIndex:idx_user_identities_provider_user" json:"provider_user_id,omitempty"`
+	Email          string    `gorm:"column:email" json:"email,omitempty"`
+	EmailVerified  bool      `gorm:"column:email_verified;not null;default:false" json:"email_verified"`
+	CreatedAt      time.Time `gorm:"column:created_at" json:"created_at,omitempty"`
+	UpdatedAt      time.Time `gorm:"column:updated_at" json:"updated_at,omitempty"`
+}
@@ -0,0 +1,150 @@
+// Package money handles ISO 4217 amounts as integers of minor units (cents
+// for USD, yen for JPY) so that they add up exactly
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency
+type Currency struct {
+	Code string
+	// Digits is the number of minor unit digits: 2 for USD, 0 for JPY
+	Digits int
+	// Symbol prefixes formatted amounts; the code is used when empty
+	Symbol string
+}
+
+// activeCodes are the ISO 4217 currencies in circulation, without funds
+// and precious metals
+const activeCodes = "AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BRL BSD BTN BWP BYN BZD " +
+	"CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD " +
+	"HKD HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL LYD " +
+	"MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN PYG " +
+	"QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD " +
+	"TZS UAH UGX USD UYU UZS VES VND VUV WST XAF XCD XCG XOF XPF YER ZAR ZMW ZWG"
+
+// minorDigits lists the currencies whose minor unit is not a hundredth
+var minorDigits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+var symbols = map[string]string{
+	"USD": "$", "EUR": "€", "GBP": "£", "JPY": "¥", "CNY": "CN¥", "INR": "₹", "KRW": "₩", "GHS": "GH₵",
+	"NGN": "₦", "KES": "KSh ", "ZAR": "R ", "CAD": "CA$", "AUD": "A$", "NZD": "NZ$", "HKD": "HK$",
+	"SGD": "S$", "TWD": "NT$", "MXN": "MX$", "BRL": "R$", "ILS": "₪", "TRY": "₺", "PHP": "₱",
+	"THB": "฿", "VND": "₫", "UAH": "₴", "PLN": "zł ", "XAF": "FCFA ", "XOF": "CFA ",
+}
+
+var currencies = func() map[string]Currency {
+	all := make(map[string]Currency)
+	for _, code := range strings.Fields(activeCodes) {
+		digits, ok := minorDigits[code]
+		if !ok {
+			digits = 2
+		}
+		all[code] = Currency{Code: code, Digits: digits, Symbol: symbols[code]}
+	}
+	return all
+}()
+
+// Lookup returns the currency with the given code, in any case
+func Lookup(code string) (Currency, bool) {
+	c, ok := currencies[strings.ToUpper(strings.TrimSpace(code))]
+	return c, ok
+}
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+func (c Currency) scale() float64 {
+	return math.Pow10(c.Digits)
+}
+
+// Parse reads a decimal amount such as "1234.5" or "-12" into minor units
+// without going through floating point. More decimals than the currency has
+// are an error rather than being rounded away.
+func (c Currency) Parse(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" && fraction == "" {
+		return 0, fmt.Errorf("%w: %q is not a number", ErrInvalidAmount, value)
+	}
+	if len(fraction) > c.Digits {
+		return 0, fmt.Errorf("%w: %s amounts have at most %d decimal places", ErrInvalidAmount, c.Code, c.Digits)
+	}
+	digits := whole + fraction + strings.Repeat("0", c.Digits-len(fraction))
+	if strings.Trim(digits, "0123456789") != "" {
+		return 0, fmt.Errorf("%w: %q is not a number", ErrInvalidAmount, value)
+	}
+
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is too large", ErrInvalidAmount, value)
+	}
+	if negative {
+		minor = -minor
+	}
+	return minor, nil
+}
+
+// FromMajor rounds a value in major units, such as dollars, to minor units
+func (c Currency) FromMajor(value float64) int64 {
+	return int64(math.Round(value * c.scale()))
+}
+
+// ToMajor returns minor units as a value in major units
+func (c Currency) ToMajor(minor int64) float64 {
+	return float64(minor) / c.scale()
+}
+
+// Format renders minor units with the currency's symbol, thousands
+// separators and all of its decimals, as in "$1,234.50" or "CHF 12.00"
+func (c Currency) Format(minor int64) string {
+	var b strings.Builder
+	if minor < 0 {
+		b.WriteByte('-')
+	}
+	if c.Symbol != "" {
+		b.WriteString(c.Symbol)
+	} else {
+		b.WriteString(c.Code + " ")
+	}
+
+	digits := strconv.FormatUint(absMinor(minor), 10)
+	if len(digits) <= c.Digits {
+		digits = strings.Repeat("0", c.Digits-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-c.Digits], digits[len(digits)-c.Digits:]
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	if c.Digits > 0 {
+		b.WriteString("." + fraction)
+	}
+	return b.String()
+}
+
+// Convert turns minor units of c into minor units of to, where rate is the
+// number of units of to that one unit of c buys
+func (c Currency) Convert(minor int64, to Currency, rate float64) int64 {
+	return to.FromMajor(c.ToMajor(minor) * rate)
+}
+
+func absMinor(minor int64) uint64 {
+	if minor < 0 {
+		return uint64(-(minor + 1)) + 1
+	}
+	return uint64(minor)
+}
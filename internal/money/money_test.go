@@ -0,0 +1,182 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func mustLookup(t *testing.T, code string) Currency {
+	t.Helper()
+	c, ok := Lookup(code)
+	if !ok {
+		t.Fatalf("Lookup(%q) found no currency", code)
+	}
+	return c
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		code       string
+		wantOK     bool
+		wantCode   string
+		wantDigits int
+	}{
+		{"USD", true, "USD", 2},
+		{" eur ", true, "EUR", 2},
+		{"JPY", true, "JPY", 0},
+		{"KWD", true, "KWD", 3},
+		{"XAU", false, "", 0},
+		{"", false, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got, ok := Lookup(tt.code)
+			if ok != tt.wantOK || got.Code != tt.wantCode || got.Digits != tt.wantDigits {
+				t.Errorf("Lookup(%q) = %+v, %v, want %s with %d digits, %v", tt.code, got, ok, tt.wantCode, tt.wantDigits, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency string
+		value    string
+		want     int64
+		wantErr  bool
+	}{
+		{"whole", "USD", "1234", 123400, false},
+		{"cents", "USD", "1234.50", 123450, false},
+		{"one decimal", "USD", "1234.5", 123450, false},
+		{"trailing point", "USD", "12.", 1200, false},
+		{"leading point", "USD", ".5", 50, false},
+		{"negative", "USD", "-12.34", -1234, false},
+		{"surrounding space", "USD", " 7.01 ", 701, false},
+		{"too many decimals", "USD", "1.005", 0, true},
+		{"no decimals", "JPY", "1234", 1234, false},
+		{"decimals without a minor unit", "JPY", "1234.5", 0, true},
+		{"three decimals", "KWD", "1.234", 1234, false},
+		{"fils", "KWD", "0.005", 5, false},
+		{"four decimals", "KWD", "1.2345", 0, true},
+		{"empty", "USD", "", 0, true},
+		{"sign only", "USD", "-", 0, true},
+		{"thousands separator", "USD", "1,000", 0, true},
+		{"plus sign", "USD", "+5", 0, true},
+		{"double minus", "USD", "--5", 0, true},
+		{"exponent", "USD", "1e3", 0, true},
+		{"too large", "USD", "92233720368547758.08", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mustLookup(t, tt.currency).Parse(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAmount) {
+					t.Errorf("Parse(%q) error = %v, want ErrInvalidAmount", tt.value, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Parse(%q) = %d, %v, want %d", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromMajor(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency string
+		value    float64
+		want     int64
+	}{
+		{"exact", "USD", 12.5, 1250},
+		{"float noise", "USD", 19.99, 1999},
+		{"half rounds up", "USD", 0.125, 13},
+		{"negative half rounds down", "USD", -0.125, -13},
+		{"below half", "USD", 0.124, 12},
+		{"no minor unit", "JPY", 1234.5, 1235},
+		{"three decimals", "KWD", 1.2345, 1235},
+		{"zero", "KWD", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustLookup(t, tt.currency).FromMajor(tt.value); got != tt.want {
+				t.Errorf("FromMajor(%v) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToMajor(t *testing.T) {
+	tests := []struct {
+		currency string
+		minor    int64
+		want     float64
+	}{
+		{"USD", 123450, 1234.5},
+		{"JPY", 1500, 1500},
+		{"KWD", 1500, 1.5},
+		{"KWD", -5, -0.005},
+	}
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			if got := mustLookup(t, tt.currency).ToMajor(tt.minor); got != tt.want {
+				t.Errorf("ToMajor(%d) = %v, want %v", tt.minor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency string
+		minor    int64
+		want     string
+	}{
+		{"zero", "USD", 0, "$0.00"},
+		{"cents only", "USD", 5, "$0.05"},
+		{"thousands", "USD", 123450, "$1,234.50"},
+		{"millions", "EUR", 123456789, "€1,234,567.89"},
+		{"negative", "GBP", -123450, "-£1,234.50"},
+		{"code without a symbol", "CHF", 1200, "CHF 12.00"},
+		{"no minor unit", "JPY", 1234567, "¥1,234,567"},
+		{"no minor unit zero", "JPY", 0, "¥0"},
+		{"three decimals", "KWD", 1234, "KWD 1.234"},
+		{"fils only", "KWD", 5, "KWD 0.005"},
+		{"thousands with three decimals", "KWD", 1234567, "KWD 1,234.567"},
+		{"smallest amount", "USD", math.MinInt64, "-$92,233,720,368,547,758.08"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustLookup(t, tt.currency).Format(tt.minor); got != tt.want {
+				t.Errorf("Format(%d) = %q, want %q", tt.minor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		minor    int64
+		rate     float64
+		want     int64
+	}{
+		{"same digits", "EUR", "USD", 10000, 1.08, 10800},
+		{"into no minor unit", "USD", "JPY", 1050, 150.5, 1580},
+		{"into three decimals", "USD", "KWD", 10000, 0.3075, 30750},
+		{"out of no minor unit", "JPY", "USD", 1000, 0.0067, 670},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := mustLookup(t, tt.from), mustLookup(t, tt.to)
+			if got := from.Convert(tt.minor, to, tt.rate); got != tt.want {
+				t.Errorf("Convert(%d) = %d, want %d", tt.minor, got, tt.want)
+			}
+		})
+	}
+}
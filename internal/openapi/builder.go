@@ -0,0 +1,243 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	bearerScheme  = "bearerAuth"
+	jsonMediaType = "application/json"
+)
+
+// Route documents one route registered on the router. Request and Response
+// are zero values of the body types; Response is wrapped in the success
+// envelope unless ContentType says the route serves something else.
+type Route struct {
+	Tag     string
+	Summary string
+	// Auth marks routes behind RequireAuth
+	Auth     bool
+	Request  any
+	Response any
+	// Status of a successful response; 200 when zero
+	Status int
+	// Redirect marks browser routes that answer with a 302
+	Redirect bool
+	// ContentType of a body served as is rather than in the envelope
+	ContentType string
+}
+
+// Builder assembles the document from the routes of a gin engine
+type Builder struct {
+	info     Info
+	schemas  *schemas
+	envelope struct{ success, failure *Schema }
+}
+
+// NewBuilder describes the API with info. success and failure are the
+// envelope types every JSON response is wrapped in.
+func NewBuilder(info Info, success, failure any) *Builder {
+	b := &Builder{info: info, schemas: newSchemas()}
+	b.envelope.success = b.schemas.of(reflect.TypeOf(success))
+	b.envelope.failure = b.schemas.of(reflect.TypeOf(failure))
+	return b
+}
+
+// Enum lists the allowed values of the type of sample, for string types
+// whose constants the validate tags do not spell out
+func (b *Builder) Enum(sample any, values ...any) {
+	b.schemas.enums[reflect.TypeOf(sample)] = values
+}
+
+// Tag describes a custom validate tag, such as one registered with
+// validator.RegisterValidation
+func (b *Builder) Tag(name string, apply func(*Schema)) {
+	b.schemas.tags[name] = apply
+}
+
+// Build describes every route in routes. Routes missing from docs are still
+// listed, without summaries or body schemas.
+func (b *Builder) Build(routes gin.RoutesInfo, docs map[string]Route) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    b.info,
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas: b.schemas.components,
+			SecuritySchemes: map[string]*SecurityScheme{
+				bearerScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	tags := map[string]bool{}
+	operationIDs := map[string]bool{}
+
+	for _, route := range routes {
+		if route.Method == http.MethodHead {
+			continue
+		}
+
+		path, params := pathTemplate(route.Path)
+		meta := docs[route.Method+" "+route.Path]
+		if meta.Tag == "" {
+			meta.Tag = defaultTag(route.Path)
+		}
+
+		op := b.operation(meta, params)
+		op.OperationID = operationID(route, operationIDs)
+		operationIDs[op.OperationID] = true
+		tags[meta.Tag] = true
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = op
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	return doc
+}
+
+func (b *Builder) operation(meta Route, params []string) *Operation {
+	op := &Operation{
+		Tags:      []string{meta.Tag},
+		Summary:   meta.Summary,
+		Responses: make(map[string]*Response),
+	}
+
+	for _, name := range params {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	if meta.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{jsonMediaType: {Schema: b.schemas.of(reflect.TypeOf(meta.Request))}},
+		}
+		op.Responses["400"] = b.failure("Invalid request body")
+	}
+	if meta.Auth {
+		op.Security = []SecurityRequirement{{bearerScheme: {}}}
+		op.Responses["401"] = b.failure("Missing or invalid access token")
+	}
+	if len(params) > 0 {
+		op.Responses["404"] = b.failure("Resource not found")
+	}
+	op.Responses["429"] = b.failure("Rate limit exceeded")
+	op.Responses["default"] = b.failure("Unexpected error")
+
+	status := meta.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	switch {
+	case meta.Redirect:
+		op.Responses["302"] = &Response{
+			Description: "Redirect",
+			Headers:     map[string]*Header{"Location": {Schema: &Schema{Type: "string", Format: "uri"}}},
+		}
+	case meta.ContentType != "":
+		res := &Response{Description: http.StatusText(status)}
+		if meta.Response != nil {
+			res.Content = map[string]MediaType{meta.ContentType: {Schema: b.schemas.of(reflect.TypeOf(meta.Response))}}
+		} else {
+			res.Content = map[string]MediaType{meta.ContentType: {}}
+		}
+		op.Responses[strconv.Itoa(status)] = res
+	default:
+		op.Responses[strconv.Itoa(status)] = b.success(meta.Response, http.StatusText(status))
+	}
+
+	return op
+}
+
+// success wraps data in the success envelope
+func (b *Builder) success(data any, description string) *Response {
+	schema := b.envelope.success
+	if data != nil {
+		schema = &Schema{AllOf: []*Schema{
+			b.envelope.success,
+			{
+				Type:       "object",
+				Properties: map[string]*Schema{"data": b.schemas.of(reflect.TypeOf(data))},
+			},
+		}}
+	}
+	return &Response{
+		Description: description,
+		Content:     map[string]MediaType{jsonMediaType: {Schema: schema}},
+	}
+}
+
+func (b *Builder) failure(description string) *Response {
+	return &Response{
+		Description: description,
+		Content:     map[string]MediaType{jsonMediaType: {Schema: b.envelope.failure}},
+	}
+}
+
+// pathTemplate converts gin parameters (:id, *path) to OpenAPI ones ({id})
+func pathTemplate(path string) (string, []string) {
+	var params []string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// defaultTag groups an undocumented route by its first segment after the
+// API version, e.g. "companies"
+func defaultTag(path string) string {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(path, "/api/v1"), "/"), "/")
+	if segments[0] == "" {
+		return "default"
+	}
+	return segments[0]
+}
+
+// operationID names an operation after its handler method, e.g. "createTeam"
+// for TeamController.CreateTeam, falling back to the method and path when
+// that is ambiguous
+func operationID(route gin.RouteInfo, taken map[string]bool) string {
+	name := route.Handler[strings.LastIndex(route.Handler, ".")+1:]
+	name = strings.TrimSuffix(name, "-fm")
+
+	if strings.HasPrefix(name, "func") || taken[lowerFirst(name)] {
+		name = strings.ToLower(route.Method)
+		for _, segment := range strings.FieldsFunc(route.Path, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			name += strings.ToUpper(segment[:1]) + segment[1:]
+		}
+	}
+	return lowerFirst(name)
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,79 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+)
+
+// uiContentSecurityPolicy lets Swagger UI load its own assets and inline
+// styles, replacing the API's policy that forbids everything
+const uiContentSecurityPolicy = "default-src 'self'; img-src 'self' data:; style-src 'self' 'unsafe-inline'; frame-ancestors 'none'"
+
+const uiIndex = `<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+    <link rel="stylesheet" type="text/css" href="./swagger-ui.css" />
+    <link rel="icon" type="image/png" href="./favicon-32x32.png" sizes="32x32" />
+  </head>
+  <body>
+    <div id="swagger-ui"></div>
+    <script src="./swagger-ui-bundle.js" charset="UTF-8"></script>
+    <script src="./swagger-ui-standalone-preset.js" charset="UTF-8"></script>
+    <script src="./swagger-initializer.js" charset="UTF-8"></script>
+  </body>
+</html>
+`
+
+const uiInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: %s,
+    dom_id: '#swagger-ui',
+    deepLinking: true,
+    persistAuthorization: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// Handler serves doc as JSON. The document is encoded once.
+func Handler(doc *Document) gin.HandlerFunc {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		// Every type in Document is plain data
+		panic(fmt.Sprintf("openapi: failed to encode document: %v", err))
+	}
+
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+	}
+}
+
+// UI serves Swagger UI for the document at specURL. Register it on a
+// wildcard route named filepath, e.g. /docs/*filepath.
+func UI(title, specURL string) gin.HandlerFunc {
+	index := fmt.Sprintf(uiIndex, html.EscapeString(title))
+	quotedURL, _ := json.Marshal(specURL)
+	initializer := fmt.Sprintf(uiInitializer, quotedURL)
+
+	return func(c *gin.Context) {
+		c.Header("Content-Security-Policy", uiContentSecurityPolicy)
+
+		switch file := c.Param("filepath"); file {
+		case "", "/", "/index.html":
+			c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(index))
+		case "/swagger-initializer.js":
+			c.Data(http.StatusOK, "application/javascript; charset=utf-8", []byte(initializer))
+		default:
+			c.FileFromFS(file, swaggerFiles.HTTP)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package openapi
+
+import (
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemas turns Go types into component schemas. Named structs become
+// components referenced by name; everything else is described inline.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+	enums      map[reflect.Type][]any
+	tags       map[string]func(*Schema)
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+		enums:      make(map[reflect.Type][]any),
+		tags:       make(map[string]func(*Schema)),
+	}
+}
+
+func (s *schemas) of(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if values, ok := s.enums[t]; ok {
+		return &Schema{Type: primitiveType(t.Kind()), Enum: values}
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer"}
+	case reflect.Int32, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return s.component(t)
+	}
+
+	// Interfaces and anything else accept any value
+	return &Schema{}
+}
+
+// component registers a named struct and returns a reference to it. The
+// entry is reserved before the fields are described so that recursive types
+// terminate.
+func (s *schemas) component(t reflect.Type) *Schema {
+	if name, ok := s.names[t]; ok {
+		return Ref(name)
+	}
+
+	name := t.Name()
+	if _, taken := s.components[name]; taken {
+		// Prefix the package, e.g. ModelsTeam next to a dto Team
+		pkg := path.Base(t.PkgPath())
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+
+	schema := &Schema{}
+	s.names[t] = name
+	s.components[name] = schema
+	*schema = *s.object(t)
+
+	return Ref(name)
+}
+
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	s.addFields(schema, t)
+	return schema
+}
+
+func (s *schemas) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// Embedded structs without a JSON name are flattened by encoding/json
+		if field.Anonymous && name == "" && indirect(field.Type).Kind() == reflect.Struct {
+			s.addFields(schema, indirect(field.Type))
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := s.of(field.Type)
+		if field.Type.Kind() == reflect.Pointer && property.Ref == "" {
+			property.Nullable = true
+		}
+
+		rules := field.Tag.Get("validate")
+		if rules == "" {
+			rules = field.Tag.Get("binding")
+		}
+		if s.applyRules(property, rules) {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = property
+	}
+}
+
+// applyRules describes validator rules on schema and reports whether the
+// field is required. Rules after "dive" apply to the elements of a slice or
+// map. Rules without an OpenAPI equivalent, such as gtfield, are skipped.
+func (s *schemas) applyRules(schema *Schema, rules string) bool {
+	required, dived := false, false
+	target := schema
+	if schema.Ref != "" {
+		// A reference cannot carry constraints of its own
+		target = &Schema{}
+	}
+	for _, rule := range strings.Split(rules, ",") {
+		tag, param, _ := strings.Cut(rule, "=")
+
+		switch tag {
+		case "dive":
+			switch {
+			case target.Items != nil:
+				target = target.Items
+			case target.AdditionalProperties != nil:
+				target = target.AdditionalProperties
+			default:
+				return required
+			}
+			dived = true
+			continue
+		case "required":
+			required = required || !dived
+		case "email":
+			target.Format = "email"
+		case "url", "uri", "http_url":
+			target.Format = "uri"
+		case "uuid", "uuid4":
+			target.Format = "uuid"
+		case "fqdn", "hostname":
+			target.Format = "hostname"
+		case "ip":
+			target.Format = "ip"
+		case "oneof":
+			target.Enum = enumValues(target.Type, param)
+		case "len":
+			setBound(target, param, true, false)
+			setBound(target, param, false, false)
+		case "min", "gte":
+			setBound(target, param, true, false)
+		case "max", "lte":
+			setBound(target, param, false, false)
+		case "gt":
+			setBound(target, param, true, true)
+		case "lt":
+			setBound(target, param, false, true)
+		default:
+			if apply, ok := s.tags[tag]; ok {
+				apply(target)
+			}
+		}
+	}
+	return required
+}
+
+// setBound maps a size rule onto the keyword matching the schema type:
+// lengths for strings, item counts for arrays and values for numbers
+func setBound(schema *Schema, param string, lower, exclusive bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	switch schema.Type {
+	case "string", "array":
+		size := int(n)
+		if exclusive {
+			if lower {
+				size++
+			} else {
+				size--
+			}
+		}
+		switch {
+		case schema.Type == "string" && lower:
+			schema.MinLength = &size
+		case schema.Type == "string":
+			schema.MaxLength = &size
+		case lower:
+			schema.MinItems = &size
+		default:
+			schema.MaxItems = &size
+		}
+	case "integer", "number":
+		if lower {
+			schema.Minimum = &n
+			schema.ExclusiveMinimum = exclusive
+		} else {
+			schema.Maximum = &n
+			schema.ExclusiveMaximum = exclusive
+		}
+	}
+}
+
+func enumValues(schemaType, param string) []any {
+	var values []any
+	for _, value := range strings.Fields(param) {
+		if schemaType == "integer" || schemaType == "number" {
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				values = append(values, n)
+				continue
+			}
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+func primitiveType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return "string"
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
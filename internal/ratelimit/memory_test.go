@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBucketRefill(t *testing.T) {
+	limit := Limit{Rate: 2, Burst: 5}
+
+	tests := []struct {
+		name    string
+		tokens  float64
+		elapsed time.Duration
+		want    float64
+	}{
+		{"no time passed", 1, 0, 1},
+		{"partial token", 0, 250 * time.Millisecond, 0.5},
+		{"several tokens", 1, 1500 * time.Millisecond, 4},
+		{"capped at burst", 3, 10 * time.Second, 5},
+		{"full stays full", 5, time.Second, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			b := &bucket{tokens: tt.tokens, updated: now.Add(-tt.elapsed), limit: limit}
+
+			b.refill(now)
+
+			if b.tokens != tt.want {
+				t.Errorf("tokens = %v, want %v", b.tokens, tt.want)
+			}
+			if !b.updated.Equal(now) {
+				t.Errorf("updated = %v, want %v", b.updated, now)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	limit := Limit{Rate: 1, Burst: 2}
+
+	tests := []struct {
+		name string
+		// tokens and idle set up an existing bucket; a negative tokens
+		// leaves the key without one
+		tokens        float64
+		idle          time.Duration
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		{"new key starts full", -1, 0, true, 1, 0},
+		{"last token", 1, 0, true, 0, 0},
+		{"empty bucket", 0, 0, false, 0, time.Second},
+		{"half a token", 0.5, 0, false, 0, 500 * time.Millisecond},
+		{"refilled while idle", 0, 1500 * time.Millisecond, true, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryStore()
+			if tt.tokens >= 0 {
+				s.buckets["key"] = &bucket{tokens: tt.tokens, updated: time.Now().Add(-tt.idle), limit: limit}
+			}
+
+			got, err := s.Take(context.Background(), "key", limit)
+			if err != nil {
+				t.Fatalf("Take() error = %v", err)
+			}
+
+			if got.Allowed != tt.wantAllowed {
+				t.Errorf("Allowed = %v, want %v", got.Allowed, tt.wantAllowed)
+			}
+			if got.Remaining != tt.wantRemaining {
+				t.Errorf("Remaining = %d, want %d", got.Remaining, tt.wantRemaining)
+			}
+			// Time passes between setting up the bucket and taking from it
+			if diff := tt.wantRetry - got.RetryAfter; diff < 0 || diff > 50*time.Millisecond {
+				t.Errorf("RetryAfter = %v, want about %v", got.RetryAfter, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	limit := Limit{Rate: 1, Burst: 10}
+
+	tests := []struct {
+		name      string
+		sinceLast time.Duration
+		tokens    float64
+		idle      time.Duration
+		wantKept  bool
+	}{
+		{"full bucket is dropped", sweepInterval, 10, 0, false},
+		{"refilled while idle is dropped", sweepInterval, 0, 10 * time.Second, false},
+		{"partly refilled is kept", sweepInterval, 0, 5 * time.Second, true},
+		{"not due for a sweep", sweepInterval - time.Second, 10, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			s := NewMemoryStore()
+			s.lastSweep = now.Add(-tt.sinceLast)
+			s.buckets["key"] = &bucket{tokens: tt.tokens, updated: now.Add(-tt.idle), limit: limit}
+
+			s.sweep(now)
+
+			if _, kept := s.buckets["key"]; kept != tt.wantKept {
+				t.Errorf("bucket kept = %v, want %v", kept, tt.wantKept)
+			}
+		})
+	}
+}
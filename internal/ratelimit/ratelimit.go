@@ -0,0 +1,48 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit is a token bucket that holds up to Burst tokens and refills at Rate
+// tokens per second. Every request spends one token.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Every returns the limit allowing requests per period on average, with
+// bursts of up to burst requests
+func Every(requests int, period time.Duration, burst int) Limit {
+	return Limit{
+		Rate:  float64(requests) / period.Seconds(),
+		Burst: burst,
+	}
+}
+
+// Result is the state of a bucket after a request tried to spend a token
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is how long until the next token is available; it is zero
+	// when the request was allowed
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again
+	ResetAfter time.Duration
+}
+
+// Store keeps the token buckets. Implementations must be safe for concurrent
+// use; a store backed by a shared service enforces one budget across every
+// replica of the API.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// durationFor returns how long it takes to refill tokens at rate
+func durationFor(tokens, rate float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / rate * float64(time.Second))
+}
@@ -1,25 +1,39 @@
 package repositories
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"log"
 
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
 	"github.com/Slightly-Techie/st-okr-api/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
-	ErrCompanyNotFound    = errors.New("no company exists with the provided credentials")
-	ErrCompanyDBOperation = errors.New("database operation failed")
+	ErrCompanyNotFound    = apperror.NotFound("no company exists with the provided credentials")
+	ErrCompanyDBOperation = apperror.Database("database operation failed")
+
+	ErrExchangeRateNotFound = apperror.NotFound("the company keeps no such exchange rate")
+	ErrCycleNotFound        = apperror.NotFound("the company has no such cycle")
 )
 
 type CompanyRepository interface {
 	GetDB() *gorm.DB
-	GetByIdentifier(identifier, id string) (*models.Company, error)
-	Create(company *models.Company) (*models.Company, error)
-	Update(company *models.Company) (*models.Company, error)
-	Delete(id string) error
+	GetByIdentifier(ctx context.Context, identifier, id string) (*models.Company, error)
+	List(ctx context.Context) ([]models.Company, error)
+	Create(ctx context.Context, company *models.Company) (*models.Company, error)
+	Update(ctx context.Context, company *models.Company) (*models.Company, error)
+	UpdateStatusThresholds(ctx context.Context, id string, thresholds models.StatusThresholds) error
+	UpdateReportingCurrency(ctx context.Context, id, currency string) error
+	ListExchangeRates(ctx context.Context, companyID string) ([]models.ExchangeRate, error)
+	UpsertExchangeRate(ctx context.Context, rate *models.ExchangeRate) error
+	DeleteExchangeRate(ctx context.Context, companyID, from, to string) error
+	ListCycles(ctx context.Context, companyID string) ([]models.Cycle, error)
+	CreateCycle(ctx context.Context, cycle *models.Cycle) error
+	DeleteCycle(ctx context.Context, companyID, id string) error
+	Delete(ctx context.Context, id string) error
 }
 
 type companyRepository struct {
@@ -36,50 +50,173 @@ func (r *companyRepository) GetDB() *gorm.DB {
 	return r.db
 }
 
-func (r *companyRepository) GetByIdentifier(identifier, id string) (*models.Company, error) {
+func (r *companyRepository) GetByIdentifier(ctx context.Context, identifier, id string) (*models.Company, error) {
 	var company models.Company
 
-	res := r.db.Where(identifier, id).First(&company)
+	res := r.db.WithContext(ctx).Where(identifier, id).First(&company)
 	if res.Error != nil {
 		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
 			return nil, ErrCompanyNotFound
 		}
-		log.Printf("error getting company by identifier: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrCompanyDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error getting company by identifier", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrCompanyDBOperation)
 	}
 	return &company, nil
 }
 
-func (r *companyRepository) Create(company *models.Company) (*models.Company, error) {
-	res := r.db.Create(company)
+// List returns every company, oldest first
+func (r *companyRepository) List(ctx context.Context) ([]models.Company, error) {
+	var companies []models.Company
 
+	res := r.db.WithContext(ctx).Order("created_at").Find(&companies)
 	if res.Error != nil {
-		log.Printf("error creating company: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrCompanyDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error listing companies", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrCompanyDBOperation)
+	}
+	return companies, nil
+}
+
+func (r *companyRepository) Create(ctx context.Context, company *models.Company) (*models.Company, error) {
+	res := r.db.WithContext(ctx).Create(company)
+
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error creating company", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrCompanyDBOperation)
 	}
 
 	return company, nil
 }
 
-func (r *companyRepository) Update(company *models.Company) (*models.Company, error) {
-	res := r.db.Save(company)
+func (r *companyRepository) Update(ctx context.Context, company *models.Company) (*models.Company, error) {
+	res := r.db.WithContext(ctx).Save(company)
 
 	if res.Error != nil {
-		log.Printf("error updating company: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrCompanyDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error updating company", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrCompanyDBOperation)
 	}
 
 	return company, nil
 }
 
-func (r *companyRepository) Delete(id string) error {
-	res := r.db.Where("id = ?", id).Delete(&models.Company{})
+func (r *companyRepository) UpdateStatusThresholds(ctx context.Context, id string, thresholds models.StatusThresholds) error {
+	res := r.db.WithContext(ctx).
+		Model(&models.Company{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"at_risk_threshold": thresholds.AtRisk,
+			"behind_threshold":  thresholds.Behind,
+		})
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error updating status thresholds", "error", res.Error.Error())
+		return dbError(res.Error, ErrCompanyDBOperation)
+	}
+	if res.RowsAffected == 0 {
+		return ErrCompanyNotFound
+	}
+	return nil
+}
+
+func (r *companyRepository) UpdateReportingCurrency(ctx context.Context, id, currency string) error {
+	res := r.db.WithContext(ctx).
+		Model(&models.Company{}).
+		Where("id = ?", id).
+		Update("reporting_currency", currency)
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error updating reporting currency", "error", res.Error.Error())
+		return dbError(res.Error, ErrCompanyDBOperation)
+	}
+	if res.RowsAffected == 0 {
+		return ErrCompanyNotFound
+	}
+	return nil
+}
+
+func (r *companyRepository) ListExchangeRates(ctx context.Context, companyID string) ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+	err := r.db.WithContext(ctx).
+		Where("company_id = ?", companyID).
+		Order("from_currency, to_currency").
+		Find(&rates).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("Error listing exchange rates", "error", err.Error())
+		return nil, dbError(err, ErrCompanyDBOperation)
+	}
+	return rates, nil
+}
+
+// UpsertExchangeRate saves a rate, replacing the company's previous rate
+// between the same currencies
+func (r *companyRepository) UpsertExchangeRate(ctx context.Context, rate *models.ExchangeRate) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "company_id"}, {Name: "from_currency"}, {Name: "to_currency"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+		}).
+		Create(rate).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("Error saving exchange rate", "error", err.Error())
+		return dbError(err, ErrCompanyDBOperation)
+	}
+	return nil
+}
+
+func (r *companyRepository) DeleteExchangeRate(ctx context.Context, companyID, from, to string) error {
+	res := r.db.WithContext(ctx).
+		Where("company_id = ? AND from_currency = ? AND to_currency = ?", companyID, from, to).
+		Delete(&models.ExchangeRate{})
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error deleting exchange rate", "error", res.Error.Error())
+		return dbError(res.Error, ErrCompanyDBOperation)
+	}
+	if res.RowsAffected == 0 {
+		return ErrExchangeRateNotFound
+	}
+	return nil
+}
+
+func (r *companyRepository) ListCycles(ctx context.Context, companyID string) ([]models.Cycle, error) {
+	var cycles []models.Cycle
+	err := r.db.WithContext(ctx).
+		Where("company_id = ?", companyID).
+		Order("start_date, name").
+		Find(&cycles).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("Error listing cycles", "error", err.Error())
+		return nil, dbError(err, ErrCompanyDBOperation)
+	}
+	return cycles, nil
+}
+
+func (r *companyRepository) CreateCycle(ctx context.Context, cycle *models.Cycle) error {
+	if err := r.db.WithContext(ctx).Create(cycle).Error; err != nil {
+		logger.FromContext(ctx).Error("Error creating cycle", "error", err.Error())
+		return dbError(err, ErrCompanyDBOperation)
+	}
+	return nil
+}
+
+func (r *companyRepository) DeleteCycle(ctx context.Context, companyID, id string) error {
+	res := r.db.WithContext(ctx).
+		Where("company_id = ? AND id = ?", companyID, id).
+		Delete(&models.Cycle{})
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error deleting cycle", "error", res.Error.Error())
+		return dbError(res.Error, ErrCompanyDBOperation)
+	}
+	if res.RowsAffected == 0 {
+		return ErrCycleNotFound
+	}
+	return nil
+}
+
+func (r *companyRepository) Delete(ctx context.Context, id string) error {
+	res := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Company{})
 	if res.Error != nil {
-		log.Printf("error deleting company: %v", res.Error)
-		return fmt.Errorf("%w: %v", ErrCompanyDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error deleting company", "error", res.Error.Error())
+		return dbError(res.Error, ErrCompanyDBOperation)
 	}
 	if res.RowsAffected == 0 {
-		log.Printf("no company found with id: %s", id)
+		logger.FromContext(ctx).Warn("No company found", "id", id)
 		return ErrCompanyNotFound
 	}
 	return nil
@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"gorm.io/gorm"
+)
+
+var ErrDashboardDBOperation = apperror.Database("database operation failed")
+
+// StatusCount is the number of objectives or key results in one status
+type StatusCount struct {
+	Status string
+	Count  int64
+}
+
+// TeamProgress is the average progress of a team's objectives. TeamID is
+// empty for objectives that belong to no team.
+type TeamProgress struct {
+	TeamID          string
+	TeamName        string
+	Objectives      int64
+	AverageProgress float64
+}
+
+// KeyResultFreshness counts the open key results by when they were last
+// updated
+type KeyResultFreshness struct {
+	UpdatedWithinWeek  int64
+	UpdatedWithinMonth int64
+	Stale              int64
+	LastUpdatedAt      *time.Time
+}
+
+// ProgressBucket counts the key results whose progress falls in bucket:
+// 0 for under 25%, 1 for 25 to 50%, 2 for 50 to 75%, 3 for 75 to 100% and 4
+// for done
+type ProgressBucket struct {
+	Bucket int
+	Count  int64
+}
+
+// AtRiskKeyResult is a key result that is at risk or behind, with the title
+// of its objective
+type AtRiskKeyResult struct {
+	models.KeyResult
+	ObjectiveTitle string
+}
+
+// DashboardRepository computes company-wide figures with aggregate queries
+// rather than by loading objectives and key results
+type DashboardRepository interface {
+	GetDB() *gorm.DB
+	ObjectivesByStatus(ctx context.Context, filter ObjectiveFilter) ([]StatusCount, error)
+	KeyResultsByStatus(ctx context.Context, filter ObjectiveFilter) ([]StatusCount, error)
+	ProgressByTeam(ctx context.Context, filter ObjectiveFilter) ([]TeamProgress, error)
+	AtRiskKeyResults(ctx context.Context, filter ObjectiveFilter, limit int) ([]AtRiskKeyResult, error)
+	KeyResultFreshness(ctx context.Context, filter ObjectiveFilter, now time.Time) (*KeyResultFreshness, error)
+	ProgressDistribution(ctx context.Context, filter ObjectiveFilter) ([]ProgressBucket, error)
+}
+
+type dashboardRepository struct {
+	db *gorm.DB
+}
+
+func NewDashboardRepository(db *gorm.DB) DashboardRepository {
+	return &dashboardRepository{db: db}
+}
+
+func (r *dashboardRepository) GetDB() *gorm.DB {
+	return r.db
+}
+
+// keyResults selects the key results of the filtered objectives
+func (r *dashboardRepository) keyResults(ctx context.Context, filter ObjectiveFilter) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Table("key_results").
+		Joins("JOIN objectives ON objectives.id = key_results.objective_id").
+		Scopes(filter.scope("objectives"))
+}
+
+func (r *dashboardRepository) ObjectivesByStatus(ctx context.Context, filter ObjectiveFilter) ([]StatusCount, error) {
+	var counts []StatusCount
+	err := r.db.WithContext(ctx).
+		Table("objectives").
+		Scopes(filter.scope("objectives")).
+		Select("objectives.status AS status, COUNT(*) AS count").
+		Group("objectives.status").
+		Scan(&counts).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("Error counting objectives by status", "error", err.Error())
+		return nil, dbError(err, ErrDashboardDBOperation)
+	}
+	return counts, nil
+}
+
+func (r *dashboardRepository) KeyResultsByStatus(ctx context.Context, filter ObjectiveFilter) ([]StatusCount, error) {
+	var counts []StatusCount
+	err := r.keyResults(ctx, filter).
+		Select("key_results.status AS status, COUNT(*) AS count").
+		Group("key_results.status").
+		Scan(&counts).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("Error counting key results by status", "error", err.Error())
+		return nil, dbError(err, ErrDashboardDBOperation)
+	}
+	return counts, nil
+}
+
+func (r *dashboardRepository) ProgressByTeam(ctx context.Context, filter ObjectiveFilter) ([]TeamProgress, error) {
+	var teams []TeamProgress
+	err := r.db.WithContext(ctx).
+		Table("objectives").
+		Joins("LEFT JOIN teams ON teams.id = objectives.team_id").
+		Scopes(filter.scope("objectives")).
+		Select("COALESCE(objectives.team_id, '') AS team_id, COALESCE(teams.name, '') AS team_name, " +
+			"COUNT(*) AS objectives, AVG(objectives.progress) AS average_progress").
+		Group("objectives.team_id, teams.name").
+		Order("average_progress").
+		Scan(&teams).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("Error averaging progress by team", "error", err.Error())
+		return nil, dbError(err, ErrDashboardDBOperation)
+	}
+	return teams, nil
+}
+
+// AtRiskKeyResults returns the open key results that are at risk or behind,
+// least progressed and soonest due first
+func (r *dashboardRepository) AtRiskKeyResults(ctx context.Context, filter ObjectiveFilter, limit int) ([]AtRiskKeyResult, error) {
+	var keyResults []AtRiskKeyResult
+	err := r.keyResults(ctx, filter).
+		Select("key_results.*, objectives.title AS objective_title").
+		Where("key_results.status IN ?", []models.KeyResultProgressStatus{models.StatusRisk, models.StatusBehind}).
+		Order("key_results.progress, key_results.due_date").
+		Limit(limit).
+		Scan(&keyResults).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("Error listing at-risk key results", "error", err.Error())
+		return nil, dbError(err, ErrDashboardDBOperation)
+	}
+	return keyResults, nil
+}
+
+// KeyResultFreshness ignores completed key results, which need no more
+// check-ins
+func (r *dashboardRepository) KeyResultFreshness(ctx context.Context, filter ObjectiveFilter, now time.Time) (*KeyResultFreshness, error) {
+	week, month := now.AddDate(0, 0, -7), now.AddDate(0, -1, 0)
+
+	var row struct {
+		UpdatedWithinWeek  int64
+		UpdatedWithinMonth int64
+		Stale              int64
+		LastUpdatedAt      *string
+	}
+	err := r.keyResults(ctx, filter).
+		Select("COALESCE(SUM(CASE WHEN key_results.updated_at >= ? THEN 1 ELSE 0 END), 0) AS updated_within_week, "+
+			"COALESCE(SUM(CASE WHEN key_results.updated_at < ? AND key_results.updated_at >= ? THEN 1 ELSE 0 END), 0) AS updated_within_month, "+
+			"COALESCE(SUM(CASE WHEN key_results.updated_at < ? THEN 1 ELSE 0 END), 0) AS stale, "+
+			"MAX(key_results.updated_at) AS last_updated_at", week, week, month, month).
+		Where("key_results.status <> ?", models.StatusCompleted).
+		Scan(&row).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("Error measuring key result freshness", "error", err.Error())
+		return nil, dbError(err, ErrDashboardDBOperation)
+	}
+
+	freshness := &KeyResultFreshness{
+		UpdatedWithinWeek:  row.UpdatedWithinWeek,
+		UpdatedWithinMonth: row.UpdatedWithinMonth,
+		Stale:              row.Stale,
+	}
+	if row.LastUpdatedAt != nil {
+		if t, ok := parseDBTime(*row.LastUpdatedAt); ok {
+			freshness.LastUpdatedAt = &t
+		}
+	}
+	return freshness, nil
+}
+
+func (r *dashboardRepository) ProgressDistribution(ctx context.Context, filter ObjectiveFilter) ([]ProgressBucket, error) {
+	var buckets []ProgressBucket
+	err := r.keyResults(ctx, filter).
+		Select("CASE WHEN key_results.progress >= 100 THEN 4 " +
+			"WHEN key_results.progress >= 75 THEN 3 " +
+			"WHEN key_results.progress >= 50 THEN 2 " +
+			"WHEN key_results.progress >= 25 THEN 1 " +
+			"ELSE 0 END AS bucket, COUNT(*) AS count").
+		Group("bucket").
+		Scan(&buckets).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("Error computing progress distribution", "error", err.Error())
+		return nil, dbError(err, ErrDashboardDBOperation)
+	}
+	return buckets, nil
+}
+
+// parseDBTime reads an aggregated timestamp. MAX over a timestamp column
+// comes back as text from some drivers.
+func parseDBTime(value string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05.999999999"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
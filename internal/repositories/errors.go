@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"gorm.io/gorm"
+)
+
+var ErrDuplicate = apperror.Conflict("a record with the same details already exists")
+
+// dbError wraps a failed query. Unique constraint violations become
+// ErrDuplicate; everything else is wrapped in the repository's operation error.
+func dbError(err, opErr error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return fmt.Errorf("%w: %v", ErrDuplicate, err)
+	}
+	return fmt.Errorf("%w: %v", opErr, err)
+}
@@ -1,26 +1,43 @@
 package repositories
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"log"
+	"time"
 
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
 	"github.com/Slightly-Techie/st-okr-api/internal/models"
 	"gorm.io/gorm"
 )
 
 var (
-	ErrKeyResultNotFound    = errors.New("no key result exists with the provided details")
-	ErrKeyResultDBOperation = errors.New("database operation failed")
+	ErrKeyResultNotFound    = apperror.NotFound("no key result exists with the provided details")
+	ErrKeyResultDBOperation = apperror.Database("database operation failed")
 )
 
 type KeyResultRepository interface {
 	GetDB() *gorm.DB
-	Create(keyResult *models.KeyResult) (*models.KeyResult, error)
-	GetByIdentifier(identifier, id string) (*models.KeyResult, error)
-	ListByIdentifier(identifier, id string) ([]models.KeyResult, error)
-	Update(keyResult *models.KeyResult) (*models.KeyResult, error)
-	Delete(id string) error
+	Create(ctx context.Context, keyResult *models.KeyResult) (*models.KeyResult, error)
+	GetByIdentifier(ctx context.Context, identifier, id string) (*models.KeyResult, error)
+	ListByIdentifier(ctx context.Context, identifier, id string) ([]models.KeyResult, error)
+	Update(ctx context.Context, keyResult *models.KeyResult) (*models.KeyResult, error)
+	Delete(ctx context.Context, id string) error
+	StatusThresholds(ctx context.Context, objectiveID string) (models.StatusThresholds, error)
+	EachOpen(ctx context.Context, now time.Time, batchSize int, fn func([]OpenKeyResult) error) error
+	UpdateForecast(ctx context.Context, keyResult *models.KeyResult) error
+}
+
+// OpenKeyResult is a key result whose status can still change with time,
+// with the status thresholds of its company
+type OpenKeyResult struct {
+	models.KeyResult
+	AtRiskThreshold float64
+	BehindThreshold float64
+}
+
+func (k OpenKeyResult) StatusThresholds() models.StatusThresholds {
+	return models.StatusThresholds{AtRisk: k.AtRiskThreshold, Behind: k.BehindThreshold}
 }
 
 type keyResultRepository struct {
@@ -35,19 +52,19 @@ func (k *keyResultRepository) GetDB() *gorm.DB {
 	return k.db
 }
 
-func (k *keyResultRepository) Create(keyResult *models.KeyResult) (*models.KeyResult, error) {
-	res := k.db.Create(keyResult)
+func (k *keyResultRepository) Create(ctx context.Context, keyResult *models.KeyResult) (*models.KeyResult, error) {
+	res := k.db.WithContext(ctx).Create(keyResult)
 	if res.Error != nil {
-		log.Printf("error creating team: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrKeyResultDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error creating key result", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrKeyResultDBOperation)
 	}
 	return keyResult, nil
 }
 
-func (k *keyResultRepository) GetByIdentifier(identifier, id string) (*models.KeyResult, error) {
+func (k *keyResultRepository) GetByIdentifier(ctx context.Context, identifier, id string) (*models.KeyResult, error) {
 	var keyResult models.KeyResult
 
-	res := k.db.Where(identifier, id).First(&keyResult)
+	res := k.db.WithContext(ctx).Where(identifier, id).First(&keyResult)
 	if res.Error != nil {
 		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
 			return nil, ErrKeyResultNotFound
@@ -57,10 +74,10 @@ func (k *keyResultRepository) GetByIdentifier(identifier, id string) (*models.Ke
 	return &keyResult, nil
 }
 
-func (k *keyResultRepository) ListByIdentifier(identifier, id string) ([]models.KeyResult, error) {
+func (k *keyResultRepository) ListByIdentifier(ctx context.Context, identifier, id string) ([]models.KeyResult, error) {
 	var keyResult []models.KeyResult
 
-	res := k.db.Where(identifier, id).Find(&keyResult)
+	res := k.db.WithContext(ctx).Where(identifier, id).Find(&keyResult)
 	if res.Error != nil {
 		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
 			return nil, ErrKeyResultNotFound
@@ -70,26 +87,100 @@ func (k *keyResultRepository) ListByIdentifier(identifier, id string) ([]models.
 	return keyResult, nil
 }
 
-func (k *keyResultRepository) Update(keyResult *models.KeyResult) (*models.KeyResult, error) {
-	res := k.db.Save(keyResult)
+func (k *keyResultRepository) Update(ctx context.Context, keyResult *models.KeyResult) (*models.KeyResult, error) {
+	res := k.db.WithContext(ctx).Save(keyResult)
 	if res.Error != nil {
-		log.Printf("error updating Key Result: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrKeyResultDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error updating Key Result", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrKeyResultDBOperation)
 	}
 
 	return keyResult, nil
 }
 
-func (k *keyResultRepository) Delete(id string) error {
-	res := k.db.Where("id = ?", id).Delete(&models.KeyResult{})
+func (k *keyResultRepository) Delete(ctx context.Context, id string) error {
+	res := k.db.WithContext(ctx).Where("id = ?", id).Delete(&models.KeyResult{})
 	if res.Error != nil {
-		log.Printf("error deleting Key Result: %v", res.Error)
-		return fmt.Errorf("%w: %v", ErrKeyResultDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error deleting Key Result", "error", res.Error.Error())
+		return dbError(res.Error, ErrKeyResultDBOperation)
 	}
 	if res.RowsAffected == 0 {
-		log.Printf("no Key Result found with id: %s", id)
+		logger.FromContext(ctx).Warn("No Key Result found", "id", id)
 		return ErrKeyResultNotFound
 	}
 
 	return nil
 }
+
+// StatusThresholds returns the thresholds of the company the objective
+// belongs to
+func (k *keyResultRepository) StatusThresholds(ctx context.Context, objectiveID string) (models.StatusThresholds, error) {
+	var thresholds []models.StatusThresholds
+	err := k.db.WithContext(ctx).
+		Table("objectives").
+		Joins("JOIN companies ON companies.id = objectives.company_id").
+		Where("objectives.id = ?", objectiveID).
+		Select("companies.at_risk_threshold AS at_risk, companies.behind_threshold AS behind").
+		Scan(&thresholds).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("Error getting status thresholds", "error", err.Error())
+		return models.StatusThresholds{}, dbError(err, ErrKeyResultDBOperation)
+	}
+	if len(thresholds) == 0 {
+		return models.StatusThresholds{}, ErrObjectiveNotFound
+	}
+	return thresholds[0], nil
+}
+
+// EachOpen calls fn with the key results that have started and are not
+// complete, batchSize at a time. Overdue key results are left out once they
+// are behind, since nothing but a check-in can change them.
+func (k *keyResultRepository) EachOpen(ctx context.Context, now time.Time, batchSize int, fn func([]OpenKeyResult) error) error {
+	query := k.db.WithContext(ctx).
+		Table("key_results").
+		Joins("JOIN objectives ON objectives.id = key_results.objective_id").
+		Joins("JOIN companies ON companies.id = objectives.company_id").
+		Select("key_results.*, companies.at_risk_threshold, companies.behind_threshold").
+		Where("key_results.status <> ? AND key_results.start_date <= ?", models.StatusCompleted, now).
+		Where("key_results.due_date >= ? OR key_results.status <> ?", now, models.StatusBehind)
+
+	lastID := ""
+	for {
+		var keyResults []OpenKeyResult
+		err := query.Session(&gorm.Session{}).
+			Where("key_results.id > ?", lastID).
+			Order("key_results.id").
+			Limit(batchSize).
+			Scan(&keyResults).Error
+		if err != nil {
+			logger.FromContext(ctx).Error("Error listing open key results", "error", err.Error())
+			return dbError(err, ErrKeyResultDBOperation)
+		}
+		if len(keyResults) == 0 {
+			return nil
+		}
+		if err := fn(keyResults); err != nil {
+			return err
+		}
+		if len(keyResults) < batchSize {
+			return nil
+		}
+		lastID = keyResults[len(keyResults)-1].ID
+	}
+}
+
+// UpdateForecast saves the status and projected completion only. updated_at
+// is left alone because it marks the last check-in.
+func (k *keyResultRepository) UpdateForecast(ctx context.Context, keyResult *models.KeyResult) error {
+	err := k.db.WithContext(ctx).
+		Model(&models.KeyResult{}).
+		Where("id = ?", keyResult.ID).
+		UpdateColumns(map[string]any{
+			"status":                  keyResult.Status,
+			"projected_completion_at": keyResult.ProjectedCompletionAt,
+		}).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("Error updating key result forecast", "error", err.Error())
+		return dbError(err, ErrKeyResultDBOperation)
+	}
+	return nil
+}
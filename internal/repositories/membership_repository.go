@@ -1,25 +1,26 @@
 package repositories
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"log"
 
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
 	"github.com/Slightly-Techie/st-okr-api/internal/models"
 	"gorm.io/gorm"
 )
 
 var (
-	ErrMembershipNotFound    = errors.New("no membership exists with the provided credentials")
-	ErrMembershipDBOperation = errors.New("database operation failed")
+	ErrMembershipNotFound    = apperror.NotFound("no membership exists with the provided credentials")
+	ErrMembershipDBOperation = apperror.Database("database operation failed")
 )
 
 type MembershipRepository interface {
 	GetDB() *gorm.DB
-	GetByIdentifier(identifier, id string) (*models.Membership, error)
-	Create(membership *models.Membership) (*models.Membership, error)
-	Update(membership *models.Membership) (*models.Membership, error)
-	Delete(id string) error
+	GetByIdentifier(ctx context.Context, identifier, id string) (*models.Membership, error)
+	Create(ctx context.Context, membership *models.Membership) (*models.Membership, error)
+	Update(ctx context.Context, membership *models.Membership) (*models.Membership, error)
+	Delete(ctx context.Context, id string) error
 }
 
 type membershipRepository struct {
@@ -36,50 +37,50 @@ func (r *membershipRepository) GetDB() *gorm.DB {
 	return r.db
 }
 
-func (r *membershipRepository) GetByIdentifier(identifier, id string) (*models.Membership, error) {
+func (r *membershipRepository) GetByIdentifier(ctx context.Context, identifier, id string) (*models.Membership, error) {
 	var membership models.Membership
 
-	res := r.db.Where(identifier, id).First(&membership)
+	res := r.db.WithContext(ctx).Where(identifier, id).First(&membership)
 	if res.Error != nil {
 		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
 			return nil, ErrMembershipNotFound
 		}
-		log.Printf("error getting membership by identifier: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrMembershipDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error getting membership by identifier", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrMembershipDBOperation)
 	}
 	return &membership, nil
 }
 
-func (r *membershipRepository) Create(membership *models.Membership) (*models.Membership, error) {
-	res := r.db.Create(membership)
+func (r *membershipRepository) Create(ctx context.Context, membership *models.Membership) (*models.Membership, error) {
+	res := r.db.WithContext(ctx).Create(membership)
 
 	if res.Error != nil {
-		log.Printf("error creating membership: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrMembershipDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error creating membership", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrMembershipDBOperation)
 	}
 
 	return membership, nil
 }
 
-func (r *membershipRepository) Update(membership *models.Membership) (*models.Membership, error) {
-	res := r.db.Save(membership)
+func (r *membershipRepository) Update(ctx context.Context, membership *models.Membership) (*models.Membership, error) {
+	res := r.db.WithContext(ctx).Save(membership)
 
 	if res.Error != nil {
-		log.Printf("error updating membership: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrMembershipDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error updating membership", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrMembershipDBOperation)
 	}
 
 	return membership, nil
 }
 
-func (r *membershipRepository) Delete(id string) error {
-	res := r.db.Where("id = ?", id).Delete(&models.Membership{})
+func (r *membershipRepository) Delete(ctx context.Context, id string) error {
+	res := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Membership{})
 	if res.Error != nil {
-		log.Printf("error deleting membership: %v", res.Error)
-		return fmt.Errorf("%w: %v", ErrMembershipDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error deleting membership", "error", res.Error.Error())
+		return dbError(res.Error, ErrMembershipDBOperation)
 	}
 	if res.RowsAffected == 0 {
-		log.Printf("no membership found with id: %s", id)
+		logger.FromContext(ctx).Warn("No membership found", "id", id)
 		return ErrMembershipNotFound
 	}
 	return nil
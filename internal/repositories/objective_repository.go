@@ -1,30 +1,65 @@
 package repositories
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"log"
+	"time"
 
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
 	"github.com/Slightly-Techie/st-okr-api/internal/models"
 	"gorm.io/gorm"
 )
 
 var (
-	ErrObjectiveNotFound    = errors.New("no objective exists with the provided details")
-	ErrObjectiveDBOperation = errors.New("database operation failed")
+	ErrObjectiveNotFound    = apperror.NotFound("no objective exists with the provided details")
+	ErrObjectiveDBOperation = apperror.Database("database operation failed")
 )
 
 type ObjectiveRepository interface {
 	GetDB() *gorm.DB
-	Create(objective *models.Objective) (*models.Objective, error)
-	GetByIdentifier(identifier, id string) (*models.Objective, error)
-	GetWithKeyResults(id string) (*models.Objective, error)
-	ListByIdentifier(identifier, id string) ([]models.Objective, error)
-	ListByCompany(companyID string) ([]models.Objective, error)
-	ListByTeam(teamID string) ([]models.Objective, error)
-	ListByOwner(ownerID string) ([]models.Objective, error)
-	Update(objective *models.Objective) (*models.Objective, error)
-	Delete(id string) error
+	Create(ctx context.Context, objective *models.Objective) (*models.Objective, error)
+	GetByIdentifier(ctx context.Context, identifier, id string) (*models.Objective, error)
+	GetWithKeyResults(ctx context.Context, id string) (*models.Objective, error)
+	ListByIdentifier(ctx context.Context, identifier, id string) ([]models.Objective, error)
+	ListByCompany(ctx context.Context, companyID string) ([]models.Objective, error)
+	ListByTeam(ctx context.Context, teamID string) ([]models.Objective, error)
+	ListByOwner(ctx context.Context, ownerID string) ([]models.Objective, error)
+	EachByCompany(ctx context.Context, filter ObjectiveFilter, batchSize int, fn func([]models.Objective) error) error
+	Update(ctx context.Context, objective *models.Objective) (*models.Objective, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// ObjectiveFilter narrows down a company's objectives. Zero fields match
+// everything.
+type ObjectiveFilter struct {
+	CompanyID string
+	TeamID    string
+	Statuses  []models.ObjectiveStatus
+	// From and To keep the objectives whose period overlaps [From, To)
+	From time.Time
+	To   time.Time
+}
+
+// scope applies the filter to the objectives table, or to its alias table
+// in a join
+func (f ObjectiveFilter) scope(table string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where(table+".company_id = ?", f.CompanyID)
+		if f.TeamID != "" {
+			db = db.Where(table+".team_id = ?", f.TeamID)
+		}
+		if len(f.Statuses) > 0 {
+			db = db.Where(table+".status IN ?", f.Statuses)
+		}
+		if !f.From.IsZero() {
+			db = db.Where(table+".end_date >= ?", f.From)
+		}
+		if !f.To.IsZero() {
+			db = db.Where(table+".start_date < ?", f.To)
+		}
+		return db
+	}
 }
 
 type objectiveRepository struct {
@@ -39,111 +74,144 @@ func (r *objectiveRepository) GetDB() *gorm.DB {
 	return r.db
 }
 
-func (r *objectiveRepository) Create(objective *models.Objective) (*models.Objective, error) {
-	res := r.db.Create(objective)
+func (r *objectiveRepository) Create(ctx context.Context, objective *models.Objective) (*models.Objective, error) {
+	res := r.db.WithContext(ctx).Create(objective)
 	if res.Error != nil {
-		log.Printf("error creating objective: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrObjectiveDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error creating objective", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrObjectiveDBOperation)
 	}
 	return objective, nil
 }
 
-func (r *objectiveRepository) GetByIdentifier(identifier, id string) (*models.Objective, error) {
+func (r *objectiveRepository) GetByIdentifier(ctx context.Context, identifier, id string) (*models.Objective, error) {
 	var objective models.Objective
 
-	res := r.db.Where(identifier, id).First(&objective)
+	res := r.db.WithContext(ctx).Where(identifier, id).First(&objective)
 	if res.Error != nil {
 		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
 			return nil, ErrObjectiveNotFound
 		}
-		log.Printf("error getting objective by identifier: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrObjectiveDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error getting objective by identifier", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrObjectiveDBOperation)
 	}
 
 	return &objective, nil
 }
 
-func (r *objectiveRepository) GetWithKeyResults(id string) (*models.Objective, error) {
+func (r *objectiveRepository) GetWithKeyResults(ctx context.Context, id string) (*models.Objective, error) {
 	var objective models.Objective
 
-	res := r.db.Preload("KeyResults").Where("id = ?", id).First(&objective)
+	res := r.db.WithContext(ctx).Preload("KeyResults").Where("id = ?", id).First(&objective)
 	if res.Error != nil {
 		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
 			return nil, ErrObjectiveNotFound
 		}
-		log.Printf("error getting objective with key results: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrObjectiveDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error getting objective with key results", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrObjectiveDBOperation)
 	}
 
 	return &objective, nil
 }
 
-func (r *objectiveRepository) ListByIdentifier(identifier, id string) ([]models.Objective, error) {
+func (r *objectiveRepository) ListByIdentifier(ctx context.Context, identifier, id string) ([]models.Objective, error) {
 	var objectives []models.Objective
 
-	res := r.db.Where(identifier, id).Find(&objectives)
+	res := r.db.WithContext(ctx).Where(identifier, id).Find(&objectives)
 	if res.Error != nil {
-		log.Printf("error listing objectives by identifier: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrObjectiveDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error listing objectives by identifier", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrObjectiveDBOperation)
 	}
 
 	return objectives, nil
 }
 
-func (r *objectiveRepository) ListByCompany(companyID string) ([]models.Objective, error) {
+func (r *objectiveRepository) ListByCompany(ctx context.Context, companyID string) ([]models.Objective, error) {
 	var objectives []models.Objective
 
-	res := r.db.Where("company_id = ?", companyID).Find(&objectives)
+	res := r.db.WithContext(ctx).Where("company_id = ?", companyID).Find(&objectives)
 	if res.Error != nil {
-		log.Printf("error listing objectives by company: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrObjectiveDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error listing objectives by company", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrObjectiveDBOperation)
 	}
 
 	return objectives, nil
 }
 
-func (r *objectiveRepository) ListByTeam(teamID string) ([]models.Objective, error) {
+func (r *objectiveRepository) ListByTeam(ctx context.Context, teamID string) ([]models.Objective, error) {
 	var objectives []models.Objective
 
-	res := r.db.Where("team_id = ?", teamID).Find(&objectives)
+	res := r.db.WithContext(ctx).Where("team_id = ?", teamID).Find(&objectives)
 	if res.Error != nil {
-		log.Printf("error listing objectives by team: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrObjectiveDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error listing objectives by team", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrObjectiveDBOperation)
 	}
 
 	return objectives, nil
 }
 
-func (r *objectiveRepository) ListByOwner(ownerID string) ([]models.Objective, error) {
+func (r *objectiveRepository) ListByOwner(ctx context.Context, ownerID string) ([]models.Objective, error) {
 	var objectives []models.Objective
 
-	res := r.db.Where("owner_id = ?", ownerID).Find(&objectives)
+	res := r.db.WithContext(ctx).Where("owner_id = ?", ownerID).Find(&objectives)
 	if res.Error != nil {
-		log.Printf("error listing objectives by owner: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrObjectiveDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error listing objectives by owner", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrObjectiveDBOperation)
 	}
 
 	return objectives, nil
 }
 
-func (r *objectiveRepository) Update(objective *models.Objective) (*models.Objective, error) {
-	res := r.db.Save(objective)
+// EachByCompany calls fn with the objectives matching filter, with their key
+// results, batchSize at a time in start date order. Only one batch is held in
+// memory at once, so whole companies can be exported.
+func (r *objectiveRepository) EachByCompany(ctx context.Context, filter ObjectiveFilter, batchSize int, fn func([]models.Objective) error) error {
+	query := r.db.WithContext(ctx).Scopes(filter.scope("objectives"))
+
+	// Keyset pagination on (start_date, id) keeps later batches as cheap as
+	// the first
+	var last *models.Objective
+	for {
+		batch := query.Session(&gorm.Session{}).Preload("KeyResults").Order("start_date, id").Limit(batchSize)
+		if last != nil {
+			batch = batch.Where("start_date > ? OR (start_date = ? AND id > ?)", last.StartDate, last.StartDate, last.ID)
+		}
+
+		var objectives []models.Objective
+		if err := batch.Find(&objectives).Error; err != nil {
+			logger.FromContext(ctx).Error("Error listing objectives by company", "error", err.Error())
+			return dbError(err, ErrObjectiveDBOperation)
+		}
+		if len(objectives) == 0 {
+			return nil
+		}
+		if err := fn(objectives); err != nil {
+			return err
+		}
+		if len(objectives) < batchSize {
+			return nil
+		}
+		last = &objectives[len(objectives)-1]
+	}
+}
+
+func (r *objectiveRepository) Update(ctx context.Context, objective *models.Objective) (*models.Objective, error) {
+	res := r.db.WithContext(ctx).Save(objective)
 	if res.Error != nil {
-		log.Printf("error updating objective: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrObjectiveDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error updating objective", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrObjectiveDBOperation)
 	}
 
 	return objective, nil
 }
 
-func (r *objectiveRepository) Delete(id string) error {
-	res := r.db.Where("id = ?", id).Delete(&models.Objective{})
+func (r *objectiveRepository) Delete(ctx context.Context, id string) error {
+	res := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Objective{})
 	if res.Error != nil {
-		log.Printf("error deleting objective: %v", res.Error)
-		return fmt.Errorf("%w: %v", ErrObjectiveDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error deleting objective", "error", res.Error.Error())
+		return dbError(res.Error, ErrObjectiveDBOperation)
 	}
 	if res.RowsAffected == 0 {
-		log.Printf("no objective found with id: %s", id)
+		logger.FromContext(ctx).Warn("No objective found", "id", id)
 		return ErrObjectiveNotFound
 	}
 
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrSnapshotDBOperation = apperror.Database("database operation failed")
+
+// snapshotBatchSize bounds the objectives or key results read, and the
+// snapshots written, per statement
+const snapshotBatchSize = 500
+
+type ProgressSnapshotRepository interface {
+	GetDB() *gorm.DB
+	Upsert(ctx context.Context, snapshots []models.ProgressSnapshot) error
+	RecordActive(ctx context.Context, now time.Time) (int64, error)
+	ListBySubject(ctx context.Context, subjectType models.SnapshotSubject, subjectID string, from, to time.Time) ([]models.ProgressSnapshot, error)
+	ListBySubjects(ctx context.Context, subjectType models.SnapshotSubject, subjectIDs []string, from, to time.Time) ([]models.ProgressSnapshot, error)
+}
+
+type progressSnapshotRepository struct {
+	db *gorm.DB
+}
+
+func NewProgressSnapshotRepository(db *gorm.DB) ProgressSnapshotRepository {
+	return &progressSnapshotRepository{db: db}
+}
+
+func (r *progressSnapshotRepository) GetDB() *gorm.DB {
+	return r.db
+}
+
+// Upsert writes snapshots, replacing any taken earlier the same day
+func (r *progressSnapshotRepository) Upsert(ctx context.Context, snapshots []models.ProgressSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "subject_type"}, {Name: "subject_id"}, {Name: "day"}},
+			DoUpdates: clause.AssignmentColumns([]string{"progress", "value", "updated_at"}),
+		}).
+		CreateInBatches(snapshots, snapshotBatchSize).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("Error saving progress snapshots", "error", err.Error())
+		return dbError(err, ErrSnapshotDBOperation)
+	}
+	return nil
+}
+
+// RecordActive snapshots every objective and key result whose period covers
+// now and returns how many snapshots were written. Finished ones keep the
+// series they had when they ended.
+func (r *progressSnapshotRepository) RecordActive(ctx context.Context, now time.Time) (int64, error) {
+	day := models.SnapshotDay(now)
+	var written int64
+
+	var objectives []models.Objective
+	err := r.db.WithContext(ctx).
+		Select("id", "progress").
+		Where("start_date <= ? AND end_date >= ?", now, day).
+		FindInBatches(&objectives, snapshotBatchSize, func(tx *gorm.DB, batch int) error {
+			snapshots := make([]models.ProgressSnapshot, len(objectives))
+			for i, objective := range objectives {
+				snapshots[i] = models.ProgressSnapshot{
+					SubjectType: models.SnapshotSubjectObjective,
+					SubjectID:   objective.ID,
+					Day:         day,
+					Progress:    objective.Progress,
+				}
+			}
+			written += int64(len(snapshots))
+			return r.Upsert(ctx, snapshots)
+		}).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("Error snapshotting objectives", "error", err.Error())
+		return written, dbError(err, ErrSnapshotDBOperation)
+	}
+
+	var keyResults []models.KeyResult
+	err = r.db.WithContext(ctx).
+		Select("id", "progress", "current_value").
+		Where("start_date <= ? AND due_date >= ?", now, day).
+		FindInBatches(&keyResults, snapshotBatchSize, func(tx *gorm.DB, batch int) error {
+			snapshots := make([]models.ProgressSnapshot, len(keyResults))
+			for i, kr := range keyResults {
+				value := kr.CurrentValue
+				snapshots[i] = models.ProgressSnapshot{
+					SubjectType: models.SnapshotSubjectKeyResult,
+					SubjectID:   kr.ID,
+					Day:         day,
+					Progress:    kr.Progress,
+					Value:       &value,
+				}
+			}
+			written += int64(len(snapshots))
+			return r.Upsert(ctx, snapshots)
+		}).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("Error snapshotting key results", "error", err.Error())
+		return written, dbError(err, ErrSnapshotDBOperation)
+	}
+
+	return written, nil
+}
+
+// ListBySubject returns the snapshots of one objective or key result taken
+// between from and to inclusive, oldest first
+func (r *progressSnapshotRepository) ListBySubject(ctx context.Context, subjectType models.SnapshotSubject, subjectID string, from, to time.Time) ([]models.ProgressSnapshot, error) {
+	var snapshots []models.ProgressSnapshot
+	err := r.db.WithContext(ctx).
+		Where("subject_type = ? AND subject_id = ?", subjectType, subjectID).
+		Where("day >= ? AND day <= ?", models.SnapshotDay(from), models.SnapshotDay(to)).
+		Order("day").
+		Find(&snapshots).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("Error listing progress snapshots", "error", err.Error())
+		return nil, dbError(err, ErrSnapshotDBOperation)
+	}
+	return snapshots, nil
+}
+
+// ListBySubjects is ListBySubject for many objectives or key results at once
+func (r *progressSnapshotRepository) ListBySubjects(ctx context.Context, subjectType models.SnapshotSubject, subjectIDs []string, from, to time.Time) ([]models.ProgressSnapshot, error) {
+	var snapshots []models.ProgressSnapshot
+	if len(subjectIDs) == 0 {
+		return snapshots, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("subject_type = ? AND subject_id IN ?", subjectType, subjectIDs).
+		Where("day >= ? AND day <= ?", models.SnapshotDay(from), models.SnapshotDay(to)).
+		Order("subject_id, day").
+		Find(&snapshots).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("Error listing progress snapshots", "error", err.Error())
+		return nil, dbError(err, ErrSnapshotDBOperation)
+	}
+	return snapshots, nil
+}
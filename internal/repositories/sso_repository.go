@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSSOConfigNotFound     = apperror.NotFound("no SSO configuration exists for the company")
+	ErrCompanyDomainNotFound = apperror.NotFound("no domain exists with the provided details")
+	ErrSSODBOperation        = apperror.Database("database operation failed")
+)
+
+type SSORepository interface {
+	GetDB() *gorm.DB
+	GetConfig(ctx context.Context, companyID string) (*models.CompanySSOConfig, error)
+	SaveConfig(ctx context.Context, cfg *models.CompanySSOConfig) (*models.CompanySSOConfig, error)
+	DeleteConfig(ctx context.Context, companyID string) error
+
+	CreateDomain(ctx context.Context, domain *models.CompanyDomain) (*models.CompanyDomain, error)
+	GetDomain(ctx context.Context, companyID, id string) (*models.CompanyDomain, error)
+	GetVerifiedDomain(ctx context.Context, domain string) (*models.CompanyDomain, error)
+	ListDomains(ctx context.Context, companyID string) ([]models.CompanyDomain, error)
+	UpdateDomain(ctx context.Context, domain *models.CompanyDomain) (*models.CompanyDomain, error)
+	DeleteDomain(ctx context.Context, companyID, id string) error
+}
+
+type ssoRepository struct {
+	db *gorm.DB
+}
+
+func NewSSORepository(db *gorm.DB) SSORepository {
+	return &ssoRepository{db: db}
+}
+
+func (r *ssoRepository) GetDB() *gorm.DB {
+	return r.db
+}
+
+func (r *ssoRepository) GetConfig(ctx context.Context, companyID string) (*models.CompanySSOConfig, error) {
+	var cfg models.CompanySSOConfig
+
+	res := r.db.WithContext(ctx).Where("company_id = ?", companyID).First(&cfg)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrSSOConfigNotFound
+		}
+		logger.FromContext(ctx).Error("Error getting SSO config", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrSSODBOperation)
+	}
+	return &cfg, nil
+}
+
+func (r *ssoRepository) SaveConfig(ctx context.Context, cfg *models.CompanySSOConfig) (*models.CompanySSOConfig, error) {
+	res := r.db.WithContext(ctx).Save(cfg)
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error saving SSO config", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrSSODBOperation)
+	}
+	return cfg, nil
+}
+
+func (r *ssoRepository) DeleteConfig(ctx context.Context, companyID string) error {
+	res := r.db.WithContext(ctx).Where("company_id = ?", companyID).Delete(&models.CompanySSOConfig{})
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error deleting SSO config", "error", res.Error.Error())
+		return dbError(res.Error, ErrSSODBOperation)
+	}
+	if res.RowsAffected == 0 {
+		return ErrSSOConfigNotFound
+	}
+	return nil
+}
+
+func (r *ssoRepository) CreateDomain(ctx context.Context, domain *models.CompanyDomain) (*models.CompanyDomain, error) {
+	res := r.db.WithContext(ctx).Create(domain)
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error creating company domain", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrSSODBOperation)
+	}
+	return domain, nil
+}
+
+func (r *ssoRepository) GetDomain(ctx context.Context, companyID, id string) (*models.CompanyDomain, error) {
+	var domain models.CompanyDomain
+
+	res := r.db.WithContext(ctx).Where("company_id = ? AND id = ?", companyID, id).First(&domain)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrCompanyDomainNotFound
+		}
+		logger.FromContext(ctx).Error("Error getting company domain", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrSSODBOperation)
+	}
+	return &domain, nil
+}
+
+func (r *ssoRepository) GetVerifiedDomain(ctx context.Context, domain string) (*models.CompanyDomain, error) {
+	var companyDomain models.CompanyDomain
+
+	res := r.db.WithContext(ctx).Where("domain = ? AND verified_at IS NOT NULL", domain).First(&companyDomain)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrCompanyDomainNotFound
+		}
+		logger.FromContext(ctx).Error("Error getting verified domain", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrSSODBOperation)
+	}
+	return &companyDomain, nil
+}
+
+func (r *ssoRepository) ListDomains(ctx context.Context, companyID string) ([]models.CompanyDomain, error) {
+	var domains []models.CompanyDomain
+
+	res := r.db.WithContext(ctx).Where("company_id = ?", companyID).Order("domain").Find(&domains)
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error listing company domains", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrSSODBOperation)
+	}
+	return domains, nil
+}
+
+func (r *ssoRepository) UpdateDomain(ctx context.Context, domain *models.CompanyDomain) (*models.CompanyDomain, error) {
+	res := r.db.WithContext(ctx).Save(domain)
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error updating company domain", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrSSODBOperation)
+	}
+	return domain, nil
+}
+
+func (r *ssoRepository) DeleteDomain(ctx context.Context, companyID, id string) error {
+	res := r.db.WithContext(ctx).Where("company_id = ? AND id = ?", companyID, id).Delete(&models.CompanyDomain{})
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error deleting company domain", "error", res.Error.Error())
+		return dbError(res.Error, ErrSSODBOperation)
+	}
+	if res.RowsAffected == 0 {
+		return ErrCompanyDomainNotFound
+	}
+	return nil
+}
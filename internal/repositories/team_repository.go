@@ -1,31 +1,32 @@
 package repositories
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"log"
 
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
 	"github.com/Slightly-Techie/st-okr-api/internal/models"
 	"gorm.io/gorm"
 )
 
 var (
-	ErrTeamNotFound       = errors.New("no Team exists with the provided credentials")
-	ErrTeamDBOperation    = errors.New("database operation failed")
-	ErrTeamMemberNotFound = errors.New("no Team member exists with the provided credentials")
+	ErrTeamNotFound       = apperror.NotFound("no Team exists with the provided credentials")
+	ErrTeamDBOperation    = apperror.Database("database operation failed")
+	ErrTeamMemberNotFound = apperror.NotFound("no Team member exists with the provided credentials")
 )
 
 type TeamRepository interface {
 	GetDB() *gorm.DB
-	GetByIdentifier(identifier, id string) (*models.Team, error)
-	CreateTeam(team *models.Team) (*models.Team, error)
-	UpdateTeam(team *models.Team) (*models.Team, error)
-	DeleteTeam(id string) error
-
-	AddTeamMember(member *models.TeamMember) (*models.TeamMember, error)
-	RemoveTeamMember(id string) error
-	GetTeamMembers(identifier, id string) ([]models.TeamMember, error)
-	IsMember(teamID, userID string) (bool, error)
+	GetByIdentifier(ctx context.Context, identifier, id string) (*models.Team, error)
+	CreateTeam(ctx context.Context, team *models.Team) (*models.Team, error)
+	UpdateTeam(ctx context.Context, team *models.Team) (*models.Team, error)
+	DeleteTeam(ctx context.Context, id string) error
+
+	AddTeamMember(ctx context.Context, member *models.TeamMember) (*models.TeamMember, error)
+	RemoveTeamMember(ctx context.Context, id string) error
+	GetTeamMembers(ctx context.Context, identifier, id string) ([]models.TeamMember, error)
+	IsMember(ctx context.Context, teamID, userID string) (bool, error)
 }
 
 type teamRepository struct {
@@ -42,97 +43,97 @@ func (r *teamRepository) GetDB() *gorm.DB {
 	return r.db
 }
 
-func (r *teamRepository) GetByIdentifier(identifier, id string) (*models.Team, error) {
+func (r *teamRepository) GetByIdentifier(ctx context.Context, identifier, id string) (*models.Team, error) {
 	var team models.Team
 
-	res := r.db.Where(identifier, id).First(&team)
+	res := r.db.WithContext(ctx).Where(identifier, id).First(&team)
 	if res.Error != nil {
 		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
 			return nil, ErrTeamNotFound
 		}
-		log.Printf("error getting team by identifier: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrTeamDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error getting team by identifier", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrTeamDBOperation)
 	}
 	return &team, nil
 }
 
-func (r *teamRepository) CreateTeam(team *models.Team) (*models.Team, error) {
-	res := r.db.Create(team)
+func (r *teamRepository) CreateTeam(ctx context.Context, team *models.Team) (*models.Team, error) {
+	res := r.db.WithContext(ctx).Create(team)
 	if res.Error != nil {
-		log.Printf("error creating team: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrTeamDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error creating team", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrTeamDBOperation)
 	}
 	return team, nil
 }
 
-func (r *teamRepository) UpdateTeam(team *models.Team) (*models.Team, error) {
-	res := r.db.Save(team)
+func (r *teamRepository) UpdateTeam(ctx context.Context, team *models.Team) (*models.Team, error) {
+	res := r.db.WithContext(ctx).Save(team)
 	if res.Error != nil {
-		log.Printf("error updating team: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrTeamDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error updating team", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrTeamDBOperation)
 	}
 	return team, nil
 }
 
-func (r *teamRepository) DeleteTeam(id string) error {
-	res := r.db.Where("id = ?", id).Delete(&models.Team{})
+func (r *teamRepository) DeleteTeam(ctx context.Context, id string) error {
+	res := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Team{})
 	if res.Error != nil {
-		log.Printf("error deleting team: %v", res.Error)
-		return fmt.Errorf("%w: %v", ErrTeamDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error deleting team", "error", res.Error.Error())
+		return dbError(res.Error, ErrTeamDBOperation)
 	}
 	if res.RowsAffected == 0 {
-		log.Printf("no team found with id: %s", id)
+		logger.FromContext(ctx).Warn("No team found", "id", id)
 		return ErrTeamNotFound
 	}
 	return nil
 }
 
-func (r *teamRepository) AddTeamMember(member *models.TeamMember) (*models.TeamMember, error) {
-	res := r.db.Create(member)
+func (r *teamRepository) AddTeamMember(ctx context.Context, member *models.TeamMember) (*models.TeamMember, error) {
+	res := r.db.WithContext(ctx).Create(member)
 	if res.Error != nil {
-		log.Printf("error adding team member: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrTeamDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error adding team member", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrTeamDBOperation)
 	}
 	return member, nil
 }
 
-func (r *teamRepository) GetTeamMembers(identifier, id string) ([]models.TeamMember, error) {
+func (r *teamRepository) GetTeamMembers(ctx context.Context, identifier, id string) ([]models.TeamMember, error) {
 	var members []models.TeamMember
 
-	res := r.db.Where(identifier, id).Find(&members)
+	res := r.db.WithContext(ctx).Where(identifier, id).Find(&members)
 	if res.Error != nil {
 		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
 			return nil, ErrTeamMemberNotFound
 		}
-		log.Printf("error getting team members: %v", res.Error)
-		return nil, fmt.Errorf("%w: %v", ErrTeamMemberNotFound, res.Error)
+		logger.FromContext(ctx).Error("Error getting team members", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrTeamDBOperation)
 	}
 	return members, nil
 }
 
-func (r *teamRepository) RemoveTeamMember(id string) error {
-	res := r.db.Where("id = ?", id).Delete(&models.TeamMember{})
+func (r *teamRepository) RemoveTeamMember(ctx context.Context, id string) error {
+	res := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.TeamMember{})
 	if res.Error != nil {
-		log.Printf("error removing team member: %v", res.Error)
-		return fmt.Errorf("%w: %v", ErrTeamDBOperation, res.Error)
+		logger.FromContext(ctx).Error("Error removing team member", "error", res.Error.Error())
+		return dbError(res.Error, ErrTeamDBOperation)
 	}
 	if res.RowsAffected == 0 {
-		log.Printf("no team member found with id: %s", id)
+		logger.FromContext(ctx).Warn("No team member found", "id", id)
 		return ErrTeamMemberNotFound
 	}
 	return nil
 }
 
-func (r *teamRepository) IsMember(teamID, userID string) (bool, error) {
+func (r *teamRepository) IsMember(ctx context.Context, teamID, userID string) (bool, error) {
 	var member models.TeamMember
 
-	res := r.db.Where("team_id = ? AND user_id = ?", teamID, userID).First(&member)
+	res := r.db.WithContext(ctx).Where("team_id = ? AND user_id = ?", teamID, userID).First(&member)
 	if res.Error != nil {
 		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
 			return false, nil
 		}
-		log.Printf("error checking team membership: %v", res.Error)
-		return false, fmt.Errorf("failed to check team membership: %v", res.Error)
+		logger.FromContext(ctx).Error("Error checking team membership", "error", res.Error.Error())
+		return false, dbError(res.Error, ErrTeamDBOperation)
 	}
 	return true, nil
 }
@@ -1,21 +1,42 @@
 package repositories
 
 import (
+	"context"
 	"errors"
-	"log"
+	"time"
 
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
 	"github.com/Slightly-Techie/st-okr-api/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
 	GetDB() *gorm.DB
-	GetByIdentifier(identifier, id string) (*models.User, error)
-	Create(user *models.User) (*models.User, error)
-	Update(user *models.User) (*models.User, error)
-	Delete(id string) error
+	GetByIdentifier(ctx context.Context, identifier, id string) (*models.User, error)
+	Create(ctx context.Context, user *models.User) (*models.User, error)
+	Update(ctx context.Context, user *models.User) (*models.User, error)
+	Delete(ctx context.Context, id string) error
+
+	GetIdentity(ctx context.Context, provider, providerUserID string) (*models.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *models.UserIdentity) (*models.UserIdentity, error)
+	ListIdentities(ctx context.Context, userID string) ([]models.UserIdentity, error)
+	DeleteIdentity(ctx context.Context, userID, id string) error
+	DeleteUnverifiedIdentities(ctx context.Context, userID string) error
+
+	CreateAuthToken(ctx context.Context, token *models.AuthToken) (*models.AuthToken, error)
+	ConsumeAuthToken(ctx context.Context, purpose models.AuthTokenPurpose, tokenHash string) (*models.AuthToken, error)
+	RevokeAuthTokens(ctx context.Context, userID string, purpose models.AuthTokenPurpose) error
 }
 
+var (
+	ErrUserNotFound         = apperror.NotFound("no user exists with the provided credentials")
+	ErrUserDBOperation      = apperror.Database("database operation failed")
+	ErrUserIdentityNotFound = apperror.NotFound("no identity exists with the provided details")
+	ErrAuthTokenInvalid     = apperror.NotFound("token is invalid, expired or already used")
+)
+
 type userRepository struct {
 	db *gorm.DB
 }
@@ -31,50 +52,153 @@ func (r *userRepository) GetDB() *gorm.DB {
 	return r.db
 }
 
-func (r *userRepository) GetByIdentifier(identifier, id string) (*models.User, error) {
+func (r *userRepository) GetByIdentifier(ctx context.Context, identifier, id string) (*models.User, error) {
 	var user models.User
 
-	res := r.db.Where(identifier, id).First(&user)
+	res := r.db.WithContext(ctx).Where(identifier, id).First(&user)
 	if res.Error != nil {
 		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return nil, errors.New("no user exists with the provided credentials")
+			return nil, ErrUserNotFound
 		}
-		log.Println("error getting user by identifier: ", res.Error)
-		return nil, res.Error
+		logger.FromContext(ctx).Error("Error getting user by identifier", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrUserDBOperation)
 	}
 	return &user, nil
 }
 
-func (r *userRepository) Create(user *models.User) (*models.User, error) {
-	res := r.db.Create(&user)
+func (r *userRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
+	res := r.db.WithContext(ctx).Create(&user)
 
 	if res.Error != nil {
-		log.Println("error creating user: ", res.Error)
-		return nil, res.Error
+		logger.FromContext(ctx).Error("Error creating user", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrUserDBOperation)
 	}
 
 	return user, nil
 }
 
-func (r *userRepository) Update(user *models.User) (*models.User, error) {
-	res := r.db.Save(&user)
+func (r *userRepository) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	res := r.db.WithContext(ctx).Save(&user)
 
 	if res.Error != nil {
-		log.Println("error updating user: ", res.Error)
-		return nil, res.Error
+		logger.FromContext(ctx).Error("Error updating user", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrUserDBOperation)
 	}
 
 	return user, nil
 }
 
-func (r *userRepository) Delete(id string) error {
+func (r *userRepository) Delete(ctx context.Context, id string) error {
 	var user models.User
 
-	res := r.db.Where("id = ?", id).Delete(&user)
+	res := r.db.WithContext(ctx).Where("id = ?", id).Delete(&user)
 	if res.Error != nil {
-		log.Println("error deleting user: ", res.Error)
-		return res.Error
+		logger.FromContext(ctx).Error("Error deleting user", "error", res.Error.Error())
+		return dbError(res.Error, ErrUserDBOperation)
 	}
 
 	return nil
 }
+
+func (r *userRepository) GetIdentity(ctx context.Context, provider, providerUserID string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+
+	res := r.db.WithContext(ctx).Where("provider = ? AND provider_user_id = ?", provider, providerUserID).First(&identity)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrUserIdentityNotFound
+		}
+		logger.FromContext(ctx).Error("Error getting user identity", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrUserDBOperation)
+	}
+	return &identity, nil
+}
+
+func (r *userRepository) CreateIdentity(ctx context.Context, identity *models.UserIdentity) (*models.UserIdentity, error) {
+	res := r.db.WithContext(ctx).Create(identity)
+
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error creating user identity", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrUserDBOperation)
+	}
+
+	return identity, nil
+}
+
+func (r *userRepository) ListIdentities(ctx context.Context, userID string) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+
+	res := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&identities)
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error listing user identities", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrUserDBOperation)
+	}
+	return identities, nil
+}
+
+func (r *userRepository) DeleteIdentity(ctx context.Context, userID, id string) error {
+	res := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&models.UserIdentity{})
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error deleting user identity", "error", res.Error.Error())
+		return dbError(res.Error, ErrUserDBOperation)
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserIdentityNotFound
+	}
+	return nil
+}
+
+// DeleteUnverifiedIdentities removes the identities whose provider did not
+// vouch for the user's email
+func (r *userRepository) DeleteUnverifiedIdentities(ctx context.Context, userID string) error {
+	res := r.db.WithContext(ctx).Where("user_id = ? AND email_verified = ?", userID, false).Delete(&models.UserIdentity{})
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error deleting unverified user identities", "error", res.Error.Error())
+		return dbError(res.Error, ErrUserDBOperation)
+	}
+	return nil
+}
+
+func (r *userRepository) CreateAuthToken(ctx context.Context, token *models.AuthToken) (*models.AuthToken, error) {
+	res := r.db.WithContext(ctx).Create(token)
+
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error creating auth token", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrUserDBOperation)
+	}
+
+	return token, nil
+}
+
+// ConsumeAuthToken marks a valid token as used and returns it. The update is
+// conditional so that a token can only be redeemed once, even under concurrency.
+func (r *userRepository) ConsumeAuthToken(ctx context.Context, purpose models.AuthTokenPurpose, tokenHash string) (*models.AuthToken, error) {
+	var token models.AuthToken
+	now := time.Now()
+
+	res := r.db.WithContext(ctx).Model(&token).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, now).
+		Update("used_at", now)
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error consuming auth token", "error", res.Error.Error())
+		return nil, dbError(res.Error, ErrUserDBOperation)
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrAuthTokenInvalid
+	}
+
+	return &token, nil
+}
+
+// RevokeAuthTokens invalidates all outstanding tokens of a purpose for a user
+func (r *userRepository) RevokeAuthTokens(ctx context.Context, userID string, purpose models.AuthTokenPurpose) error {
+	res := r.db.WithContext(ctx).Model(&models.AuthToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		logger.FromContext(ctx).Error("Error revoking auth tokens", "error", res.Error.Error())
+		return dbError(res.Error, ErrUserDBOperation)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// statuses maps each kind of domain error to its HTTP status and error code
+var statuses = map[apperror.Kind]struct {
+	status int
+	code   ErrorCode
+}{
+	apperror.KindBadRequest:   {http.StatusBadRequest, ErrCodeBadRequest},
+	apperror.KindValidation:   {http.StatusBadRequest, ErrCodeValidationFailed},
+	apperror.KindUnauthorized: {http.StatusUnauthorized, ErrCodeUnauthorized},
+	apperror.KindForbidden:    {http.StatusForbidden, ErrCodeForbidden},
+	apperror.KindNotFound:     {http.StatusNotFound, ErrCodeNotFound},
+	apperror.KindConflict:     {http.StatusConflict, ErrCodeConflict},
+	apperror.KindDatabase:     {http.StatusInternalServerError, ErrCodeDatabaseError},
+	apperror.KindInternal:     {http.StatusInternalServerError, ErrCodeInternalError},
+}
+
+// HandleError writes the error response for err. Domain errors from
+// apperror keep their message; validation errors list the invalid fields in
+// the client's language; anything else is reported as an internal error
+// without exposing its text.
+// Server errors are attached to the context so the request log records them.
+func HandleError(c *gin.Context, err error) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		ValidationError(c, "Invalid request data", validation.Translate(validationErrs, c.GetHeader("Accept-Language")))
+		return
+	}
+
+	if isMalformedBody(err) {
+		BadRequest(c, "Request body is not valid JSON", nil)
+		return
+	}
+
+	appErr, ok := apperror.As(err)
+	if !ok {
+		appErr = apperror.New(apperror.KindInternal, "Internal server error")
+	}
+
+	mapping := statuses[appErr.Kind]
+	if mapping.status >= http.StatusInternalServerError && !attached(c, err) {
+		_ = c.Error(err)
+	}
+	Error(c, mapping.status, mapping.code, appErr.Message, appErr.Details)
+}
+
+func attached(c *gin.Context, err error) bool {
+	last := c.Errors.Last()
+	return last != nil && last.Err == err
+}
+
+func isMalformedBody(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &syntaxErr) ||
+		errors.As(err, &typeErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
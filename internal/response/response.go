@@ -139,6 +139,11 @@ func Conflict(c *gin.Context, message string, details map[string]string) {
 	Error(c, http.StatusConflict, ErrCodeConflict, message, details)
 }
 
+// TooManyRequests sends a 429 too many requests error
+func TooManyRequests(c *gin.Context, message string) {
+	Error(c, http.StatusTooManyRequests, ErrCodeTooManyRequests, message, nil)
+}
+
 // InternalError sends a 500 internal server error
 func InternalError(c *gin.Context, message string) {
 	Error(c, http.StatusInternalServerError, ErrCodeInternalError, message, nil)
@@ -0,0 +1,161 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/controllers"
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"github.com/Slightly-Techie/st-okr-api/internal/openapi"
+	"github.com/Slightly-Techie/st-okr-api/internal/response"
+	auth "github.com/Slightly-Techie/st-okr-api/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+const apiTitle = "ST OKR API"
+
+// Inline request bodies bound by the controllers
+type (
+	createCompanyBody struct {
+		Name string `json:"name"`
+	}
+	membershipRoleBody struct {
+		Role models.RoleType `json:"role" binding:"required,oneof=admin member viewer"`
+	}
+	membershipStatusBody struct {
+		Status models.StatusType `json:"status" binding:"required,oneof=active inactive suspended"`
+	}
+	readinessBody struct {
+		Status       string                                  `json:"status"`
+		Dependencies map[string]controllers.DependencyStatus `json:"dependencies"`
+	}
+)
+
+// routeDocs describes the routes registered in SetupRouter, keyed by method
+// and gin path. Keep it in step with the routes: a route missing here is
+// still published, but without its summary, auth or body schemas.
+var routeDocs = map[string]openapi.Route{
+	"GET /metrics":               {Tag: "Operations", Summary: "Prometheus metrics", ContentType: "text/plain"},
+	"GET /healthz":               {Tag: "Operations", Summary: "Liveness probe", ContentType: "application/json"},
+	"GET /readyz":                {Tag: "Operations", Summary: "Readiness probe with per-dependency status", ContentType: "application/json", Response: readinessBody{}},
+	"GET /.well-known/jwks.json": {Tag: "Operations", Summary: "Token verification keys", ContentType: "application/json", Response: auth.JWKS{}},
+	"GET /api/v1/docs/*filepath": {Tag: "Operations", Summary: "Swagger UI", ContentType: "text/html"},
+
+	"GET /api/v1/auth/:provider":            {Tag: "Auth", Summary: "Start a social login; with ?link_token= it links the provider to the user who asked for the token", Redirect: true},
+	"GET /api/v1/auth/:provider/callback":   {Tag: "Auth", Summary: "Complete a social login", Redirect: true},
+	"GET /api/v1/auth/logout/:provider":     {Tag: "Auth", Summary: "Sign out of a social login", Redirect: true},
+	"POST /api/v1/auth/register":            {Tag: "Auth", Summary: "Create an account with email and password", Request: dto.RegisterRequest{}, Status: http.StatusCreated},
+	"POST /api/v1/auth/login":               {Tag: "Auth", Summary: "Sign in with email and password", Request: dto.LoginRequest{}, Response: dto.AuthResponse{}},
+	"POST /api/v1/auth/verify-email":        {Tag: "Auth", Summary: "Verify an email address", Request: dto.TokenRequest{}},
+	"POST /api/v1/auth/verify-email/resend": {Tag: "Auth", Summary: "Resend the verification email", Request: dto.EmailRequest{}},
+	"POST /api/v1/auth/password/forgot":     {Tag: "Auth", Summary: "Email a password reset link", Request: dto.EmailRequest{}},
+	"POST /api/v1/auth/password/reset":      {Tag: "Auth", Summary: "Set a new password", Request: dto.ResetPasswordRequest{}},
+	"POST /api/v1/auth/magic-link":          {Tag: "Auth", Summary: "Email a sign-in link", Request: dto.EmailRequest{}},
+	"POST /api/v1/auth/magic-link/verify":   {Tag: "Auth", Summary: "Sign in with a magic link", Request: dto.TokenRequest{}, Response: dto.AuthResponse{}},
+	"POST /api/v1/auth/refresh":             {Tag: "Auth", Summary: "Rotate the refresh token", Request: dto.RefreshTokenRequest{}, Response: dto.AuthResponse{}},
+	"POST /api/v1/auth/token":               {Tag: "Auth", Summary: "Exchange a browser login code for tokens", Request: dto.AuthorizationCodeRequest{}, Response: dto.AuthResponse{}},
+	"GET /api/v1/auth/identities/":          {Tag: "Auth", Summary: "List linked sign-in providers", Auth: true, Response: []models.UserIdentity{}},
+	"DELETE /api/v1/auth/identities/:id":    {Tag: "Auth", Summary: "Unlink a sign-in provider", Auth: true},
+	"POST /api/v1/auth/identities/link":     {Tag: "Auth", Summary: "Get a token that links the next social login to the current user", Auth: true, Response: dto.IdentityLinkResponse{}},
+
+	"POST /api/v1/auth/sso/discover":                       {Tag: "SSO", Summary: "Find the single sign-on login for an email", Request: dto.EmailRequest{}, Response: dto.SSODiscoverResponse{}},
+	"GET /api/v1/auth/sso/:company_id/login":               {Tag: "SSO", Summary: "Start a single sign-on login", Redirect: true},
+	"GET /api/v1/auth/sso/:company_id/callback":            {Tag: "SSO", Summary: "Complete an OIDC login", Redirect: true},
+	"POST /api/v1/auth/sso/:company_id/acs":                {Tag: "SSO", Summary: "Complete a SAML login", Redirect: true},
+	"GET /api/v1/auth/sso/:company_id/metadata":            {Tag: "SSO", Summary: "SAML service provider metadata", ContentType: "application/samlmetadata+xml"},
+	"GET /api/v1/companies/:id/sso":                        {Tag: "SSO", Summary: "Get the company's SSO configuration", Auth: true, Response: dto.SSOConfigResponse{}},
+	"PUT /api/v1/companies/:id/sso":                        {Tag: "SSO", Summary: "Configure single sign-on", Auth: true, Request: dto.UpsertSSOConfigRequest{}, Response: dto.SSOConfigResponse{}},
+	"DELETE /api/v1/companies/:id/sso":                     {Tag: "SSO", Summary: "Remove the SSO configuration", Auth: true},
+	"GET /api/v1/companies/:id/domains":                    {Tag: "SSO", Summary: "List the company's email domains", Auth: true, Response: []dto.CompanyDomainResponse{}},
+	"POST /api/v1/companies/:id/domains":                   {Tag: "SSO", Summary: "Add an email domain", Auth: true, Request: dto.CreateCompanyDomainRequest{}, Response: dto.CompanyDomainResponse{}, Status: http.StatusCreated},
+	"POST /api/v1/companies/:id/domains/:domain_id/verify": {Tag: "SSO", Summary: "Verify a domain through its DNS record", Auth: true, Response: dto.CompanyDomainResponse{}},
+	"DELETE /api/v1/companies/:id/domains/:domain_id":      {Tag: "SSO", Summary: "Remove an email domain", Auth: true},
+
+	"POST /api/v1/companies/":                               {Tag: "Companies", Summary: "Create a company", Auth: true, Request: createCompanyBody{}, Response: models.Company{}, Status: http.StatusCreated},
+	"GET /api/v1/companies/:id":                             {Tag: "Companies", Summary: "Get a company", Auth: true, Response: models.Company{}},
+	"PUT /api/v1/companies/:id":                             {Tag: "Companies", Summary: "Update a company", Auth: true, Request: dto.CreateCompanyRequest{}, Response: models.Company{}},
+	"DELETE /api/v1/companies/:id":                          {Tag: "Companies", Summary: "Delete a company", Auth: true},
+	"POST /api/v1/companies/:id/import":                     {Tag: "Companies", Summary: "Import objectives and key results from CSV, JSON or YAML; ?dry_run=true only checks them", Auth: true, Request: dto.ImportDocument{}, Response: dto.ImportReport{}, Status: http.StatusCreated},
+	"GET /api/v1/companies/:id/export":                      {Tag: "Companies", Summary: "Download the company's OKRs; ?format=csv|xlsx|json, filtered by team, cycle (a named cycle, 2025, 2025-H1 or 2025-Q3) and status", Auth: true, ContentType: "text/csv"},
+	"GET /api/v1/companies/:id/dashboard":                   {Tag: "Companies", Summary: "Company-wide OKR analytics, optionally for one team or cycle", Auth: true, Response: dto.DashboardResponse{}},
+	"GET /api/v1/companies/:id/status-thresholds":           {Tag: "Companies", Summary: "Get how far below expected progress key results become at risk or behind", Auth: true, Response: models.StatusThresholds{}},
+	"PUT /api/v1/companies/:id/status-thresholds":           {Tag: "Companies", Summary: "Set how far below expected progress key results become at risk or behind (admins)", Auth: true, Request: dto.StatusThresholdsRequest{}, Response: models.StatusThresholds{}},
+	"GET /api/v1/companies/:id/currency":                    {Tag: "Companies", Summary: "Get the reporting currency and exchange rates currency key results are converted with", Auth: true, Response: dto.CurrencySettingsResponse{}},
+	"PUT /api/v1/companies/:id/currency":                    {Tag: "Companies", Summary: "Set the reporting currency, or clear it to stop converting (admins)", Auth: true, Request: dto.ReportingCurrencyRequest{}, Response: dto.CurrencySettingsResponse{}},
+	"PUT /api/v1/companies/:id/currency/rates":              {Tag: "Companies", Summary: "Add or replace an exchange rate between two currencies (admins)", Auth: true, Request: dto.ExchangeRateRequest{}, Response: models.ExchangeRate{}},
+	"DELETE /api/v1/companies/:id/currency/rates/:from/:to": {Tag: "Companies", Summary: "Remove an exchange rate (admins)", Auth: true},
+	"GET /api/v1/companies/:id/cycles":                      {Tag: "Companies", Summary: "List the company's named cycles", Auth: true, Response: []models.Cycle{}},
+	"POST /api/v1/companies/:id/cycles":                     {Tag: "Companies", Summary: "Name a period such as a fiscal quarter for exports, dashboards and imports to refer to (admins)", Auth: true, Request: dto.CreateCycleRequest{}, Response: models.Cycle{}, Status: http.StatusCreated},
+	"DELETE /api/v1/companies/:id/cycles/:cycle_id":         {Tag: "Companies", Summary: "Delete a cycle; objectives keep their dates (admins)", Auth: true},
+
+	"POST /api/v1/memberships/":                   {Tag: "Memberships", Summary: "Add a user to a company", Auth: true, Request: dto.CreateMembershipRequest{}, Response: models.Membership{}, Status: http.StatusCreated},
+	"GET /api/v1/memberships/:id":                 {Tag: "Memberships", Summary: "Get a membership", Auth: true, Response: models.Membership{}},
+	"PUT /api/v1/memberships/:id":                 {Tag: "Memberships", Summary: "Update a membership", Auth: true, Request: dto.UpdateMembershipRequest{}, Response: models.Membership{}},
+	"DELETE /api/v1/memberships/:id":              {Tag: "Memberships", Summary: "Remove a membership", Auth: true},
+	"GET /api/v1/memberships/company/:company_id": {Tag: "Memberships", Summary: "List a company's members", Auth: true, Response: []models.Membership{}},
+	"PATCH /api/v1/memberships/:id/role":          {Tag: "Memberships", Summary: "Change a member's role", Auth: true, Request: membershipRoleBody{}},
+	"PATCH /api/v1/memberships/:id/status":        {Tag: "Memberships", Summary: "Change a member's status", Auth: true, Request: membershipStatusBody{}},
+
+	"GET /api/v1/teams/:id":            {Tag: "Teams", Summary: "Get a team", Auth: true, Response: models.Team{}},
+	"POST /api/v1/teams/":              {Tag: "Teams", Summary: "Create a team", Auth: true, Request: dto.CreateTeamRequest{}, Response: models.Team{}, Status: http.StatusCreated},
+	"PUT /api/v1/teams/:id":            {Tag: "Teams", Summary: "Update a team", Auth: true, Request: dto.UpdateTeamRequest{}, Response: models.Team{}},
+	"DELETE /api/v1/teams/:id":         {Tag: "Teams", Summary: "Delete a team", Auth: true},
+	"POST /api/v1/teams/:id/members":   {Tag: "Teams", Summary: "Add a team member", Auth: true, Request: dto.TeamMemberRequest{}, Response: models.TeamMember{}, Status: http.StatusCreated},
+	"GET /api/v1/teams/:id/members":    {Tag: "Teams", Summary: "List team members", Auth: true, Response: []models.TeamMember{}},
+	"DELETE /api/v1/teams/members/:id": {Tag: "Teams", Summary: "Remove a team member", Auth: true},
+
+	"POST /api/v1/objectives/":                   {Tag: "Objectives", Summary: "Create an objective", Auth: true, Request: dto.CreateObjectiveRequest{}, Response: models.Objective{}, Status: http.StatusCreated},
+	"GET /api/v1/objectives/:id":                 {Tag: "Objectives", Summary: "Get an objective", Auth: true, Response: models.Objective{}},
+	"GET /api/v1/objectives/:id/timeseries":      {Tag: "Objectives", Summary: "Daily progress of an objective with its ideal trajectory", Auth: true, Response: dto.TimeSeriesResponse{}},
+	"GET /api/v1/objectives/:id/details":         {Tag: "Objectives", Summary: "Get an objective with its key results", Auth: true, Response: dto.ObjectiveResponse{}},
+	"PUT /api/v1/objectives/:id":                 {Tag: "Objectives", Summary: "Update an objective", Auth: true, Request: dto.UpdateObjectiveRequest{}, Response: models.Objective{}},
+	"DELETE /api/v1/objectives/:id":              {Tag: "Objectives", Summary: "Delete an objective", Auth: true},
+	"PATCH /api/v1/objectives/:id/progress":      {Tag: "Objectives", Summary: "Recalculate progress from key results", Auth: true},
+	"GET /api/v1/objectives/company/:company_id": {Tag: "Objectives", Summary: "List a company's objectives", Auth: true, Response: []dto.ObjectiveListResponse{}},
+	"GET /api/v1/objectives/team/:team_id":       {Tag: "Objectives", Summary: "List a team's objectives", Auth: true, Response: []dto.ObjectiveListResponse{}},
+	"GET /api/v1/objectives/owner/:owner_id":     {Tag: "Objectives", Summary: "List an owner's objectives", Auth: true, Response: []dto.ObjectiveListResponse{}},
+
+	"GET /api/v1/key-results/:id/timeseries": {Tag: "Key Results", Summary: "Daily progress and value of a key result with its ideal trajectory", Auth: true, Response: dto.TimeSeriesResponse{}},
+	"GET /api/v1/key-results/:id":            {Tag: "Key Results", Summary: "Get a key result", Response: models.KeyResult{}},
+	"POST /api/v1/key-results/":              {Tag: "Key Results", Summary: "Create a key result", Request: dto.CreateKeyResultRequest{}, Response: models.KeyResult{}, Status: http.StatusCreated},
+	"PATCH /api/v1/key-results/:id":          {Tag: "Key Results", Summary: "Update a key result", Request: dto.UpdateKeyResultRequest{}, Response: models.KeyResult{}},
+	"DELETE /api/v1/key-results/:id":         {Tag: "Key Results", Summary: "Delete a key result"},
+	"GET /api/v1/key-results/objective/:id":  {Tag: "Key Results", Summary: "List an objective's key results", Response: []models.KeyResult{}},
+	"GET /api/v1/key-results/assignee/:id":   {Tag: "Key Results", Summary: "List an assignee's key results", Response: []models.KeyResult{}},
+}
+
+// apiDocument builds the OpenAPI document for the routes of the router
+func apiDocument(routes gin.RoutesInfo) *openapi.Document {
+	b := openapi.NewBuilder(openapi.Info{
+		Title:       apiTitle,
+		Description: "Objectives and key results for companies and their teams. Every JSON response is wrapped in the success or error envelope.",
+		Version:     "1.0.0",
+	}, response.SuccessResponse{}, response.ErrorResponse{})
+
+	b.Enum(response.ErrorCode(""),
+		response.ErrCodeValidationFailed, response.ErrCodeUnauthorized, response.ErrCodeForbidden,
+		response.ErrCodeNotFound, response.ErrCodeConflict, response.ErrCodeBadRequest,
+		response.ErrCodeTooManyRequests, response.ErrCodeInternalError,
+		response.ErrCodeServiceUnavailable, response.ErrCodeDatabaseError)
+	b.Enum(models.RoleType(""), models.RoleAdmin, models.RoleMember, models.RoleViewer)
+	b.Enum(models.StatusType(""), models.StatusActive, models.StatusInactive, models.StatusSuspended)
+	b.Enum(models.ObjectiveType(""), models.ObjectiveTypeCompany, models.ObjectiveTypeTeam)
+	b.Enum(models.ObjectiveStatus(""), models.ObjectiveStatusDraft, models.ObjectiveStatusActive,
+		models.ObjectiveStatusCompleted, models.ObjectiveStatusArchived, models.ObjectiveStatusOnHold)
+	b.Enum(models.MetricType(""), models.MetricTypeNumeric, models.MetricTypePercentage,
+		models.MetricTypeBinary, models.MetrictTypeCurrency, models.MetricTypeRange)
+	b.Enum(models.MetricDirection(""), models.DirectionIncrease, models.DirectionDecrease)
+	b.Enum(models.AssigneeType(""), models.AssigneeTypeIndividual, models.AssigneeTypeTeam)
+	b.Enum(models.KeyResultProgressStatus(""), models.StatusNotStarted, models.StatusInProgress,
+		models.StatusRisk, models.StatusBehind, models.StatusCompleted)
+	b.Enum(models.SSOProtocol(""), models.SSOProtocolOIDC, models.SSOProtocolSAML)
+
+	// Tags registered in validation.KeyResultValidators; metric_type,
+	// metric_direction and assignee_type are covered by the enums of their
+	// field types
+	b.Tag("due_date", func(s *openapi.Schema) {
+		s.Description = "Must be in the future"
+	})
+
+	return b.Build(routes, routeDocs)
+}
@@ -4,51 +4,140 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Slightly-Techie/st-okr-api/config"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
+	"github.com/Slightly-Techie/st-okr-api/internal/metrics"
 	"github.com/Slightly-Techie/st-okr-api/internal/middleware"
+	"github.com/Slightly-Techie/st-okr-api/internal/openapi"
+	"github.com/Slightly-Techie/st-okr-api/internal/ratelimit"
+	"github.com/Slightly-Techie/st-okr-api/internal/response"
 	"github.com/Slightly-Techie/st-okr-api/provider"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func SetupRouter(prov *provider.Provider) *gin.Engine {
-	router := gin.Default()
+	router := gin.New()
+
+	// Only listed proxies may set the client IP through X-Forwarded-For
+	if err := router.SetTrustedProxies(config.ENV.Server.TrustedProxies); err != nil {
+		logger.Error("Invalid trusted proxies, trusting none", "error", err)
+		_ = router.SetTrustedProxies(nil)
+	}
 
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
+		AllowOrigins:     config.ENV.Server.AllowedCORSOrigins(),
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Request-ID"},
-		ExposeHeaders:    []string{"Content-Length", "X-Request-ID"},
+		ExposeHeaders:    []string{"Content-Length", "X-Request-ID", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
+	router.Use(middleware.SecurityHeaders(config.ENV.Server.HSTSMaxAge))
 
+	router.Use(otelgin.Middleware(config.ENV.Tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		// Probes and scrapes would drown out real traffic
+		switch r.URL.Path {
+		case "/metrics", "/healthz", "/readyz":
+			return false
+		}
+		return true
+	})))
 	router.Use(middleware.RequestID())
+	router.Use(middleware.RequestLogger())
+	router.Use(metrics.GinMiddleware())
 	router.Use(ErrorHandlerMiddleware())
 	router.Use(gin.Recovery())
 
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.GET("/healthz", prov.HealthController.Liveness)
+	router.GET("/readyz", prov.HealthController.Readiness)
+	router.GET("/.well-known/jwks.json", prov.UserController.JWKS)
+
+	limits := config.ENV.RateLimit
+	requireAuth := []gin.HandlerFunc{
+		middleware.RequireAuth(prov),
+		rateLimit(prov, "user", limits.User, middleware.UserID),
+	}
+
 	v1 := router.Group("/api/v1")
+	v1.Use(rateLimit(prov, "ip", limits.Default, middleware.ClientIP))
 
 	// Auth routes
 	authRoutes := v1.Group("/auth")
+	authRoutes.Use(rateLimit(prov, "auth", limits.Auth, middleware.ClientIP))
 	{
 		authRoutes.GET("/:provider", prov.UserController.ContinueWithOAuth)
 		authRoutes.GET("/:provider/callback", prov.UserController.GetOAuthCallback)
 		authRoutes.GET("/logout/:provider", prov.UserController.LogoutWithOAuth)
+
+		// Email + password and passwordless sign-in
+		authRoutes.POST("/register", prov.UserController.Register)
+		authRoutes.POST("/login", prov.UserController.Login)
+		authRoutes.POST("/verify-email", prov.UserController.VerifyEmail)
+		authRoutes.POST("/verify-email/resend", prov.UserController.ResendVerification)
+		authRoutes.POST("/password/forgot", prov.UserController.ForgotPassword)
+		authRoutes.POST("/password/reset", prov.UserController.ResetPassword)
+		authRoutes.POST("/magic-link", prov.UserController.RequestMagicLink)
+		authRoutes.POST("/magic-link/verify", prov.UserController.VerifyMagicLink)
+		authRoutes.POST("/refresh", prov.UserController.RefreshToken)
+		authRoutes.POST("/token", prov.UserController.ExchangeCode)
+	}
+
+	// Company single sign-on
+	ssoRoutes := authRoutes.Group("/sso")
+	{
+		ssoRoutes.POST("/discover", prov.SSOController.Discover)
+		ssoRoutes.GET("/:company_id/login", prov.SSOController.Login)
+		ssoRoutes.GET("/:company_id/callback", prov.SSOController.OIDCCallback)
+		ssoRoutes.POST("/:company_id/acs", prov.SSOController.SAMLAssertionConsumer)
+		ssoRoutes.GET("/:company_id/metadata", prov.SSOController.SAMLMetadata)
+	}
+
+	// Linked provider accounts of the signed-in user
+	identityRoutes := authRoutes.Group("/identities")
+	identityRoutes.Use(requireAuth...)
+	{
+		identityRoutes.GET("/", prov.UserController.ListIdentities)
+		identityRoutes.POST("/link", prov.UserController.StartIdentityLink)
+		identityRoutes.DELETE("/:id", prov.UserController.UnlinkIdentity)
 	}
 
 	// Company routes
 	companyRoutes := v1.Group("/companies")
-	companyRoutes.Use(middleware.RequireAuth(prov))
+	companyRoutes.Use(requireAuth...)
 	{
 		companyRoutes.POST("/", prov.CompanyController.CreateCompany)
 		companyRoutes.GET("/:id", prov.CompanyController.GetCompany)
 		companyRoutes.PUT("/:id", prov.CompanyController.UpdateCompany)
 		companyRoutes.DELETE("/:id", prov.CompanyController.DeleteCompany)
+		companyRoutes.POST("/:id/import", prov.ImportController.ImportOKRs)
+		companyRoutes.GET("/:id/export", prov.ExportController.ExportOKRs)
+		companyRoutes.GET("/:id/dashboard", prov.DashboardController.GetDashboard)
+		companyRoutes.GET("/:id/status-thresholds", prov.CompanyController.GetStatusThresholds)
+		companyRoutes.PUT("/:id/status-thresholds", prov.CompanyController.UpdateStatusThresholds)
+		companyRoutes.GET("/:id/currency", prov.CompanyController.GetCurrencySettings)
+		companyRoutes.PUT("/:id/currency", prov.CompanyController.UpdateReportingCurrency)
+		companyRoutes.PUT("/:id/currency/rates", prov.CompanyController.SetExchangeRate)
+		companyRoutes.DELETE("/:id/currency/rates/:from/:to", prov.CompanyController.DeleteExchangeRate)
+		companyRoutes.GET("/:id/cycles", prov.CompanyController.ListCycles)
+		companyRoutes.POST("/:id/cycles", prov.CompanyController.CreateCycle)
+		companyRoutes.DELETE("/:id/cycles/:cycle_id", prov.CompanyController.DeleteCycle)
+
+		// SSO settings and verified email domains
+		companyRoutes.GET("/:id/sso", prov.SSOController.GetConfig)
+		companyRoutes.PUT("/:id/sso", prov.SSOController.UpsertConfig)
+		companyRoutes.DELETE("/:id/sso", prov.SSOController.DeleteConfig)
+		companyRoutes.GET("/:id/domains", prov.SSOController.ListDomains)
+		companyRoutes.POST("/:id/domains", prov.SSOController.AddDomain)
+		companyRoutes.POST("/:id/domains/:domain_id/verify", prov.SSOController.VerifyDomain)
+		companyRoutes.DELETE("/:id/domains/:domain_id", prov.SSOController.RemoveDomain)
 	}
 
 	// Membership routes
 	membershipRoutes := v1.Group("/memberships")
-	membershipRoutes.Use(middleware.RequireAuth(prov))
+	membershipRoutes.Use(requireAuth...)
 	{
 		membershipRoutes.POST("/", prov.MembershipController.CreateMembership)
 		membershipRoutes.GET("/:id", prov.MembershipController.GetMembership)
@@ -63,7 +152,7 @@ func SetupRouter(prov *provider.Provider) *gin.Engine {
 
 	// team routes
 	teamRoutes := v1.Group("/teams")
-	teamRoutes.Use(middleware.RequireAuth(prov))
+	teamRoutes.Use(requireAuth...)
 	{
 		teamRoutes.GET("/:id", prov.TeamController.GetTeam)
 		teamRoutes.POST("/", prov.TeamController.CreateTeam)
@@ -78,7 +167,7 @@ func SetupRouter(prov *provider.Provider) *gin.Engine {
 
 	// Objective routes
 	objectiveRoutes := v1.Group("/objectives")
-	objectiveRoutes.Use(middleware.RequireAuth(prov))
+	objectiveRoutes.Use(requireAuth...)
 	{
 		objectiveRoutes.POST("/", prov.ObjectiveController.CreateObjective)
 		objectiveRoutes.GET("/:id", prov.ObjectiveController.GetObjective)
@@ -86,6 +175,7 @@ func SetupRouter(prov *provider.Provider) *gin.Engine {
 		objectiveRoutes.PUT("/:id", prov.ObjectiveController.UpdateObjective)
 		objectiveRoutes.DELETE("/:id", prov.ObjectiveController.DeleteObjective)
 		objectiveRoutes.PATCH("/:id/progress", prov.ObjectiveController.UpdateObjectiveProgress)
+		objectiveRoutes.GET("/:id/timeseries", prov.TimeSeriesController.ObjectiveTimeSeries)
 
 		// List objectives by different criteria
 		objectiveRoutes.GET("/company/:company_id", prov.ObjectiveController.ListCompanyObjectives)
@@ -101,21 +191,38 @@ func SetupRouter(prov *provider.Provider) *gin.Engine {
 		keyResultRoutes.POST("/", prov.KeyResultController.CreateKeyResult)
 		keyResultRoutes.PATCH("/:id", prov.KeyResultController.UpdateKeyResult)
 		keyResultRoutes.DELETE("/:id", prov.KeyResultController.DeleteKeyResult)
+		// Reading progress history needs to know the company the caller is in
+		keyResultRoutes.GET("/:id/timeseries", append(requireAuth, prov.TimeSeriesController.KeyResultTimeSeries)...)
 
 		keyResultRoutes.GET("/objective/:id", prov.KeyResultController.ListObjKeyResults)
 		keyResultRoutes.GET("/assignee/:id", prov.KeyResultController.ListAssigneeKeyResults)
 	}
 
+	// API documentation, generated from the routes registered above
+	v1.GET("/docs/*filepath", openapi.UI(apiTitle, "/api/v1/openapi.json"))
+	v1.GET("/openapi.json", openapi.Handler(apiDocument(router.Routes())))
+
 	return router
 }
 
+// rateLimit applies budget to the requests keyed by keyFunc, or does nothing
+// while rate limiting is disabled
+func rateLimit(prov *provider.Provider, scope string, budget config.RateLimitBudget, keyFunc func(*gin.Context) string) gin.HandlerFunc {
+	if !config.ENV.RateLimit.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+	limit := ratelimit.Every(budget.Requests, budget.Period, budget.Burst)
+	return middleware.RateLimit(prov.RateLimitStore, scope, limit, keyFunc)
+}
+
 func ErrorHandlerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
-		if len(c.Errors) > 0 {
-			err := c.Errors.Last()
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		// Handlers that already responded only attach errors for the request log
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			response.HandleError(c, c.Errors.Last().Err)
+			c.Abort()
 		}
 	}
 }
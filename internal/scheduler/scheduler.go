@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/config"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
+	"github.com/Slightly-Techie/st-okr-api/internal/metrics"
+)
+
+// Job is background work repeated on every tick. Run must be safe to repeat
+// and to run on several replicas at once.
+type Job struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Scheduler runs its jobs once on start and then every interval, one after
+// the other
+type Scheduler struct {
+	enabled  bool
+	interval time.Duration
+	jobs     []Job
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+func New(cfg config.SchedulerConfig, jobs ...Job) *Scheduler {
+	return &Scheduler{
+		enabled:  cfg.Enabled,
+		interval: cfg.Interval,
+		jobs:     jobs,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start runs the jobs in the background. It does nothing while the scheduler
+// is disabled.
+func (s *Scheduler) Start() {
+	if !s.enabled || len(s.jobs) == 0 {
+		close(s.done)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	logger.Info("Scheduler started", "interval", s.interval.String(), "jobs", len(s.jobs))
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.runAll(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown cancels the running job and waits for it to return
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.once.Do(func() {
+		if s.cancel != nil {
+			s.cancel()
+		}
+	})
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("scheduled jobs did not finish in time: %w", ctx.Err())
+	}
+}
+
+func (s *Scheduler) runAll(ctx context.Context) {
+	for _, job := range s.jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		s.run(ctx, job)
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	ctx = logger.With(ctx, "job", job.Name)
+	// A run never overlaps the next tick
+	ctx, cancel := context.WithTimeout(ctx, s.interval)
+	defer cancel()
+
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		metrics.SchedulerRuns.WithLabelValues(job.Name, "error").Inc()
+		logger.FromContext(ctx).Error("Scheduled job failed", "error", err.Error(), "duration", time.Since(start).String())
+		return
+	}
+	metrics.SchedulerRuns.WithLabelValues(job.Name, "success").Inc()
+	logger.FromContext(ctx).Info("Scheduled job finished", "duration", time.Since(start).String())
+}
@@ -1,26 +1,52 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
 	"github.com/Slightly-Techie/st-okr-api/internal/dto"
 	"github.com/Slightly-Techie/st-okr-api/internal/logger"
 	"github.com/Slightly-Techie/st-okr-api/internal/message"
 	"github.com/Slightly-Techie/st-okr-api/internal/models"
 	"github.com/Slightly-Techie/st-okr-api/internal/repositories"
 	auth "github.com/Slightly-Techie/st-okr-api/pkg"
-	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
-	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrAccountLinkRequired     = apperror.Conflict("an account with this email already exists; sign in to it and link this provider from your account")
+	ErrIdentityLinkedElsewhere = apperror.Conflict("this provider account is already linked to another user")
+)
+
+// IdentityLinkTTL bounds the time between asking to link a provider and
+// completing its login
+const IdentityLinkTTL = 10 * time.Minute
+
 type AuthService interface {
-	AuthHandler(provider string, c *gin.Context)
-	GetAuthCallback(provider string, c *gin.Context) (*dto.AuthResponse, error)
-	Logout(provider string, c *gin.Context) error
+	CompleteOAuthLogin(ctx context.Context, provider string, gothUser goth.User) (*dto.AuthResponse, error)
+	ListIdentities(ctx context.Context, userID string) ([]models.UserIdentity, error)
+	UnlinkIdentity(ctx context.Context, userID, identityID string) error
+	IssueIdentityLinkToken(ctx context.Context, userID string) (string, error)
+	LinkOAuthIdentity(ctx context.Context, linkToken, provider string, gothUser goth.User) (*dto.AuthResponse, error)
+
+	Register(ctx context.Context, req dto.RegisterRequest) error
+	Login(ctx context.Context, req dto.LoginRequest) (*dto.AuthResponse, error)
+	VerifyEmail(ctx context.Context, req dto.TokenRequest) error
+	ResendVerification(ctx context.Context, req dto.EmailRequest) error
+	RequestPasswordReset(ctx context.Context, req dto.EmailRequest) error
+	ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) error
+	RequestMagicLink(ctx context.Context, req dto.EmailRequest) error
+	LoginWithMagicLink(ctx context.Context, req dto.TokenRequest) (*dto.AuthResponse, error)
+	Refresh(ctx context.Context, req dto.RefreshTokenRequest) (*dto.AuthResponse, error)
+	IssueAuthorizationCode(ctx context.Context, userID string) (string, error)
+	ExchangeAuthorizationCode(ctx context.Context, req dto.AuthorizationCodeRequest) (*dto.AuthResponse, error)
 }
 
 type authService struct {
@@ -35,209 +61,335 @@ func NewAuthService(repo repositories.UserRepository, validator *validator.Valid
 	}
 }
 
-func (s *authService) AuthHandler(provider string, c *gin.Context) {
-	q := c.Request.URL.Query()
-	q.Add("provider", provider)
-	c.Request.URL.RawQuery = q.Encode()
+// CompleteOAuthLogin signs in the user behind a completed social login,
+// creating or linking the account as needed
+func (s *authService) CompleteOAuthLogin(ctx context.Context, provider string, gothUser goth.User) (*dto.AuthResponse, error) {
+	log := logger.FromContext(ctx).With("provider", provider)
 
-	gothic.BeginAuthHandler(c.Writer, c.Request)
-}
+	log.Debug("OAuth user data received",
+		"provider_user_id", gothUser.UserID,
+		"email", maskEmailForLog(gothUser.Email),
+		"nickname", gothUser.NickName,
+	)
+
+	existingUser, err := s.resolveUser(ctx, provider, gothUser)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *authService) GetAuthCallback(provider string, c *gin.Context) (*dto.AuthResponse, error) {
-	requestID := getRequestIDFromContext(c)
-	remoteIP := c.ClientIP()
+	if err := s.ensureSSONotEnforced(ctx, existingUser); err != nil {
+		return nil, err
+	}
 
-	logger.Info("Processing OAuth callback",
-		"request_id", requestID,
-		"provider", provider,
-		"remote_ip", remoteIP,
+	// Publish sign-up message
+	log.Debug("Publishing sign-up message",
+		"user_id", existingUser.ID,
+	)
+
+	message.PublishMessage(ctx, "sign_up", map[string]any{
+		"user_name": existingUser.UserName,
+		"email":     existingUser.Email,
+	})
+
+	response, err := issueTokens(ctx, existingUser)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("OAuth authentication completed successfully",
+		"user_id", existingUser.ID,
+		"email", maskEmailForLog(existingUser.Email),
 	)
 
-	// Add provider to query parameters
-	q := c.Request.URL.Query()
-	q.Add("provider", provider)
-	c.Request.URL.RawQuery = q.Encode()
+	return response, nil
+}
+
+// issueTokens creates the access and refresh tokens for a signed-in user
+func issueTokens(ctx context.Context, user *models.User) (*dto.AuthResponse, error) {
+	log := logger.FromContext(ctx)
 
-	// Get user data from OAuth provider
-	gothUser, err := gothic.CompleteUserAuth(c.Writer, c.Request)
+	log.Debug("Generating JWT tokens",
+		"user_id", user.ID,
+	)
+
+	accessToken, refreshToken, expiry, err := auth.CreateJWTTokens(user.ID)
 	if err != nil {
-		logger.Error("Failed to complete OAuth user authentication",
-			"request_id", requestID,
-			"provider", provider,
-			"remote_ip", remoteIP,
+		log.Error("Failed to create JWT tokens",
+			"user_id", user.ID,
 			"error", err.Error(),
 		)
-		return nil, fmt.Errorf("failed to complete user auth: %w", err)
+		return nil, fmt.Errorf("failed to create JWT tokens: %w", err)
 	}
 
-	logger.Debug("OAuth user data received",
-		"request_id", requestID,
-		"provider", provider,
-		"provider_user_id", gothUser.UserID,
-		"email", maskEmailForLog(gothUser.Email),
-		"nickname", gothUser.NickName,
+	log.Info("JWT tokens generated successfully",
+		"user_id", user.ID,
+		"expires_in", expiry,
 	)
 
-	// Look for existing user
-	logger.Debug("Looking up existing user",
-		"request_id", requestID,
+	return &dto.AuthResponse{
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		UserName:     user.UserName,
+		Email:        user.Email,
+		ID:           user.ID,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    expiry,
+	}, nil
+}
+
+// ensureSSONotEnforced rejects non-SSO logins for users whose company enforces SSO
+func (s *authService) ensureSSONotEnforced(ctx context.Context, user *models.User) error {
+	log := logger.FromContext(ctx)
+
+	enforced, err := ssoEnforcedFor(s.repo.GetDB().WithContext(ctx), user)
+	if err != nil {
+		return err
+	}
+
+	if enforced {
+		log.Warn("Non-SSO login blocked by company policy",
+			"user_id", user.ID,
+		)
+		return ErrSSORequired
+	}
+	return nil
+}
+
+// resolveUser finds the user behind a provider identity. Identities are matched by
+// provider and provider user ID first, then by the legacy users.provider_id column,
+// and finally by email so that a person signing in through a second provider is
+// linked to their existing account instead of colliding on the unique email.
+// Linking by email needs the provider to vouch for the address and the account
+// to have verified it; otherwise the person must sign in to the account and
+// link the provider explicitly, or anyone able to claim the address at some
+// provider could take the account over.
+func (s *authService) resolveUser(ctx context.Context, provider string, gothUser goth.User) (*models.User, error) {
+	log := logger.FromContext(ctx)
+
+	log.Debug("Looking up user identity",
+		"provider", provider,
 		"provider_user_id", gothUser.UserID,
 	)
 
-	var existingUser models.User
-	result := s.repo.GetDB().Where("provider_id = ?", gothUser.UserID).First(&existingUser)
-
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			logger.Info("Creating new user account",
-				"request_id", requestID,
-				"provider", provider,
-				"provider_user_id", gothUser.UserID,
-				"email", maskEmailForLog(gothUser.Email),
-				"username", gothUser.NickName,
+	identity, err := s.repo.GetIdentity(ctx, provider, gothUser.UserID)
+	if err == nil {
+		user, err := s.repo.GetByIdentifier(ctx, "id = ?", identity.UserID)
+		if err != nil {
+			log.Error("Identity references a missing user",
+				"identity_id", identity.ID,
+				"user_id", identity.UserID,
+				"error", err.Error(),
 			)
+			return nil, fmt.Errorf("failed to get user for identity: %w", err)
+		}
 
-			// If user doesn't exist, create new user
-			newUser := models.User{
-				ID:         uuid.NewString(),
-				FirstName:  gothUser.FirstName,
-				LastName:   gothUser.LastName,
-				AvatarURL:  gothUser.AvatarURL,
-				UserName:   gothUser.NickName,
-				ProviderID: gothUser.UserID,
-				Email:      gothUser.Email,
-			}
+		log.Info("Existing user found",
+			"user_id", user.ID,
+			"provider", provider,
+			"email", maskEmailForLog(user.Email),
+		)
+		return user, nil
+	}
+	if !errors.Is(err, repositories.ErrUserIdentityNotFound) {
+		log.Error("Database error during identity lookup",
+			"provider_user_id", gothUser.UserID,
+			"error", err.Error(),
+		)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
 
-			if err := s.repo.GetDB().Create(&newUser).Error; err != nil {
-				logger.Error("Failed to create new user",
-					"request_id", requestID,
-					"provider_user_id", gothUser.UserID,
-					"email", maskEmailForLog(gothUser.Email),
-					"error", err.Error(),
+	emailVerified := providerVerifiedEmail(provider, gothUser)
+
+	var user models.User
+	err = s.repo.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Accounts created before identities existed only carry provider_id
+		res := tx.Where("provider_id = ?", gothUser.UserID).First(&user)
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) && gothUser.Email != "" {
+			res = tx.Where("LOWER(email) = LOWER(?)", gothUser.Email).First(&user)
+			if res.Error == nil && (!emailVerified || user.EmailVerifiedAt == nil) {
+				log.Warn("Provider login matches an account it cannot be linked to automatically",
+					"user_id", user.ID,
+					"provider", provider,
+					"provider_email_verified", emailVerified,
 				)
-				return nil, fmt.Errorf("failed to create user: %w", err)
+				return ErrAccountLinkRequired
 			}
+		}
 
-			logger.Info("New user created successfully",
-				"request_id", requestID,
-				"user_id", newUser.ID,
+		switch {
+		case res.Error == nil:
+			log.Info("Linking provider identity to existing user",
+				"user_id", user.ID,
 				"provider", provider,
-				"email", maskEmailForLog(newUser.Email),
+				"email", maskEmailForLog(user.Email),
 			)
-
-			existingUser = newUser
-		} else {
-			logger.Error("Database error during user lookup",
-				"request_id", requestID,
+		case errors.Is(res.Error, gorm.ErrRecordNotFound):
+			log.Info("Creating new user account",
+				"provider", provider,
 				"provider_user_id", gothUser.UserID,
-				"error", result.Error.Error(),
+				"email", maskEmailForLog(gothUser.Email),
+				"username", gothUser.NickName,
 			)
-			return nil, fmt.Errorf("database error: %w", result.Error)
-		}
-	} else {
-		logger.Info("Existing user found",
-			"request_id", requestID,
-			"user_id", existingUser.ID,
-			"provider", provider,
-			"email", maskEmailForLog(existingUser.Email),
-		)
-	}
 
-	// Generate JWT tokens
-	logger.Debug("Generating JWT tokens",
-		"request_id", requestID,
-		"user_id", existingUser.ID,
-	)
+			user = models.User{
+				ID:        uuid.NewString(),
+				FirstName: gothUser.FirstName,
+				LastName:  gothUser.LastName,
+				AvatarURL: gothUser.AvatarURL,
+				UserName:  gothUser.NickName,
+				Email:     gothUser.Email,
+			}
+			if emailVerified {
+				verifiedAt := time.Now()
+				user.EmailVerifiedAt = &verifiedAt
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return fmt.Errorf("failed to create user: %w", err)
+			}
+		default:
+			return fmt.Errorf("database error: %w", res.Error)
+		}
 
-	accessToken, refreshToken, expiry, err := auth.CreateJWTTokens(existingUser.ID)
+		return createIdentity(tx, &user, provider, gothUser)
+	})
 	if err != nil {
-		logger.Error("Failed to create JWT tokens",
-			"request_id", requestID,
-			"user_id", existingUser.ID,
+		log.Error("Failed to resolve user for provider identity",
+			"provider", provider,
+			"provider_user_id", gothUser.UserID,
+			"email", maskEmailForLog(gothUser.Email),
 			"error", err.Error(),
 		)
-		return nil, fmt.Errorf("failed to create JWT tokens: %w", err)
+		return nil, err
 	}
 
-	logger.Info("JWT tokens generated successfully",
-		"request_id", requestID,
-		"user_id", existingUser.ID,
-		"expires_in", expiry,
-	)
-
-	// Publish sign-up message
-	logger.Debug("Publishing sign-up message",
-		"request_id", requestID,
-		"user_id", existingUser.ID,
-	)
-
-	message.PublishMessage("sign_up", map[string]any{
-		"user_name": existingUser.UserName,
-		"email":     existingUser.Email,
-	})
+	return &user, nil
+}
 
-	// Create response
-	response := &dto.AuthResponse{
-		FirstName:    existingUser.FirstName,
-		LastName:     existingUser.LastName,
-		UserName:     existingUser.UserName,
-		Email:        existingUser.Email,
-		ID:           existingUser.ID,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    expiry,
+// providerVerifiedEmail reports whether the provider vouches that the person
+// signing in owns gothUser.Email
+func providerVerifiedEmail(provider string, gothUser goth.User) bool {
+	if gothUser.Email == "" {
+		return false
 	}
+	switch provider {
+	case "google":
+		verified, _ := gothUser.RawData["verified_email"].(bool)
+		return verified
+	case "github":
+		// Without a public profile email, goth takes the primary address
+		// from the user's email list and only if GitHub verified it
+		public, _ := gothUser.RawData["email"].(string)
+		return public == ""
+	default:
+		// Microsoft Entra lets each tenant set the mail attribute to any
+		// address, so it proves nothing about ownership
+		return false
+	}
+}
 
-	logger.Info("OAuth authentication completed successfully",
-		"request_id", requestID,
-		"user_id", existingUser.ID,
-		"provider", provider,
-		"email", maskEmailForLog(existingUser.Email),
-	)
-
-	return response, nil
+func createIdentity(tx *gorm.DB, user *models.User, provider string, gothUser goth.User) error {
+	identity := models.UserIdentity{
+		ID:             uuid.NewString(),
+		UserID:         user.ID,
+		Provider:       provider,
+		ProviderUserID: gothUser.UserID,
+		Email:          gothUser.Email,
+		EmailVerified:  providerVerifiedEmail(provider, gothUser) && strings.EqualFold(gothUser.Email, user.Email),
+	}
+	if err := tx.Create(&identity).Error; err != nil {
+		return fmt.Errorf("failed to create user identity: %w", err)
+	}
+	return nil
 }
 
-func (s *authService) Logout(provider string, c *gin.Context) error {
-	requestID := getRequestIDFromContext(c)
-	remoteIP := c.ClientIP()
+// IssueIdentityLinkToken starts linking a provider to the signed-in user. The
+// browser carries the token through the provider login, whose identity is
+// then linked by LinkOAuthIdentity whatever its email.
+func (s *authService) IssueIdentityLinkToken(ctx context.Context, userID string) (string, error) {
+	token, hash, err := auth.GenerateToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate link token: %w", err)
+	}
 
-	logger.Info("Processing logout request",
-		"request_id", requestID,
-		"provider", provider,
-		"remote_ip", remoteIP,
-	)
+	_, err = s.repo.CreateAuthToken(ctx, &models.AuthToken{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Purpose:   models.AuthTokenIdentityLink,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(IdentityLinkTTL),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store link token: %w", err)
+	}
+	return token, nil
+}
 
-	q := c.Request.URL.Query()
-	q.Add("provider", provider)
-	c.Request.URL.RawQuery = q.Encode()
+// LinkOAuthIdentity links a completed provider login to the user who asked
+// for it with linkToken, and signs that user in
+func (s *authService) LinkOAuthIdentity(ctx context.Context, linkToken, provider string, gothUser goth.User) (*dto.AuthResponse, error) {
+	log := logger.FromContext(ctx).With("provider", provider)
 
-	err := gothic.Logout(c.Writer, c.Request)
+	user, err := s.redeemToken(ctx, models.AuthTokenIdentityLink, linkToken)
 	if err != nil {
-		logger.Error("Failed to logout user",
-			"request_id", requestID,
-			"provider", provider,
-			"remote_ip", remoteIP,
-			"error", err.Error(),
+		return nil, err
+	}
+
+	identity, err := s.repo.GetIdentity(ctx, provider, gothUser.UserID)
+	switch {
+	case err == nil && identity.UserID != user.ID:
+		log.Warn("Provider account is linked to another user",
+			"user_id", user.ID,
 		)
-		return err
+		return nil, ErrIdentityLinkedElsewhere
+	case errors.Is(err, repositories.ErrUserIdentityNotFound):
+		if err := createIdentity(s.repo.GetDB().WithContext(ctx), user, provider, gothUser); err != nil {
+			return nil, err
+		}
+		log.Info("Provider identity linked",
+			"user_id", user.ID,
+		)
+	case err != nil:
+		return nil, fmt.Errorf("database error: %w", err)
 	}
 
-	logger.Info("User logout completed successfully",
-		"request_id", requestID,
-		"provider", provider,
-		"remote_ip", remoteIP,
-	)
+	if err := s.ensureSSONotEnforced(ctx, user); err != nil {
+		return nil, err
+	}
+	return issueTokens(ctx, user)
+}
 
-	return nil
+func (s *authService) ListIdentities(ctx context.Context, userID string) ([]models.UserIdentity, error) {
+	identities, err := s.repo.ListIdentities(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+	return identities, nil
 }
 
-// Helper functions
-func getRequestIDFromContext(c *gin.Context) string {
-	if reqID, exists := c.Get("request_id"); exists {
-		return reqID.(string)
+func (s *authService) UnlinkIdentity(ctx context.Context, userID, identityID string) error {
+	identities, err := s.repo.ListIdentities(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list identities: %w", err)
 	}
-	return "unknown"
+	user, err := s.repo.GetByIdentifier(ctx, "id = ?", userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// A password is a sign-in method as well
+	methods := len(identities)
+	if user.PasswordHash != "" {
+		methods++
+	}
+	if methods <= 1 {
+		return apperror.Conflict("cannot unlink the only sign-in method of the account")
+	}
+
+	if err := s.repo.DeleteIdentity(ctx, userID, identityID); err != nil {
+		return fmt.Errorf("failed to unlink identity: %w", err)
+	}
+	return nil
 }
 
 func maskEmailForLog(email string) string {
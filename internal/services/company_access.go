@@ -0,0 +1,44 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"github.com/Slightly-Techie/st-okr-api/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var ErrNotCompanyMember = apperror.Forbidden("only active members of the company can view its OKRs")
+
+// checkCompanyAccess returns repositories.ErrCompanyNotFound if the company
+// does not exist, and forbidden unless userID is an active member holding
+// one of roles
+func checkCompanyAccess(db *gorm.DB, companyID, userID string, forbidden error, roles ...models.RoleType) error {
+	var company models.Company
+	if err := db.Select("id").Where("id = ?", companyID).First(&company).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return repositories.ErrCompanyNotFound
+		}
+		return fmt.Errorf("failed to get company: %w", err)
+	}
+
+	var membership models.Membership
+	err := db.Where("company_id = ? AND user_id = ?", companyID, userID).First(&membership).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return forbidden
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get membership: %w", err)
+	}
+	if membership.Status != models.StatusActive {
+		return forbidden
+	}
+	for _, role := range roles {
+		if membership.Role == role {
+			return nil
+		}
+	}
+	return forbidden
+}
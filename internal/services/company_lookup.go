@@ -0,0 +1,61 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+)
+
+// teamLookup resolves a team given by ID or name to the ID of one of a
+// company's teams. Keys are lower-cased IDs and names.
+type teamLookup map[string][]string
+
+func newTeamLookup(teams []models.Team) teamLookup {
+	lookup := make(teamLookup, 2*len(teams))
+	for _, team := range teams {
+		lookup[strings.ToLower(team.ID)] = []string{team.ID}
+		name := strings.ToLower(strings.TrimSpace(team.Name))
+		lookup[name] = append(lookup[name], team.ID)
+	}
+	return lookup
+}
+
+func (l teamLookup) resolve(ref string) (string, error) {
+	ids := l[strings.ToLower(strings.TrimSpace(ref))]
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("the company has no team %q", ref)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("the company has %d teams named %q; use the team ID", len(ids), ref)
+	}
+}
+
+// cycleLookup resolves a cycle given by name to the dates of one of a
+// company's cycles. Keys are lower-cased names.
+type cycleLookup map[string]models.Cycle
+
+func newCycleLookup(cycles []models.Cycle) cycleLookup {
+	lookup := make(cycleLookup, len(cycles))
+	for _, cycle := range cycles {
+		lookup[strings.ToLower(cycle.Name)] = cycle
+	}
+	return lookup
+}
+
+// resolve returns the half-open range of one of the company's cycles, or of
+// a calendar period as accepted by parseCycle when no cycle has that name
+func (l cycleLookup) resolve(ref string) (time.Time, time.Time, error) {
+	if cycle, ok := l[strings.ToLower(strings.TrimSpace(ref))]; ok {
+		from, to := cycle.Range()
+		return from, to, nil
+	}
+	from, to, err := parseCycle(ref)
+	if err != nil {
+		return from, to, fmt.Errorf("the company has no cycle named %q and it is not a year, half or quarter such as 2025, 2025-H1 or 2025-Q3", ref)
+	}
+	return from, to, nil
+}
@@ -1,9 +1,14 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Slightly-Techie/st-okr-api/helper"
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
 	"github.com/Slightly-Techie/st-okr-api/internal/dto"
 	"github.com/Slightly-Techie/st-okr-api/internal/models"
 	"github.com/Slightly-Techie/st-okr-api/internal/repositories"
@@ -12,11 +17,28 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	ErrStatusThresholdsForbidden = apperror.Forbidden("only company admins can change status thresholds")
+	ErrCurrencySettingsForbidden = apperror.Forbidden("only company admins can change the reporting currency and exchange rates")
+	ErrCyclesForbidden           = apperror.Forbidden("only company admins can create and delete cycles")
+	ErrCycleExists               = apperror.Conflict("the company already has a cycle with this name")
+)
+
 type CompanyService interface {
-	CreateCompany(r dto.CreateCompanyRequest) (*models.Company, error)
-	GetCompany(ident, id string) (*models.Company, error)
-	DeleteCompany(id string) error
-	UpdateCompany(r dto.CreateCompanyRequest) (*models.Company, error)
+	CreateCompany(ctx context.Context, r dto.CreateCompanyRequest) (*models.Company, error)
+	GetCompany(ctx context.Context, ident, id string) (*models.Company, error)
+	ListCompanies(ctx context.Context) ([]models.Company, error)
+	DeleteCompany(ctx context.Context, id string) error
+	UpdateCompany(ctx context.Context, r dto.CreateCompanyRequest) (*models.Company, error)
+	GetStatusThresholds(ctx context.Context, userID, companyID string) (*models.StatusThresholds, error)
+	UpdateStatusThresholds(ctx context.Context, userID, companyID string, r dto.StatusThresholdsRequest) (*models.StatusThresholds, error)
+	GetCurrencySettings(ctx context.Context, userID, companyID string) (*dto.CurrencySettingsResponse, error)
+	UpdateReportingCurrency(ctx context.Context, userID, companyID string, r dto.ReportingCurrencyRequest) (*dto.CurrencySettingsResponse, error)
+	SetExchangeRate(ctx context.Context, userID, companyID string, r dto.ExchangeRateRequest) (*models.ExchangeRate, error)
+	DeleteExchangeRate(ctx context.Context, userID, companyID, from, to string) error
+	ListCycles(ctx context.Context, userID, companyID string) ([]models.Cycle, error)
+	CreateCycle(ctx context.Context, userID, companyID string, r dto.CreateCycleRequest) (*models.Cycle, error)
+	DeleteCycle(ctx context.Context, userID, companyID, cycleID string) error
 }
 
 type companyService struct {
@@ -31,14 +53,14 @@ func NewCompanyService(repo repositories.CompanyRepository, validator *validator
 	}
 }
 
-func (c *companyService) CreateCompany(r dto.CreateCompanyRequest) (*models.Company, error) {
+func (c *companyService) CreateCompany(ctx context.Context, r dto.CreateCompanyRequest) (*models.Company, error) {
 	if err := c.validator.Struct(r); err != nil {
 		return nil, err
 	}
 
 	var company models.Company
 
-	err := c.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+	err := c.repo.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Create company
 		company = models.Company{
 			ID:        uuid.NewString(),
@@ -74,15 +96,23 @@ func (c *companyService) CreateCompany(r dto.CreateCompanyRequest) (*models.Comp
 	return &company, nil
 }
 
-func (c *companyService) GetCompany(ident, id string) (*models.Company, error) {
-	company, err := c.repo.GetByIdentifier(ident, id)
+func (c *companyService) GetCompany(ctx context.Context, ident, id string) (*models.Company, error) {
+	company, err := c.repo.GetByIdentifier(ctx, ident, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get company: %w", err)
 	}
 	return company, nil
 }
 
-func (c *companyService) UpdateCompany(r dto.CreateCompanyRequest) (*models.Company, error) {
+func (c *companyService) ListCompanies(ctx context.Context) ([]models.Company, error) {
+	companies, err := c.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list companies: %w", err)
+	}
+	return companies, nil
+}
+
+func (c *companyService) UpdateCompany(ctx context.Context, r dto.CreateCompanyRequest) (*models.Company, error) {
 	if err := c.validator.Struct(r); err != nil {
 		return nil, err
 	}
@@ -92,7 +122,7 @@ func (c *companyService) UpdateCompany(r dto.CreateCompanyRequest) (*models.Comp
 		CreatorID: r.CreatorId,
 	}
 
-	updatedCompany, err := c.repo.Update(&company)
+	updatedCompany, err := c.repo.Update(ctx, &company)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update company: %w", err)
 	}
@@ -100,8 +130,229 @@ func (c *companyService) UpdateCompany(r dto.CreateCompanyRequest) (*models.Comp
 	return updatedCompany, nil
 }
 
-func (c *companyService) DeleteCompany(id string) error {
-	if err := c.repo.Delete(id); err != nil {
+// GetStatusThresholds returns the thresholds key results of the company are
+// judged by. Any active member can read them.
+func (c *companyService) GetStatusThresholds(ctx context.Context, userID, companyID string) (*models.StatusThresholds, error) {
+	db := c.repo.GetDB().WithContext(ctx)
+	if err := checkCompanyAccess(db, companyID, userID, ErrNotCompanyMember, models.RoleAdmin, models.RoleMember, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	company, err := c.repo.GetByIdentifier(ctx, "id", companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get company: %w", err)
+	}
+	thresholds := company.StatusThresholds()
+	return &thresholds, nil
+}
+
+// UpdateStatusThresholds changes the company's thresholds. Key results are
+// re-evaluated against them on their next check-in or scheduled refresh.
+func (c *companyService) UpdateStatusThresholds(ctx context.Context, userID, companyID string, r dto.StatusThresholdsRequest) (*models.StatusThresholds, error) {
+	if err := c.validator.Struct(r); err != nil {
+		return nil, err
+	}
+
+	db := c.repo.GetDB().WithContext(ctx)
+	if err := checkCompanyAccess(db, companyID, userID, ErrStatusThresholdsForbidden, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	thresholds := models.StatusThresholds{AtRisk: r.AtRisk, Behind: r.Behind}
+	if err := c.repo.UpdateStatusThresholds(ctx, companyID, thresholds); err != nil {
+		return nil, fmt.Errorf("failed to update status thresholds: %w", err)
+	}
+	return &thresholds, nil
+}
+
+// GetCurrencySettings returns the currency the company reports currency key
+// results in and the exchange rates it converts them at. Any active member
+// can read them.
+func (c *companyService) GetCurrencySettings(ctx context.Context, userID, companyID string) (*dto.CurrencySettingsResponse, error) {
+	db := c.repo.GetDB().WithContext(ctx)
+	if err := checkCompanyAccess(db, companyID, userID, ErrNotCompanyMember, models.RoleAdmin, models.RoleMember, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	return c.currencySettings(ctx, companyID)
+}
+
+// UpdateReportingCurrency changes the currency key results are converted to.
+// Rates are kept between pairs of currencies, so those of the previous
+// reporting currency stay in place for switching back.
+func (c *companyService) UpdateReportingCurrency(ctx context.Context, userID, companyID string, r dto.ReportingCurrencyRequest) (*dto.CurrencySettingsResponse, error) {
+	code := ""
+	if r.ReportingCurrency != "" {
+		currency, err := lookupCurrency("reporting_currency", r.ReportingCurrency)
+		if err != nil {
+			return nil, err
+		}
+		code = currency.Code
+	}
+
+	db := c.repo.GetDB().WithContext(ctx)
+	if err := checkCompanyAccess(db, companyID, userID, ErrCurrencySettingsForbidden, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	if err := c.repo.UpdateReportingCurrency(ctx, companyID, code); err != nil {
+		return nil, fmt.Errorf("failed to update reporting currency: %w", err)
+	}
+	return c.currencySettings(ctx, companyID)
+}
+
+// SetExchangeRate adds or replaces the company's rate from one currency to
+// another. The opposite conversion uses its inverse unless it has a rate of
+// its own.
+func (c *companyService) SetExchangeRate(ctx context.Context, userID, companyID string, r dto.ExchangeRateRequest) (*models.ExchangeRate, error) {
+	if err := c.validator.Struct(r); err != nil {
+		return nil, err
+	}
+	from, err := lookupCurrency("from", r.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := lookupCurrency("to", r.To)
+	if err != nil {
+		return nil, err
+	}
+	if from.Code == to.Code {
+		return nil, apperror.Validation("invalid exchange rate", map[string]string{"to": "an exchange rate needs two different currencies"})
+	}
+
+	db := c.repo.GetDB().WithContext(ctx)
+	if err := checkCompanyAccess(db, companyID, userID, ErrCurrencySettingsForbidden, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	rate := models.ExchangeRate{
+		CompanyID:    companyID,
+		FromCurrency: from.Code,
+		ToCurrency:   to.Code,
+		Rate:         r.Rate,
+		UpdatedAt:    time.Now(),
+	}
+	if err := c.repo.UpsertExchangeRate(ctx, &rate); err != nil {
+		return nil, fmt.Errorf("failed to save exchange rate: %w", err)
+	}
+	return &rate, nil
+}
+
+func (c *companyService) DeleteExchangeRate(ctx context.Context, userID, companyID, from, to string) error {
+	db := c.repo.GetDB().WithContext(ctx)
+	if err := checkCompanyAccess(db, companyID, userID, ErrCurrencySettingsForbidden, models.RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := c.repo.DeleteExchangeRate(ctx, companyID, strings.ToUpper(from), strings.ToUpper(to)); err != nil {
+		return fmt.Errorf("failed to delete exchange rate: %w", err)
+	}
+	return nil
+}
+
+// ListCycles returns the company's cycles in date order. Any active member
+// can read them.
+func (c *companyService) ListCycles(ctx context.Context, userID, companyID string) ([]models.Cycle, error) {
+	db := c.repo.GetDB().WithContext(ctx)
+	if err := checkCompanyAccess(db, companyID, userID, ErrNotCompanyMember, models.RoleAdmin, models.RoleMember, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	cycles, err := c.repo.ListCycles(ctx, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cycles: %w", err)
+	}
+	return cycles, nil
+}
+
+// CreateCycle names a period of the company's OKRs. Without dates the name
+// must be a calendar period, which gives them. Names are unique within the
+// company regardless of case, since filters and imports match them that way.
+func (c *companyService) CreateCycle(ctx context.Context, userID, companyID string, r dto.CreateCycleRequest) (*models.Cycle, error) {
+	if err := c.validator.Struct(r); err != nil {
+		return nil, err
+	}
+
+	cycle := models.Cycle{
+		ID:        uuid.NewString(),
+		CompanyID: companyID,
+		Name:      strings.TrimSpace(r.Name),
+		CreatedAt: time.Now(),
+	}
+	if r.StartDate == "" && r.EndDate == "" {
+		from, to, err := parseCycle(cycle.Name)
+		if err != nil {
+			return nil, apperror.Validation("invalid cycle", map[string]string{
+				"start_date": "start_date and end_date are required unless the name is a year, half or quarter such as 2025, 2025-H1 or 2025-Q3",
+			})
+		}
+		cycle.StartDate, cycle.EndDate = from, to.AddDate(0, 0, -1)
+	} else {
+		details := map[string]string{}
+		var err error
+		if cycle.StartDate, err = time.Parse(time.DateOnly, r.StartDate); err != nil {
+			details["start_date"] = "start_date must be a date in YYYY-MM-DD form"
+		}
+		if cycle.EndDate, err = time.Parse(time.DateOnly, r.EndDate); err != nil {
+			details["end_date"] = "end_date must be a date in YYYY-MM-DD form"
+		} else if len(details) == 0 && cycle.EndDate.Before(cycle.StartDate) {
+			details["end_date"] = "end_date must not be before start_date"
+		}
+		if len(details) > 0 {
+			return nil, apperror.Validation("invalid cycle", details)
+		}
+	}
+
+	db := c.repo.GetDB().WithContext(ctx)
+	if err := checkCompanyAccess(db, companyID, userID, ErrCyclesForbidden, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	var existing int64
+	err := db.Model(&models.Cycle{}).
+		Where("company_id = ? AND LOWER(name) = LOWER(?)", companyID, cycle.Name).
+		Count(&existing).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to check cycle names: %w", err)
+	}
+	if existing > 0 {
+		return nil, ErrCycleExists
+	}
+
+	if err := c.repo.CreateCycle(ctx, &cycle); err != nil {
+		if errors.Is(err, repositories.ErrDuplicate) {
+			return nil, ErrCycleExists
+		}
+		return nil, fmt.Errorf("failed to create cycle: %w", err)
+	}
+	return &cycle, nil
+}
+
+func (c *companyService) DeleteCycle(ctx context.Context, userID, companyID, cycleID string) error {
+	db := c.repo.GetDB().WithContext(ctx)
+	if err := checkCompanyAccess(db, companyID, userID, ErrCyclesForbidden, models.RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := c.repo.DeleteCycle(ctx, companyID, cycleID); err != nil {
+		return fmt.Errorf("failed to delete cycle: %w", err)
+	}
+	return nil
+}
+
+func (c *companyService) currencySettings(ctx context.Context, companyID string) (*dto.CurrencySettingsResponse, error) {
+	company, err := c.repo.GetByIdentifier(ctx, "id", companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get company: %w", err)
+	}
+	rates, err := c.repo.ListExchangeRates(ctx, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exchange rates: %w", err)
+	}
+	return &dto.CurrencySettingsResponse{ReportingCurrency: company.ReportingCurrency, Rates: rates}, nil
+}
+
+func (c *companyService) DeleteCompany(ctx context.Context, id string) error {
+	if err := c.repo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete company: %w", err)
 	}
 	return nil
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"github.com/Slightly-Techie/st-okr-api/internal/money"
+	"gorm.io/gorm"
+)
+
+// lookupCurrency finds an ISO 4217 currency given in field of a request
+func lookupCurrency(field, code string) (money.Currency, error) {
+	currency, ok := money.Lookup(code)
+	if !ok {
+		message := fmt.Sprintf("%q is not an ISO 4217 currency code", code)
+		return money.Currency{}, apperror.Validation("invalid currency", map[string]string{field: message})
+	}
+	return currency, nil
+}
+
+// defaultCurrency gives a currency key result without a currency, whether
+// left out of the request or created before currencies were kept, its
+// company's reporting currency
+func defaultCurrency(db *gorm.DB, kr *models.KeyResult) error {
+	if kr.MetricType != models.MetrictTypeCurrency || kr.Currency != "" {
+		return nil
+	}
+
+	var codes []string
+	err := db.Model(&models.Company{}).
+		Joins("JOIN objectives ON objectives.company_id = companies.id").
+		Where("objectives.id = ?", kr.ObjectiveID).
+		Pluck("companies.reporting_currency", &codes).Error
+	if err != nil {
+		return fmt.Errorf("failed to load reporting currency: %w", err)
+	}
+	if len(codes) > 0 {
+		kr.Currency = codes[0]
+	}
+	return nil
+}
+
+// setCurrencyAmounts fixes the minor unit amounts of a currency key result
+// from the exact decimal amounts of a request, falling back to its float
+// values, rounded to the currency, where an amount is not given. The float
+// values are then derived from the amounts. Other key results lose any
+// currency they had.
+func setCurrencyAmounts(kr *models.KeyResult, current, target, start string) error {
+	if kr.MetricType != models.MetrictTypeCurrency {
+		kr.Currency = ""
+		kr.CurrentMinor, kr.TargetMinor, kr.StartMinor = 0, 0, 0
+		return nil
+	}
+	currency, ok := money.Lookup(kr.Currency)
+	if !ok {
+		// left for ValidateMetricValues to report
+		return nil
+	}
+	kr.Currency = currency.Code
+
+	amount := func(field, value string, fallback float64) (int64, error) {
+		if value == "" {
+			return currency.FromMajor(fallback), nil
+		}
+		minor, err := currency.Parse(value)
+		if err != nil {
+			return 0, apperror.Validation(err.Error(), map[string]string{field: err.Error()})
+		}
+		return minor, nil
+	}
+	currentMinor, err := amount("current_amount", current, kr.CurrentValue)
+	if err != nil {
+		return err
+	}
+	targetMinor, err := amount("target_amount", target, kr.TargetValue)
+	if err != nil {
+		return err
+	}
+	startMinor, err := amount("start_amount", start, kr.StartValue)
+	if err != nil {
+		return err
+	}
+	kr.SetAmounts(currentMinor, targetMinor, startMinor)
+	return nil
+}
+
+// formatAmounts fills in the display amounts of the currency key results
+// among keyResults, converted to the reporting currency of their company
+// where it keeps a rate for them
+func formatAmounts(db *gorm.DB, keyResults ...*models.KeyResult) error {
+	var objectiveIDs []string
+	for _, kr := range keyResults {
+		if kr.MetricType == models.MetrictTypeCurrency && kr.Currency != "" {
+			objectiveIDs = append(objectiveIDs, kr.ObjectiveID)
+		}
+	}
+	if len(objectiveIDs) == 0 {
+		return nil
+	}
+
+	var owners []struct {
+		ObjectiveID       string
+		CompanyID         string
+		ReportingCurrency string
+	}
+	err := db.Table("objectives").
+		Joins("JOIN companies ON companies.id = objectives.company_id").
+		Where("objectives.id IN ?", objectiveIDs).
+		Select("objectives.id AS objective_id, companies.id AS company_id, companies.reporting_currency").
+		Scan(&owners).Error
+	if err != nil {
+		return fmt.Errorf("failed to get reporting currencies: %w", err)
+	}
+
+	companyIDs := make([]string, 0, len(owners))
+	for _, owner := range owners {
+		if owner.ReportingCurrency != "" {
+			companyIDs = append(companyIDs, owner.CompanyID)
+		}
+	}
+	rates := make(map[string]models.ExchangeRates, len(companyIDs))
+	if len(companyIDs) > 0 {
+		var all []models.ExchangeRate
+		if err := db.Where("company_id IN ?", companyIDs).Find(&all).Error; err != nil {
+			return fmt.Errorf("failed to get exchange rates: %w", err)
+		}
+		for _, rate := range all {
+			rates[rate.CompanyID] = append(rates[rate.CompanyID], rate)
+		}
+	}
+
+	for _, kr := range keyResults {
+		for _, owner := range owners {
+			if owner.ObjectiveID == kr.ObjectiveID {
+				kr.FormatAmounts(owner.ReportingCurrency, rates[owner.CompanyID])
+				break
+			}
+		}
+	}
+	return nil
+}
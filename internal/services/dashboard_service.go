@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"github.com/Slightly-Techie/st-okr-api/internal/repositories"
+)
+
+// defaultAtRiskLimit and maxAtRiskLimit bound the at-risk key results listed
+// on the dashboard
+const (
+	defaultAtRiskLimit = 10
+	maxAtRiskLimit     = 100
+)
+
+// progressBucketRanges names the buckets of DashboardRepository.ProgressDistribution
+var progressBucketRanges = []string{"0-25", "25-50", "50-75", "75-100", "100"}
+
+// DashboardRequest selects the objectives a dashboard covers. Team and Cycle
+// work as they do for exports.
+type DashboardRequest struct {
+	CompanyID   string
+	UserID      string
+	Team        string
+	Cycle       string
+	AtRiskLimit int
+}
+
+type DashboardService interface {
+	GetDashboard(ctx context.Context, req DashboardRequest) (*dto.DashboardResponse, error)
+}
+
+type dashboardService struct {
+	repo repositories.DashboardRepository
+}
+
+func NewDashboardService(repo repositories.DashboardRepository) DashboardService {
+	return &dashboardService{
+		repo: repo,
+	}
+}
+
+func (s *dashboardService) GetDashboard(ctx context.Context, req DashboardRequest) (*dto.DashboardResponse, error) {
+	db := s.repo.GetDB().WithContext(ctx)
+	if err := checkCompanyAccess(db, req.CompanyID, req.UserID, ErrNotCompanyMember, models.RoleAdmin, models.RoleMember, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	filter, _, err := companyObjectiveFilter(db, req.CompanyID, req.Team, req.Cycle, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.AtRiskLimit
+	if limit <= 0 {
+		limit = defaultAtRiskLimit
+	}
+	limit = min(limit, maxAtRiskLimit)
+
+	now := time.Now()
+	dashboard := &dto.DashboardResponse{
+		GeneratedAt: now.UTC(),
+		ObjectivesByStatus: map[models.ObjectiveStatus]int64{
+			models.ObjectiveStatusDraft:     0,
+			models.ObjectiveStatusActive:    0,
+			models.ObjectiveStatusCompleted: 0,
+			models.ObjectiveStatusArchived:  0,
+			models.ObjectiveStatusOnHold:    0,
+		},
+		KeyResultsByStatus: map[models.KeyResultProgressStatus]int64{
+			models.StatusNotStarted: 0,
+			models.StatusInProgress: 0,
+			models.StatusRisk:       0,
+			models.StatusBehind:     0,
+			models.StatusCompleted:  0,
+		},
+	}
+
+	objectiveCounts, err := s.repo.ObjectivesByStatus(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count objectives: %w", err)
+	}
+	for _, count := range objectiveCounts {
+		dashboard.ObjectivesByStatus[models.ObjectiveStatus(count.Status)] += count.Count
+	}
+
+	keyResultCounts, err := s.repo.KeyResultsByStatus(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count key results: %w", err)
+	}
+	for _, count := range keyResultCounts {
+		dashboard.KeyResultsByStatus[models.KeyResultProgressStatus(count.Status)] += count.Count
+	}
+
+	teams, err := s.repo.ProgressByTeam(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to average team progress: %w", err)
+	}
+	dashboard.TeamProgress = make([]dto.TeamProgressResponse, len(teams))
+	for i, team := range teams {
+		dashboard.TeamProgress[i] = dto.TeamProgressResponse{
+			TeamID:          team.TeamID,
+			TeamName:        team.TeamName,
+			Objectives:      team.Objectives,
+			AverageProgress: roundProgress(team.AverageProgress),
+		}
+	}
+
+	atRisk, err := s.repo.AtRiskKeyResults(ctx, filter, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list at-risk key results: %w", err)
+	}
+	dashboard.AtRiskKeyResults = make([]dto.AtRiskKeyResultResponse, len(atRisk))
+	for i, kr := range atRisk {
+		dashboard.AtRiskKeyResults[i] = dto.AtRiskKeyResultResponse{
+			ID:             kr.ID,
+			Title:          kr.Title,
+			ObjectiveID:    kr.ObjectiveID,
+			ObjectiveTitle: kr.ObjectiveTitle,
+			Status:         kr.Status,
+			Progress:       roundProgress(kr.Progress),
+			AssigneeType:   kr.AssigneeType,
+			AssigneeID:     kr.AssigneeID,
+			DueDate:        kr.DueDate,
+		}
+	}
+
+	freshness, err := s.repo.KeyResultFreshness(ctx, filter, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure check-in freshness: %w", err)
+	}
+	dashboard.CheckInFreshness = dto.CheckInFreshnessResponse{
+		UpdatedWithinWeek:  freshness.UpdatedWithinWeek,
+		UpdatedWithinMonth: freshness.UpdatedWithinMonth,
+		Stale:              freshness.Stale,
+		LastCheckInAt:      freshness.LastUpdatedAt,
+	}
+
+	buckets, err := s.repo.ProgressDistribution(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute progress distribution: %w", err)
+	}
+	dashboard.ProgressDistribution = make([]dto.ProgressBucketResponse, len(progressBucketRanges))
+	for i, name := range progressBucketRanges {
+		dashboard.ProgressDistribution[i].Range = name
+	}
+	for _, bucket := range buckets {
+		if bucket.Bucket >= 0 && bucket.Bucket < len(progressBucketRanges) {
+			dashboard.ProgressDistribution[bucket.Bucket].Count = bucket.Count
+		}
+	}
+
+	return dashboard, nil
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+	"github.com/xuri/excelize/v2"
+)
+
+// ExportFormat is the file format of an export
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatXLSX ExportFormat = "xlsx"
+	ExportFormatJSON ExportFormat = "json"
+)
+
+func ParseExportFormat(s string) (ExportFormat, bool) {
+	switch format := ExportFormat(strings.ToLower(strings.TrimSpace(s))); format {
+	case ExportFormatCSV, ExportFormatXLSX, ExportFormatJSON:
+		return format, true
+	}
+	return "", false
+}
+
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportFormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case ExportFormatJSON:
+		return "application/json"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// exportColumns are the columns of CSV and XLSX exports. Each row is a key
+// result with its objective; an objective without key results gets a row of
+// its own with the key result columns blank.
+var exportColumns = []string{
+	"objective_id", "title", "type", "status", "progress", "owner", "owner_email", "team", "start_date", "end_date",
+	"kr_id", "kr_title", "kr_metric_type", "kr_current_value", "kr_target_value",
+	"kr_start_value", "kr_direction", "kr_range_min", "kr_range_max", "kr_currency", "kr_progress", "kr_status",
+	"kr_assignee_type", "kr_assignee", "kr_start_date", "kr_due_date",
+}
+
+// exportEncoder writes exported objectives in one format. Flush pushes what
+// has been encoded so far to the underlying writer, where the format allows.
+// Close completes the file; Discard releases the encoder after a failure
+// and leaves the output truncated.
+type exportEncoder interface {
+	Encode(objective dto.ExportObjective) error
+	Flush() error
+	Close() error
+	Discard()
+}
+
+func newExportEncoder(format ExportFormat, w io.Writer, exportedAt time.Time) (exportEncoder, error) {
+	switch format {
+	case ExportFormatXLSX:
+		return newXLSXExportEncoder(w)
+	case ExportFormatJSON:
+		return newJSONExportEncoder(w, exportedAt)
+	default:
+		return newCSVExportEncoder(w)
+	}
+}
+
+// exportRows flattens an objective into the rows of exportColumns
+func exportRows(o dto.ExportObjective) [][]any {
+	objective := []any{
+		o.ID, o.Title, string(o.Type), string(o.Status), roundProgress(o.Progress), o.OwnerName, o.OwnerEmail, o.TeamName,
+		exportDate(o.StartDate), exportDate(o.EndDate),
+	}
+	if len(o.KeyResults) == 0 {
+		return [][]any{append(objective, make([]any, len(exportColumns)-len(objective))...)}
+	}
+
+	rows := make([][]any, 0, len(o.KeyResults))
+	for _, kr := range o.KeyResults {
+		row := append(append(make([]any, 0, len(exportColumns)), objective...),
+			kr.ID, kr.Title, string(kr.MetricType), kr.CurrentValue, kr.TargetValue,
+			kr.StartValue, string(kr.Direction), kr.RangeMin, kr.RangeMax, kr.Currency, roundProgress(kr.Progress), string(kr.Status),
+			string(kr.AssigneeType), kr.AssigneeName, exportDate(kr.StartDate), exportDate(kr.DueDate),
+		)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func exportDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func roundProgress(progress float64) float64 {
+	return float64(int64(progress*100+0.5)) / 100
+}
+
+// spreadsheetText keeps a user-supplied value from being read as a formula
+// when the sheet is opened: text starting with one of the characters that
+// begin a formula is prefixed with a quote
+func spreadsheetText(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+type csvExportEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVExportEncoder(w io.Writer) (*csvExportEncoder, error) {
+	enc := &csvExportEncoder{w: csv.NewWriter(w)}
+	if err := enc.w.Write(exportColumns); err != nil {
+		return nil, err
+	}
+	return enc, nil
+}
+
+func (e *csvExportEncoder) Encode(objective dto.ExportObjective) error {
+	for _, row := range exportRows(objective) {
+		record := make([]string, len(row))
+		for i, value := range row {
+			switch v := value.(type) {
+			case nil:
+			case float64:
+				record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+			case string:
+				record[i] = spreadsheetText(v)
+			default:
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		if err := e.w.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *csvExportEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExportEncoder) Close() error {
+	return e.Flush()
+}
+
+func (e *csvExportEncoder) Discard() {}
+
+// xlsxExportEncoder streams rows into the sheet. The workbook is a zip
+// file, so nothing reaches w until Close.
+type xlsxExportEncoder struct {
+	w     io.Writer
+	file  *excelize.File
+	sheet *excelize.StreamWriter
+	row   int
+}
+
+const exportSheet = "OKRs"
+
+func newXLSXExportEncoder(w io.Writer) (*xlsxExportEncoder, error) {
+	file := excelize.NewFile()
+	if err := file.SetSheetName("Sheet1", exportSheet); err != nil {
+		file.Close()
+		return nil, err
+	}
+	sheet, err := file.NewStreamWriter(exportSheet)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	enc := &xlsxExportEncoder{w: w, file: file, sheet: sheet, row: 1}
+
+	bold, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := sheet.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	header := make([]any, len(exportColumns))
+	for i, column := range exportColumns {
+		header[i] = column
+	}
+	if err := sheet.SetRow("A1", header, excelize.RowOpts{StyleID: bold}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return enc, nil
+}
+
+func (e *xlsxExportEncoder) Encode(objective dto.ExportObjective) error {
+	for _, row := range exportRows(objective) {
+		e.row++
+		cell, err := excelize.CoordinatesToCellName(1, e.row)
+		if err != nil {
+			return err
+		}
+		for i, value := range row {
+			if text, ok := value.(string); ok {
+				row[i] = spreadsheetText(text)
+			}
+		}
+		if err := e.sheet.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *xlsxExportEncoder) Flush() error {
+	return nil
+}
+
+func (e *xlsxExportEncoder) Close() error {
+	defer e.file.Close()
+
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+	return e.file.Write(e.w)
+}
+
+// Discard removes the temporary files of a large sheet
+func (e *xlsxExportEncoder) Discard() {
+	e.file.Close()
+}
+
+// jsonExportEncoder writes {"exported_at": ..., "objectives": [...]} one
+// objective at a time
+type jsonExportEncoder struct {
+	w     io.Writer
+	enc   *json.Encoder
+	count int
+}
+
+func newJSONExportEncoder(w io.Writer, exportedAt time.Time) (*jsonExportEncoder, error) {
+	stamp, err := json.Marshal(exportedAt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(w, `{"exported_at":%s,"objectives":[`, stamp); err != nil {
+		return nil, err
+	}
+	return &jsonExportEncoder{w: w, enc: json.NewEncoder(w)}, nil
+}
+
+func (e *jsonExportEncoder) Encode(objective dto.ExportObjective) error {
+	if e.count > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.count++
+	return e.enc.Encode(objective)
+}
+
+func (e *jsonExportEncoder) Flush() error {
+	return nil
+}
+
+func (e *jsonExportEncoder) Close() error {
+	_, err := io.WriteString(e.w, "]}\n")
+	return err
+}
+
+func (e *jsonExportEncoder) Discard() {}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"github.com/Slightly-Techie/st-okr-api/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize is how many objectives are loaded from the database at a time
+const exportBatchSize = 200
+
+// ExportRequest selects the objectives of a company to export. Empty
+// filters match everything.
+type ExportRequest struct {
+	CompanyID string
+	UserID    string
+	Format    ExportFormat
+	// Team is a team ID or name
+	Team string
+	// Cycle is a calendar period: 2025, 2025-H1 or 2025-Q3. Objectives
+	// whose dates overlap it are exported.
+	Cycle    string
+	Statuses []models.ObjectiveStatus
+}
+
+type ExportService interface {
+	// ExportOKRs checks the request, then writes the export to w batch by
+	// batch. An error returned before anything was written means the
+	// request was rejected; w may be flushed between batches when it has a
+	// Flush method.
+	ExportOKRs(ctx context.Context, req ExportRequest, w io.Writer) error
+}
+
+type exportService struct {
+	repo repositories.ObjectiveRepository
+}
+
+func NewExportService(repo repositories.ObjectiveRepository) ExportService {
+	return &exportService{
+		repo: repo,
+	}
+}
+
+func (s *exportService) ExportOKRs(ctx context.Context, req ExportRequest, w io.Writer) error {
+	db := s.repo.GetDB().WithContext(ctx)
+	if err := checkCompanyAccess(db, req.CompanyID, req.UserID, ErrNotCompanyMember, models.RoleAdmin, models.RoleMember, models.RoleViewer); err != nil {
+		return err
+	}
+
+	filter, teams, err := companyObjectiveFilter(db, req.CompanyID, req.Team, req.Cycle, req.Statuses)
+	if err != nil {
+		return err
+	}
+	teamNames := make(map[string]string, len(teams))
+	for _, team := range teams {
+		teamNames[team.ID] = team.Name
+	}
+
+	enc, err := newExportEncoder(req.Format, w, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.EachByCompany(ctx, filter, exportBatchSize, func(objectives []models.Objective) error {
+		users, err := exportUsers(db, objectives)
+		if err != nil {
+			return err
+		}
+		for _, objective := range objectives {
+			if err := enc.Encode(exportObjective(objective, users, teamNames)); err != nil {
+				return err
+			}
+		}
+		if err := enc.Flush(); err != nil {
+			return err
+		}
+		if f, ok := w.(interface{ Flush() }); ok {
+			f.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		enc.Discard()
+		return err
+	}
+	return enc.Close()
+}
+
+// exportUsers loads the owners and individual assignees of a batch
+func exportUsers(db *gorm.DB, objectives []models.Objective) (map[string]models.User, error) {
+	seen := map[string]bool{}
+	var ids []string
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, objective := range objectives {
+		add(objective.OwnerID)
+		for _, kr := range objective.KeyResults {
+			if kr.AssigneeType == models.AssigneeTypeIndividual {
+				add(kr.AssigneeID)
+			}
+		}
+	}
+
+	var users []models.User
+	if err := db.Select("id", "first_name", "last_name", "user_name", "email").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+	byID := make(map[string]models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+	return byID, nil
+}
+
+func exportObjective(o models.Objective, users map[string]models.User, teamNames map[string]string) dto.ExportObjective {
+	owner := users[o.OwnerID]
+	export := dto.ExportObjective{
+		ID:          o.ID,
+		Title:       o.Title,
+		Description: o.Description,
+		Type:        o.Type,
+		Status:      o.Status,
+		Progress:    o.Progress,
+		OwnerID:     o.OwnerID,
+		OwnerName:   displayName(owner),
+		OwnerEmail:  owner.Email,
+		TeamID:      o.TeamID,
+		StartDate:   o.StartDate,
+		EndDate:     o.EndDate,
+		KeyResults:  make([]dto.ExportKeyResult, len(o.KeyResults)),
+	}
+	if o.TeamID != nil {
+		export.TeamName = teamNames[*o.TeamID]
+	}
+
+	for i, kr := range o.KeyResults {
+		assignee := teamNames[kr.AssigneeID]
+		if kr.AssigneeType == models.AssigneeTypeIndividual {
+			assignee = displayName(users[kr.AssigneeID])
+		}
+		export.KeyResults[i] = dto.ExportKeyResult{
+			ID:           kr.ID,
+			Title:        kr.Title,
+			Description:  kr.Description,
+			MetricType:   kr.MetricType,
+			CurrentValue: kr.CurrentValue,
+			TargetValue:  kr.TargetValue,
+			StartValue:   kr.StartValue,
+			Direction:    kr.Direction,
+			RangeMin:     kr.RangeMin,
+			RangeMax:     kr.RangeMax,
+			Progress:     kr.Progress,
+			Status:       kr.Status,
+			AssigneeType: kr.AssigneeType,
+			AssigneeID:   kr.AssigneeID,
+			AssigneeName: assignee,
+			StartDate:    kr.StartDate,
+			DueDate:      kr.DueDate,
+			Currency:     kr.Currency,
+		}
+	}
+	return export
+}
+
+// displayName is a user's full name, falling back to the user name and then
+// the email
+func displayName(user models.User) string {
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+	if user.UserName != "" {
+		return user.UserName
+	}
+	return user.Email
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// ImportFormat is the encoding of an import document
+type ImportFormat string
+
+const (
+	ImportFormatJSON ImportFormat = "json"
+	ImportFormatYAML ImportFormat = "yaml"
+	ImportFormatCSV  ImportFormat = "csv"
+)
+
+// ParseImportFormat maps a format name or a media type to an ImportFormat
+func ParseImportFormat(s string) (ImportFormat, bool) {
+	mediaType, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(s)), ";")
+	switch strings.TrimSpace(mediaType) {
+	case "json", "application/json":
+		return ImportFormatJSON, true
+	case "yaml", "yml", "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return ImportFormatYAML, true
+	case "csv", "text/csv", "application/csv":
+		return ImportFormatCSV, true
+	}
+	return "", false
+}
+
+// importCSVColumns are the columns of a CSV import. Each line is one key
+// result; consecutive lines with the same title belong to one objective, and
+// the objective columns may be left blank after its first line. A line
+// without a kr_title is an objective with no key results.
+var importCSVColumns = []string{
+	"title", "description", "type", "owner", "team", "start_date", "end_date", "cycle",
+	"kr_title", "kr_description", "kr_metric_type", "kr_current_value", "kr_target_value",
+	"kr_start_value", "kr_direction", "kr_range_min", "kr_range_max", "kr_currency",
+	"kr_assignee_type", "kr_assignee", "kr_start_date", "kr_due_date",
+}
+
+// sourceObjective is an objective of an import document along with where it
+// came from, so problems can be reported against the right row and field
+type sourceObjective struct {
+	dto.ImportObjective
+	row int
+	// keyResultRows is the CSV line of each key result
+	keyResultRows []int
+}
+
+func (o *sourceObjective) field(name string) (int, string) {
+	return o.row, name
+}
+
+func (o *sourceObjective) keyResultField(index int, name string) (int, string) {
+	if o.keyResultRows != nil {
+		return o.keyResultRows[index], "kr_" + name
+	}
+	return o.row, fmt.Sprintf("key_results[%d].%s", index, name)
+}
+
+// parseImport decodes an import document. Malformed documents are rejected
+// outright; values that do not parse, such as a non-numeric target in a CSV,
+// are returned as row errors so that the rest of the document is checked too.
+func parseImport(format ImportFormat, body io.Reader) ([]*sourceObjective, []dto.ImportError, error) {
+	switch format {
+	case ImportFormatCSV:
+		return parseImportCSV(body)
+	case ImportFormatYAML:
+		objectives, err := parseImportYAML(body)
+		return objectives, nil, err
+	default:
+		objectives, err := parseImportJSON(body)
+		return objectives, nil, err
+	}
+}
+
+func parseImportJSON(body io.Reader) ([]*sourceObjective, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Either {"objectives": [...]} or the bare list
+	var doc dto.ImportDocument
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = dec.Decode(&doc.Objectives)
+	} else {
+		err = dec.Decode(&doc)
+	}
+	if err != nil {
+		return nil, apperror.BadRequest(fmt.Sprintf("invalid JSON import document: %v", err))
+	}
+	return sourceObjectives(doc.Objectives), nil
+}
+
+func parseImportYAML(body io.Reader) ([]*sourceObjective, error) {
+	var node yaml.Node
+	if err := yaml.NewDecoder(body).Decode(&node); err != nil && !errors.Is(err, io.EOF) {
+		return nil, apperror.BadRequest(fmt.Sprintf("invalid YAML import document: %v", err))
+	}
+
+	var doc dto.ImportDocument
+	if len(node.Content) > 0 {
+		// Decoding the node again catches misspelt keys, as KnownFields
+		// would on the decoder
+		raw, err := yaml.Marshal(node.Content[0])
+		if err != nil {
+			return nil, err
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(raw))
+		dec.KnownFields(true)
+		if node.Content[0].Kind == yaml.SequenceNode {
+			err = dec.Decode(&doc.Objectives)
+		} else {
+			err = dec.Decode(&doc)
+		}
+		if err != nil {
+			return nil, apperror.BadRequest(fmt.Sprintf("invalid YAML import document: %v", err))
+		}
+	}
+	return sourceObjectives(doc.Objectives), nil
+}
+
+func sourceObjectives(objectives []dto.ImportObjective) []*sourceObjective {
+	sources := make([]*sourceObjective, len(objectives))
+	for i, objective := range objectives {
+		sources[i] = &sourceObjective{ImportObjective: objective, row: i + 1}
+	}
+	return sources
+}
+
+func parseImportCSV(body io.Reader) ([]*sourceObjective, []dto.ImportError, error) {
+	r := csv.NewReader(body)
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, apperror.BadRequest(fmt.Sprintf("invalid CSV import document: %v", err))
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !slices.Contains(importCSVColumns, name) {
+			return nil, nil, apperror.BadRequest(fmt.Sprintf("unknown CSV column %q; expected %s", name, strings.Join(importCSVColumns, ", ")))
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, nil, apperror.BadRequest("CSV import document has no title column")
+	}
+	// Lines may be shorter than the header; missing cells are blank
+	r.FieldsPerRecord = -1
+
+	var objectives []*sourceObjective
+	var rowErrs []dto.ImportError
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, apperror.BadRequest(fmt.Sprintf("invalid CSV import document: %v", err))
+		}
+		line, _ := r.FieldPos(0)
+
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		number := func(name string) float64 {
+			value := cell(name)
+			if value == "" {
+				return 0
+			}
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				rowErrs = append(rowErrs, dto.ImportError{Row: line, Field: name, Message: fmt.Sprintf("%q is not a number", value)})
+			}
+			return n
+		}
+
+		title := cell("title")
+		var objective *sourceObjective
+		if last := len(objectives) - 1; last >= 0 && (title == "" || title == objectives[last].Title) {
+			objective = objectives[last]
+		} else {
+			objective = &sourceObjective{
+				ImportObjective: dto.ImportObjective{
+					Title:       title,
+					Description: cell("description"),
+					Type:        models.ObjectiveType(cell("type")),
+					Owner:       cell("owner"),
+					Team:        cell("team"),
+					StartDate:   cell("start_date"),
+					EndDate:     cell("end_date"),
+					Cycle:       cell("cycle"),
+				},
+				row:           line,
+				keyResultRows: []int{},
+			}
+			objectives = append(objectives, objective)
+		}
+
+		if cell("kr_title") == "" && cell("kr_metric_type") == "" && cell("kr_assignee") == "" {
+			continue
+		}
+		objective.KeyResults = append(objective.KeyResults, dto.ImportKeyResult{
+			Title:        cell("kr_title"),
+			Description:  cell("kr_description"),
+			MetricType:   models.MetricType(cell("kr_metric_type")),
+			CurrentValue: number("kr_current_value"),
+			TargetValue:  number("kr_target_value"),
+			StartValue:   number("kr_start_value"),
+			Direction:    models.MetricDirection(cell("kr_direction")),
+			RangeMin:     number("kr_range_min"),
+			RangeMax:     number("kr_range_max"),
+			Currency:     cell("kr_currency"),
+			AssigneeType: models.AssigneeType(cell("kr_assignee_type")),
+			Assignee:     cell("kr_assignee"),
+			StartDate:    cell("kr_start_date"),
+			DueDate:      cell("kr_due_date"),
+		})
+		objective.keyResultRows = append(objective.keyResultRows, line)
+	}
+
+	return objectives, rowErrs, nil
+}
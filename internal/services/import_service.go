@@ -0,0 +1,454 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"github.com/Slightly-Techie/st-okr-api/internal/repositories"
+	"github.com/Slightly-Techie/st-okr-api/internal/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrImportForbidden = apperror.Forbidden("only active admins and members of the company can import OKRs")
+
+// ImportRequest is a bulk import of objectives and key results into a company
+type ImportRequest struct {
+	CompanyID string
+	// ImporterID is the user running the import. Objectives that name no
+	// owner are owned by them.
+	ImporterID string
+	Format     ImportFormat
+	Body       io.Reader
+	// DryRun checks the document and reports its problems without creating
+	// anything
+	DryRun bool
+	// Language is the Accept-Language used for validation messages
+	Language string
+}
+
+type ImportService interface {
+	ImportOKRs(ctx context.Context, req ImportRequest) (*dto.ImportReport, error)
+}
+
+type importService struct {
+	repo      repositories.ObjectiveRepository
+	validator *validator.Validate
+}
+
+func NewImportService(repo repositories.ObjectiveRepository, validator *validator.Validate) ImportService {
+	validation.KeyResultValidators(validator)
+
+	return &importService{
+		repo:      repo,
+		validator: validator,
+	}
+}
+
+// importDirectory resolves the people, teams and cycles an import refers
+// to. User keys are lower-cased emails and IDs.
+type importDirectory struct {
+	users  map[string]string
+	teams  teamLookup
+	cycles cycleLookup
+	// thresholds are the company's, for the status of new key results
+	thresholds models.StatusThresholds
+	// reportingCurrency is given to currency key results without one
+	reportingCurrency string
+}
+
+// importedObjective is an objective ready to be created
+type importedObjective struct {
+	objective  models.Objective
+	keyResults []models.KeyResult
+}
+
+// importChecker collects the problems of a document, one per field
+type importChecker struct {
+	errs     []dto.ImportError
+	reported map[string]bool
+	language string
+}
+
+func (c *importChecker) add(row int, field, message string) {
+	key := fmt.Sprintf("%d/%s", row, field)
+	if c.reported[key] {
+		return
+	}
+	c.reported[key] = true
+	c.errs = append(c.errs, dto.ImportError{Row: row, Field: field, Message: message})
+}
+
+// addValidation reports validator errors. locate maps a field of the create
+// request to the row and field of the document it came from.
+func (c *importChecker) addValidation(err error, locate func(string) (int, string)) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		row, field := locate("")
+		c.add(row, field, err.Error())
+		return
+	}
+	messages := validation.Translate(validationErrs, c.language)
+	names := make([]string, 0, len(messages))
+	for name := range messages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		row, field := locate(importFieldName(name))
+		c.add(row, field, messages[name])
+	}
+}
+
+// importFieldName maps a field of the create requests to the name it has in
+// an import document
+func importFieldName(name string) string {
+	switch name {
+	case "owner_id":
+		return "owner"
+	case "team_id":
+		return "team"
+	case "assignee_id":
+		return "assignee"
+	}
+	return name
+}
+
+func (s *importService) ImportOKRs(ctx context.Context, req ImportRequest) (*dto.ImportReport, error) {
+	sources, rowErrs, err := parseImport(req.Format, req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, apperror.BadRequest("import document has no objectives")
+	}
+
+	db := s.repo.GetDB().WithContext(ctx)
+	// Viewers cannot create OKRs
+	if err := checkCompanyAccess(db, req.CompanyID, req.ImporterID, ErrImportForbidden, models.RoleAdmin, models.RoleMember); err != nil {
+		return nil, err
+	}
+	dir, err := s.loadDirectory(db, req.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := &importChecker{reported: map[string]bool{}, language: req.Language}
+	for _, rowErr := range rowErrs {
+		checker.add(rowErr.Row, rowErr.Field, rowErr.Message)
+	}
+
+	report := &dto.ImportReport{DryRun: req.DryRun}
+	imported := make([]importedObjective, 0, len(sources))
+	for _, source := range sources {
+		item := s.buildObjective(source, req, dir, checker)
+		imported = append(imported, item)
+		report.Objectives++
+		report.KeyResults += len(item.keyResults)
+	}
+
+	if len(checker.errs) > 0 {
+		sort.SliceStable(checker.errs, func(i, j int) bool { return checker.errs[i].Row < checker.errs[j].Row })
+		report.Errors = checker.errs
+		if req.DryRun {
+			return report, nil
+		}
+
+		details := make(map[string]string, len(checker.errs))
+		for _, e := range checker.errs {
+			details[fmt.Sprintf("row %d: %s", e.Row, e.Field)] = e.Message
+		}
+		return nil, apperror.Validation("the import document has errors; nothing was imported", details)
+	}
+	if req.DryRun {
+		return report, nil
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range imported {
+			if err := tx.Omit(clause.Associations).Create(&item.objective).Error; err != nil {
+				return fmt.Errorf("failed to create objective %q: %w", item.objective.Title, err)
+			}
+			if len(item.keyResults) == 0 {
+				continue
+			}
+			if err := tx.Create(&item.keyResults).Error; err != nil {
+				return fmt.Errorf("failed to create key results of objective %q: %w", item.objective.Title, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.ObjectiveIDs = make([]string, len(imported))
+	for i, item := range imported {
+		report.ObjectiveIDs[i] = item.objective.ID
+	}
+	return report, nil
+}
+
+func (s *importService) loadDirectory(db *gorm.DB, companyID string) (*importDirectory, error) {
+	var members []struct {
+		ID    string
+		Email string
+	}
+	err := db.Table("users").
+		Select("users.id, users.email").
+		Joins("JOIN memberships ON memberships.user_id = users.id").
+		// Inactive and removed members cannot own or be assigned OKRs
+		Where("memberships.company_id = ? AND memberships.status = ?", companyID, models.StatusActive).
+		Scan(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load company members: %w", err)
+	}
+
+	var teams []models.Team
+	if err := db.Where("company_id = ?", companyID).Find(&teams).Error; err != nil {
+		return nil, fmt.Errorf("failed to load company teams: %w", err)
+	}
+
+	var cycles []models.Cycle
+	if err := db.Where("company_id = ?", companyID).Find(&cycles).Error; err != nil {
+		return nil, fmt.Errorf("failed to load company cycles: %w", err)
+	}
+
+	var company models.Company
+	if err := db.Select("at_risk_threshold", "behind_threshold", "reporting_currency").Where("id = ?", companyID).First(&company).Error; err != nil {
+		return nil, fmt.Errorf("failed to load company: %w", err)
+	}
+
+	dir := &importDirectory{
+		users:             make(map[string]string, 2*len(members)),
+		teams:             newTeamLookup(teams),
+		cycles:            newCycleLookup(cycles),
+		thresholds:        company.StatusThresholds(),
+		reportingCurrency: company.ReportingCurrency,
+	}
+	for _, member := range members {
+		dir.users[strings.ToLower(member.ID)] = member.ID
+		dir.users[strings.ToLower(member.Email)] = member.ID
+	}
+	return dir, nil
+}
+
+func (d *importDirectory) user(ref string) (string, error) {
+	if id, ok := d.users[strings.ToLower(strings.TrimSpace(ref))]; ok {
+		return id, nil
+	}
+	return "", fmt.Errorf("%q is not an active member of the company", ref)
+}
+
+func (s *importService) buildObjective(source *sourceObjective, req ImportRequest, dir *importDirectory, checker *importChecker) importedObjective {
+	createReq := dto.CreateObjectiveRequest{
+		Title:       source.Title,
+		Description: source.Description,
+		Type:        source.Type,
+		OwnerID:     req.ImporterID,
+		CompanyID:   req.CompanyID,
+	}
+	if createReq.Type == "" {
+		createReq.Type = models.ObjectiveTypeTeam
+		if source.Team == "" {
+			createReq.Type = models.ObjectiveTypeCompany
+		}
+	}
+
+	if source.Owner != "" {
+		if id, err := dir.user(source.Owner); err != nil {
+			checker.add(source.row, "owner", err.Error())
+		} else {
+			createReq.OwnerID = id
+		}
+	}
+	if source.Team != "" {
+		if id, err := dir.teams.resolve(source.Team); err != nil {
+			checker.add(source.row, "team", err.Error())
+		} else {
+			createReq.TeamID = &id
+		}
+	} else if createReq.Type == models.ObjectiveTypeTeam {
+		checker.add(source.row, "team", "a team objective needs a team")
+	}
+
+	createReq.StartDate = parseImportDate(source.StartDate, func(message string) { checker.add(source.row, "start_date", message) })
+	createReq.EndDate = parseImportDate(source.EndDate, func(message string) { checker.add(source.row, "end_date", message) })
+	if source.Cycle != "" {
+		if from, to, err := dir.cycles.resolve(source.Cycle); err != nil {
+			checker.add(source.row, "cycle", err.Error())
+		} else {
+			// Dates given alongside the cycle win; the end date is the
+			// cycle's last day
+			if source.StartDate == "" {
+				createReq.StartDate = from
+			}
+			if source.EndDate == "" {
+				createReq.EndDate = to.AddDate(0, 0, -1)
+			}
+		}
+	}
+
+	if err := s.validator.Struct(createReq); err != nil {
+		checker.addValidation(err, source.field)
+	}
+
+	now := time.Now()
+	item := importedObjective{
+		objective: models.Objective{
+			ID:          uuid.NewString(),
+			Title:       createReq.Title,
+			Description: createReq.Description,
+			Type:        createReq.Type,
+			OwnerID:     createReq.OwnerID,
+			CompanyID:   createReq.CompanyID,
+			TeamID:      createReq.TeamID,
+			Status:      models.ObjectiveStatusDraft,
+			StartDate:   createReq.StartDate,
+			EndDate:     createReq.EndDate,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+	}
+
+	for i, kr := range source.KeyResults {
+		locate := func(name string) (int, string) { return source.keyResultField(i, name) }
+		keyResult := s.buildKeyResult(kr, item.objective.ID, dir, checker, locate)
+		item.keyResults = append(item.keyResults, keyResult)
+	}
+
+	// Progress is worked out the same way UpdateObjectiveProgress does, but
+	// the key results are created separately
+	item.objective.KeyResults = item.keyResults
+	item.objective.UpdateProgress()
+	item.objective.KeyResults = nil
+	return item
+}
+
+func (s *importService) buildKeyResult(kr dto.ImportKeyResult, objectiveID string, dir *importDirectory, checker *importChecker, locate func(string) (int, string)) models.KeyResult {
+	report := func(field, message string) {
+		row, name := locate(field)
+		checker.add(row, name, message)
+	}
+
+	createReq := dto.CreateKeyResultRequest{
+		ObjectiveID:  objectiveID,
+		Title:        kr.Title,
+		Description:  kr.Description,
+		MetricType:   kr.MetricType,
+		CurrentValue: kr.CurrentValue,
+		TargetValue:  kr.TargetValue,
+		StartValue:   kr.StartValue,
+		Direction:    kr.Direction,
+		RangeMin:     kr.RangeMin,
+		RangeMax:     kr.RangeMax,
+		AssigneeType: kr.AssigneeType,
+		Currency:     kr.Currency,
+	}
+
+	if kr.Assignee != "" {
+		var id string
+		var err error
+		switch createReq.AssigneeType {
+		case models.AssigneeTypeIndividual:
+			id, err = dir.user(kr.Assignee)
+		case models.AssigneeTypeTeam:
+			id, err = dir.teams.resolve(kr.Assignee)
+		case "":
+			// An email or a member's ID is a person; anything else a team.
+			// The type is set even when the lookup fails so that only the
+			// assignee is reported.
+			createReq.AssigneeType = models.AssigneeTypeIndividual
+			if id, err = dir.user(kr.Assignee); err != nil && !strings.Contains(kr.Assignee, "@") {
+				createReq.AssigneeType = models.AssigneeTypeTeam
+				id, err = dir.teams.resolve(kr.Assignee)
+			}
+		}
+		if err != nil {
+			report("assignee", err.Error())
+		}
+		createReq.AssigneeID = id
+	}
+
+	createReq.StartDate = parseImportDate(kr.StartDate, func(message string) { report("start_date", message) })
+	createReq.DueDate = parseImportDate(kr.DueDate, func(message string) { report("due_date", message) })
+
+	if err := s.validator.Struct(createReq); err != nil {
+		checker.addValidation(err, locate)
+	}
+
+	keyResult := models.KeyResult{
+		ID:           uuid.NewString(),
+		ObjectiveID:  objectiveID,
+		Title:        createReq.Title,
+		Description:  createReq.Description,
+		MetricType:   createReq.MetricType,
+		CurrentValue: createReq.CurrentValue,
+		TargetValue:  createReq.TargetValue,
+		StartValue:   createReq.StartValue,
+		Direction:    createReq.Direction,
+		RangeMin:     createReq.RangeMin,
+		RangeMax:     createReq.RangeMax,
+		AssigneeType: createReq.AssigneeType,
+		AssigneeID:   createReq.AssigneeID,
+		StartDate:    createReq.StartDate,
+		DueDate:      createReq.DueDate,
+		Currency:     createReq.Currency,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	// The objective is not created yet, so defaultCurrency cannot find the
+	// company through it
+	if keyResult.MetricType == models.MetrictTypeCurrency && keyResult.Currency == "" {
+		keyResult.Currency = dir.reportingCurrency
+	}
+
+	err := setCurrencyAmounts(&keyResult, "", "", "")
+	keyResult.SetDirection()
+	if err == nil {
+		err = validation.ValidateMetricValues(&keyResult)
+	}
+	if err != nil {
+		field := "target_value"
+		if appErr, ok := apperror.As(err); ok {
+			for name := range appErr.Details {
+				field = name
+			}
+		}
+		report(field, err.Error())
+	}
+
+	keyResult.UpdateProgress()
+	keyResult.UpdateStatus(dir.thresholds, time.Now())
+	return keyResult
+}
+
+// importDateLayouts are the date formats an import accepts
+var importDateLayouts = []string{time.RFC3339, "2006-01-02"}
+
+// parseImportDate parses a date of an import document. An empty value is
+// the zero time, which the validator reports as missing.
+func parseImportDate(value string, report func(message string)) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range importDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	report(fmt.Sprintf("%q is not a date; use YYYY-MM-DD or RFC 3339", value))
+	return time.Time{}
+}
@@ -1,10 +1,12 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
 	"github.com/Slightly-Techie/st-okr-api/internal/models"
 	"github.com/Slightly-Techie/st-okr-api/internal/repositories"
 	"github.com/Slightly-Techie/st-okr-api/internal/validation"
@@ -14,28 +16,38 @@ import (
 )
 
 type KeyResultService interface {
-	CreateKeyResult(req dto.CreateKeyResultRequest) (*models.KeyResult, error)
-	GetData(identifier, id string) (*models.KeyResult, error)
-	UpdateKeyResult(req dto.UpdateKeyResultRequest) (*models.KeyResult, error)
-	DeleteKeyResult(id string) error
-	ListData(identifier, id string) ([]models.KeyResult, error)
+	CreateKeyResult(ctx context.Context, req dto.CreateKeyResultRequest) (*models.KeyResult, error)
+	GetData(ctx context.Context, identifier, id string) (*models.KeyResult, error)
+	UpdateKeyResult(ctx context.Context, req dto.UpdateKeyResultRequest) (*models.KeyResult, error)
+	DeleteKeyResult(ctx context.Context, id string) error
+	ListData(ctx context.Context, identifier, id string) ([]models.KeyResult, error)
+	RefreshStatuses(ctx context.Context) error
 }
 
+// forecastWindow is how far back the check-in trend behind a projected
+// completion date reaches
+const forecastWindow = 28 * day
+
+// forecastBatchSize bounds the key results refreshed per batch
+const forecastBatchSize = 500
+
 type keyResultService struct {
 	repo      repositories.KeyResultRepository
+	snapshots repositories.ProgressSnapshotRepository
 	validator *validator.Validate
 }
 
-func NewKeyResultService(repo repositories.KeyResultRepository, validator *validator.Validate) KeyResultService {
+func NewKeyResultService(repo repositories.KeyResultRepository, snapshots repositories.ProgressSnapshotRepository, validator *validator.Validate) KeyResultService {
 	validation.KeyResultValidators(validator)
 
 	return &keyResultService{
 		repo:      repo,
+		snapshots: snapshots,
 		validator: validator,
 	}
 }
 
-func (k *keyResultService) CreateKeyResult(req dto.CreateKeyResultRequest) (*models.KeyResult, error) {
+func (k *keyResultService) CreateKeyResult(ctx context.Context, req dto.CreateKeyResultRequest) (*models.KeyResult, error) {
 	if err := k.validator.Struct(req); err != nil {
 		return nil, err
 	}
@@ -48,44 +60,65 @@ func (k *keyResultService) CreateKeyResult(req dto.CreateKeyResultRequest) (*mod
 		MetricType:   models.MetricType(req.MetricType),
 		CurrentValue: req.CurrentValue,
 		TargetValue:  req.TargetValue,
+		StartValue:   req.StartValue,
+		Direction:    req.Direction,
+		RangeMin:     req.RangeMin,
+		RangeMax:     req.RangeMax,
 		AssigneeType: models.AssigneeType(req.AssigneeType),
 		AssigneeID:   req.AssigneeID,
 		StartDate:    req.StartDate,
 		DueDate:      req.DueDate,
+		Currency:     req.Currency,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
+	if err := defaultCurrency(k.repo.GetDB().WithContext(ctx), &data); err != nil {
+		return nil, err
+	}
 
+	if err := setCurrencyAmounts(&data, req.CurrentAmount, req.TargetAmount, req.StartAmount); err != nil {
+		return nil, err
+	}
+	data.SetDirection()
 	if err := validation.ValidateMetricValues(&data); err != nil {
 		return nil, err
 	}
 
 	data.UpdateProgress()
-	data.UpdateStatus()
+	if err := k.forecast(ctx, &data, time.Now()); err != nil {
+		return nil, err
+	}
 
-	created, err := k.repo.Create(&data)
+	created, err := k.repo.Create(ctx, &data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Key Result: %w", err)
 	}
+	k.recordSnapshot(ctx, created)
 
+	if err := formatAmounts(k.repo.GetDB().WithContext(ctx), created); err != nil {
+		return nil, err
+	}
 	return created, nil
 }
 
-func (k *keyResultService) GetData(identifier, id string) (*models.KeyResult, error) {
-	res, err := k.repo.GetByIdentifier(identifier, id)
+func (k *keyResultService) GetData(ctx context.Context, identifier, id string) (*models.KeyResult, error) {
+	res, err := k.repo.GetByIdentifier(ctx, identifier, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get data: %v", err)
+		return nil, fmt.Errorf("failed to get data: %w", err)
 	}
 
+	if err := formatAmounts(k.repo.GetDB().WithContext(ctx), res); err != nil {
+		return nil, err
+	}
 	return res, nil
 }
 
-func (k *keyResultService) UpdateKeyResult(req dto.UpdateKeyResultRequest) (*models.KeyResult, error) {
+func (k *keyResultService) UpdateKeyResult(ctx context.Context, req dto.UpdateKeyResultRequest) (*models.KeyResult, error) {
 	if err := k.validator.Struct(req); err != nil {
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
 
-	existing, err := k.repo.GetByIdentifier("id", req.ID)
+	existing, err := k.repo.GetByIdentifier(ctx, "id", req.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find key result: %w", err)
 	}
@@ -94,47 +127,177 @@ func (k *keyResultService) UpdateKeyResult(req dto.UpdateKeyResultRequest) (*mod
 	existing.Description = req.Description
 	existing.CurrentValue = req.CurrentValue
 	existing.TargetValue = req.TargetValue
+	existing.StartValue = req.StartValue
+	existing.Direction = req.Direction
+	existing.RangeMin = req.RangeMin
+	existing.RangeMax = req.RangeMax
 	existing.MetricType = req.MetricType
 	existing.AssigneeType = req.AssigneeType
 	existing.AssigneeID = req.AssigneeID
 	existing.StartDate = req.StartDate
 	existing.DueDate = req.DueDate
+	if req.Currency != "" {
+		existing.Currency = req.Currency
+	}
+	if err := defaultCurrency(k.repo.GetDB().WithContext(ctx), existing); err != nil {
+		return nil, err
+	}
 
+	if err := setCurrencyAmounts(existing, req.CurrentAmount, req.TargetAmount, req.StartAmount); err != nil {
+		return nil, err
+	}
+	existing.SetDirection()
 	if err := validation.ValidateMetricValues(existing); err != nil {
 		return nil, err
 	}
 
 	existing.UpdateProgress()
-	existing.UpdateStatus()
+	if err := k.forecast(ctx, existing, time.Now()); err != nil {
+		return nil, err
+	}
 
-	updatedData, err := k.repo.Update(existing)
+	updatedData, err := k.repo.Update(ctx, existing)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update key Result: %v", err)
+		return nil, fmt.Errorf("failed to update key Result: %w", err)
 	}
+	k.recordSnapshot(ctx, updatedData)
 
+	if err := formatAmounts(k.repo.GetDB().WithContext(ctx), updatedData); err != nil {
+		return nil, err
+	}
 	return updatedData, nil
 }
 
-func (k *keyResultService) DeleteKeyResult(id string) error {
-	if err := k.repo.Delete(id); err != nil {
-		return fmt.Errorf("failed to delete key Result: %v", err)
+func (k *keyResultService) DeleteKeyResult(ctx context.Context, id string) error {
+	if err := k.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete key Result: %w", err)
 	}
 	return nil
 }
 
-func (k *keyResultService) ListData(identifier, objId string) ([]models.KeyResult, error) {
-	keys, err := k.repo.ListByIdentifier(identifier, objId)
+func (k *keyResultService) ListData(ctx context.Context, identifier, objId string) ([]models.KeyResult, error) {
+	keys, err := k.repo.ListByIdentifier(ctx, identifier, objId)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list data: %v", err)
+		return nil, fmt.Errorf("failed to list data: %w", err)
 	}
 
+	refs := make([]*models.KeyResult, len(keys))
+	for i := range keys {
+		refs[i] = &keys[i]
+	}
+	if err := formatAmounts(k.repo.GetDB().WithContext(ctx), refs...); err != nil {
+		return nil, err
+	}
 	return keys, nil
 }
 
-// func (k *keyResultService) ListAssigneeKeyResults(identifier, userId string) (*models.KeyResult, error) {
-// 	assignee, err := k.repo.GetByIdentifier(identifier, userId)
+// RefreshStatuses re-evaluates the status and projected completion of every
+// open key result, since both change as time passes without any check-in
+func (k *keyResultService) RefreshStatuses(ctx context.Context) error {
+	now := time.Now()
+	var updated int
+
+	err := k.repo.EachOpen(ctx, now, forecastBatchSize, func(batch []repositories.OpenKeyResult) error {
+		ids := make([]string, len(batch))
+		for i, kr := range batch {
+			ids[i] = kr.ID
+		}
+		snapshots, err := k.snapshots.ListBySubjects(ctx, models.SnapshotSubjectKeyResult, ids, now.Add(-forecastWindow), now)
+		if err != nil {
+			return err
+		}
+		history := make(map[string][]models.ProgressSnapshot, len(batch))
+		for _, snapshot := range snapshots {
+			history[snapshot.SubjectID] = append(history[snapshot.SubjectID], snapshot)
+		}
+
+		for i := range batch {
+			kr := &batch[i].KeyResult
+			status, projected := kr.Status, kr.ProjectedCompletionAt
+
+			kr.UpdateStatus(batch[i].StatusThresholds(), now)
+			kr.ProjectedCompletionAt = models.ProjectCompletion(forecastSamples(history[kr.ID], kr, now))
+			if kr.Status == status && sameDay(kr.ProjectedCompletionAt, projected) {
+				continue
+			}
+			if err := k.repo.UpdateForecast(ctx, kr); err != nil {
+				return err
+			}
+			updated++
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refresh key result statuses: %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Key result statuses refreshed", "updated", updated)
+	return nil
+}
+
+// forecast sets the status of kr from its company's thresholds and projects
+// its completion from the snapshots before today and its progress now
+func (k *keyResultService) forecast(ctx context.Context, kr *models.KeyResult, now time.Time) error {
+	thresholds, err := k.repo.StatusThresholds(ctx, kr.ObjectiveID)
+	if err != nil {
+		return fmt.Errorf("failed to get status thresholds: %w", err)
+	}
+	kr.UpdateStatus(thresholds, now)
+
+	history, err := k.snapshots.ListBySubject(ctx, models.SnapshotSubjectKeyResult, kr.ID, now.Add(-forecastWindow), now)
+	if err != nil {
+		return fmt.Errorf("failed to get progress history: %w", err)
+	}
+	kr.ProjectedCompletionAt = models.ProjectCompletion(forecastSamples(history, kr, now))
+	return nil
+}
+
+// recordSnapshot files the progress of a key result that has just changed.
+// The change is saved already, so a failure here is only logged; the next
+// scheduled snapshot catches up.
+func (k *keyResultService) recordSnapshot(ctx context.Context, kr *models.KeyResult) {
+	value := kr.CurrentValue
+	err := k.snapshots.Upsert(ctx, []models.ProgressSnapshot{{
+		SubjectType: models.SnapshotSubjectKeyResult,
+		SubjectID:   kr.ID,
+		Day:         models.SnapshotDay(time.Now()),
+		Progress:    kr.Progress,
+		Value:       &value,
+	}})
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to record key result snapshot", "key_result_id", kr.ID, "error", err.Error())
+	}
+}
+
+// forecastSamples is the progress history of kr before today, taken when
+// each day's snapshot was last written, followed by its progress now. A
+// range key result has no completion to project, so it has no samples.
+func forecastSamples(history []models.ProgressSnapshot, kr *models.KeyResult, now time.Time) []models.ProgressSample {
+	if kr.MetricType == models.MetricTypeRange {
+		return nil
+	}
+	today := models.SnapshotDay(now)
+	samples := make([]models.ProgressSample, 0, len(history)+1)
+	for _, snapshot := range history {
+		if !models.SnapshotDay(snapshot.Day).Before(today) {
+			continue
+		}
+		samples = append(samples, models.ProgressSample{At: snapshot.UpdatedAt, Progress: snapshot.Progress})
+	}
+	return append(samples, models.ProgressSample{At: now, Progress: kr.Progress})
+}
+
+func sameDay(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return models.SnapshotDay(*a).Equal(models.SnapshotDay(*b))
+}
+
+// func (k *keyResultService) ListAssigneeKeyResults(ctx context.Context, identifier, userId string) (*models.KeyResult, error) {
+// 	assignee, err := k.repo.GetByIdentifier(ctx, identifier, userId)
 // 	if err != nil {
-// 		return nil, fmt.Errorf("failed to get Assignee's Key Results: %v", err)
+// 		return nil, fmt.Errorf("failed to get Assignee's Key Results: %w", err)
 // 	}
 
 // 	return assignee, nil
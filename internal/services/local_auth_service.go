@@ -0,0 +1,448 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/config"
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
+	"github.com/Slightly-Techie/st-okr-api/internal/message"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	auth "github.com/Slightly-Techie/st-okr-api/pkg"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEmailTaken         = apperror.Conflict("an account with this email already exists")
+	ErrInvalidCredentials = apperror.Unauthorized("invalid email or password")
+	ErrEmailNotVerified   = apperror.Forbidden("email address has not been verified")
+	ErrInvalidToken       = apperror.BadRequest("link is invalid or has expired")
+	ErrInvalidRefresh     = apperror.Unauthorized("refresh token is invalid or has expired")
+)
+
+const (
+	emailVerificationTTL = 24 * time.Hour
+	passwordResetTTL     = time.Hour
+	magicLinkTTL         = 15 * time.Minute
+	authorizationCodeTTL = time.Minute
+)
+
+// Register creates a password account and emails a verification link
+func (s *authService) Register(ctx context.Context, req dto.RegisterRequest) error {
+	log := logger.FromContext(ctx)
+
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+
+	email := normalizeEmail(req.Email)
+
+	if _, err := s.repo.GetByIdentifier(ctx, "LOWER(email) = ?", email); err == nil {
+		log.Warn("Registration rejected - email already in use",
+			"email", maskEmailForLog(email),
+		)
+		return ErrEmailTaken
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	userName := req.UserName
+	if userName == "" {
+		userName = strings.Split(email, "@")[0]
+	}
+
+	user := models.User{
+		ID:           uuid.NewString(),
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		UserName:     userName,
+		Email:        email,
+		PasswordHash: hash,
+	}
+
+	if _, err := s.repo.Create(ctx, &user); err != nil {
+		log.Error("Failed to create password account",
+			"email", maskEmailForLog(email),
+			"error", err.Error(),
+		)
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	log.Info("Password account created",
+		"user_id", user.ID,
+		"email", maskEmailForLog(email),
+	)
+
+	return s.sendLink(ctx, &user, models.AuthTokenEmailVerification, "verify_email", "/auth/verify-email", emailVerificationTTL)
+}
+
+// Login authenticates a password account and issues tokens
+func (s *authService) Login(ctx context.Context, req dto.LoginRequest) (*dto.AuthResponse, error) {
+	log := logger.FromContext(ctx)
+
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetByIdentifier(ctx, "LOWER(email) = ?", normalizeEmail(req.Email))
+	if err != nil || !auth.CheckPassword(user.PasswordHash, req.Password) {
+		log.Warn("Password login failed",
+			"email", maskEmailForLog(req.Email),
+		)
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+
+	if err := s.ensureSSONotEnforced(ctx, user); err != nil {
+		return nil, err
+	}
+
+	log.Info("Password login successful",
+		"user_id", user.ID,
+	)
+
+	return issueTokens(ctx, user)
+}
+
+// VerifyEmail redeems an email verification token
+func (s *authService) VerifyEmail(ctx context.Context, req dto.TokenRequest) error {
+	log := logger.FromContext(ctx)
+
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+
+	user, err := s.redeemToken(ctx, models.AuthTokenEmailVerification, req.Token)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt == nil {
+		if err := s.markEmailVerified(ctx, user); err != nil {
+			return err
+		}
+
+		message.PublishMessage(ctx, "sign_up", map[string]any{
+			"user_name": user.UserName,
+			"email":     user.Email,
+		})
+	}
+
+	log.Info("Email verified",
+		"user_id", user.ID,
+	)
+
+	return nil
+}
+
+// ResendVerification emails a fresh verification link to an unverified account
+func (s *authService) ResendVerification(ctx context.Context, req dto.EmailRequest) error {
+
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+
+	// Do not reveal whether the email is registered
+	user, err := s.repo.GetByIdentifier(ctx, "LOWER(email) = ?", normalizeEmail(req.Email))
+	if err != nil || user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return s.sendLink(ctx, user, models.AuthTokenEmailVerification, "verify_email", "/auth/verify-email", emailVerificationTTL)
+}
+
+// RequestPasswordReset emails a password reset link if the account exists
+func (s *authService) RequestPasswordReset(ctx context.Context, req dto.EmailRequest) error {
+	log := logger.FromContext(ctx)
+
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetByIdentifier(ctx, "LOWER(email) = ?", normalizeEmail(req.Email))
+	if err != nil {
+		log.Info("Password reset requested for unknown email",
+			"email", maskEmailForLog(req.Email),
+		)
+		return nil
+	}
+
+	return s.sendLink(ctx, user, models.AuthTokenPasswordReset, "password_reset", "/auth/reset-password", passwordResetTTL)
+}
+
+// ResetPassword sets a new password using a reset token
+func (s *authService) ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) error {
+	log := logger.FromContext(ctx)
+
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+
+	user, err := s.redeemToken(ctx, models.AuthTokenPasswordReset, req.Token)
+	if err != nil {
+		return err
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	now := time.Now()
+	user.PasswordHash = hash
+	// Receiving the reset email proves ownership of the address
+	if user.EmailVerifiedAt == nil {
+		user.EmailVerifiedAt = &now
+	}
+	// Sign out every session. Token timestamps have second precision, so
+	// tokens issued in the same second as the reset stay valid.
+	revokedAt := now.Truncate(time.Second)
+	user.TokensRevokedAt = &revokedAt
+
+	if _, err := s.repo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err := s.repo.DeleteUnverifiedIdentities(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to remove unverified identities: %w", err)
+	}
+
+	if err := s.repo.RevokeAuthTokens(ctx, user.ID, models.AuthTokenPasswordReset); err != nil {
+		return fmt.Errorf("failed to revoke reset tokens: %w", err)
+	}
+
+	log.Info("Password reset completed",
+		"user_id", user.ID,
+	)
+
+	return nil
+}
+
+// RequestMagicLink emails a one-time sign-in link if the account exists
+func (s *authService) RequestMagicLink(ctx context.Context, req dto.EmailRequest) error {
+	log := logger.FromContext(ctx)
+
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetByIdentifier(ctx, "LOWER(email) = ?", normalizeEmail(req.Email))
+	if err != nil {
+		log.Info("Magic link requested for unknown email",
+			"email", maskEmailForLog(req.Email),
+		)
+		return nil
+	}
+
+	return s.sendLink(ctx, user, models.AuthTokenMagicLink, "magic_link", "/auth/magic-link", magicLinkTTL)
+}
+
+// LoginWithMagicLink redeems a magic link token and issues tokens
+func (s *authService) LoginWithMagicLink(ctx context.Context, req dto.TokenRequest) (*dto.AuthResponse, error) {
+	log := logger.FromContext(ctx)
+
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.redeemToken(ctx, models.AuthTokenMagicLink, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureSSONotEnforced(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if user.EmailVerifiedAt == nil {
+		if err := s.markEmailVerified(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Info("Magic link login successful",
+		"user_id", user.ID,
+	)
+
+	return issueTokens(ctx, user)
+}
+
+// Refresh exchanges a valid refresh token for a new token pair
+func (s *authService) Refresh(ctx context.Context, req dto.RefreshTokenRequest) (*dto.AuthResponse, error) {
+	log := logger.FromContext(ctx)
+
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	claims, err := auth.ParseToken(req.RefreshToken, auth.RefreshToken)
+	if err != nil {
+		log.Warn("Refresh token rejected",
+			"error", err.Error(),
+		)
+		return nil, ErrInvalidRefresh
+	}
+
+	user, err := s.repo.GetByIdentifier(ctx, "id = ?", claims.Subject)
+	if err != nil {
+		return nil, ErrInvalidRefresh
+	}
+
+	if user.TokensRevokedAt != nil && (claims.IssuedAt == nil || claims.IssuedAt.Before(*user.TokensRevokedAt)) {
+		log.Warn("Revoked refresh token rejected",
+			"user_id", user.ID,
+		)
+		return nil, ErrInvalidRefresh
+	}
+
+	if err := s.ensureSSONotEnforced(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return issueTokens(ctx, user)
+}
+
+// IssueAuthorizationCode creates a short-lived one-time code that the frontend
+// exchanges for tokens after a redirect-based login, so tokens never appear in
+// URLs or cookies
+func (s *authService) IssueAuthorizationCode(ctx context.Context, userID string) (string, error) {
+	log := logger.FromContext(ctx)
+
+	code, hash, err := auth.GenerateToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate authorization code: %w", err)
+	}
+
+	_, err = s.repo.CreateAuthToken(ctx, &models.AuthToken{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Purpose:   models.AuthTokenAuthorizationCode,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(authorizationCodeTTL),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store authorization code: %w", err)
+	}
+
+	log.Debug("Authorization code issued",
+		"user_id", userID,
+	)
+
+	return code, nil
+}
+
+// ExchangeAuthorizationCode redeems an authorization code and issues tokens
+func (s *authService) ExchangeAuthorizationCode(ctx context.Context, req dto.AuthorizationCodeRequest) (*dto.AuthResponse, error) {
+	log := logger.FromContext(ctx)
+
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.redeemToken(ctx, models.AuthTokenAuthorizationCode, req.Code)
+	if err != nil {
+		log.Warn("Authorization code rejected")
+		return nil, err
+	}
+
+	log.Info("Authorization code exchanged",
+		"user_id", user.ID,
+	)
+
+	return issueTokens(ctx, user)
+}
+
+// sendLink stores a new one-time token and queues the email carrying it
+func (s *authService) sendLink(ctx context.Context, user *models.User, purpose models.AuthTokenPurpose, eventType, path string, ttl time.Duration) error {
+	log := logger.FromContext(ctx)
+
+	if err := s.repo.RevokeAuthTokens(ctx, user.ID, purpose); err != nil {
+		return fmt.Errorf("failed to revoke previous tokens: %w", err)
+	}
+
+	token, hash, err := auth.GenerateToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	_, err = s.repo.CreateAuthToken(ctx, &models.AuthToken{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store token: %w", err)
+	}
+
+	link := fmt.Sprintf("%s%s?token=%s", strings.TrimSuffix(config.ENV.Server.FrontendURL, "/"), path, url.QueryEscape(token))
+
+	err = message.PublishMessage(ctx, eventType, map[string]any{
+		"user_name":  user.UserName,
+		"email":      user.Email,
+		"link":       link,
+		"expires_in": ttl.String(),
+	})
+	if err != nil {
+		log.Error("Failed to queue auth email",
+			"user_id", user.ID,
+			"event_type", eventType,
+			"error", err.Error(),
+		)
+		return fmt.Errorf("failed to queue email: %w", err)
+	}
+
+	log.Info("Auth email queued",
+		"user_id", user.ID,
+		"event_type", eventType,
+	)
+
+	return nil
+}
+
+// redeemToken consumes a one-time token and returns its user
+func (s *authService) redeemToken(ctx context.Context, purpose models.AuthTokenPurpose, token string) (*models.User, error) {
+	authToken, err := s.repo.ConsumeAuthToken(ctx, purpose, auth.HashToken(token))
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.repo.GetByIdentifier(ctx, "id = ?", authToken.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user for token: %w", err)
+	}
+
+	return user, nil
+}
+
+// markEmailVerified records that the owner of the email has proven it and
+// drops the identities linked while anyone could have claimed the address
+func (s *authService) markEmailVerified(ctx context.Context, user *models.User) error {
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+
+	if _, err := s.repo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	if err := s.repo.DeleteUnverifiedIdentities(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to remove unverified identities: %w", err)
+	}
+	return nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
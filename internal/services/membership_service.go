@@ -1,8 +1,10 @@
 package services
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
 	"github.com/Slightly-Techie/st-okr-api/internal/dto"
 	"github.com/Slightly-Techie/st-okr-api/internal/models"
 	"github.com/Slightly-Techie/st-okr-api/internal/repositories"
@@ -11,13 +13,13 @@ import (
 )
 
 type MembershipService interface {
-	CreateMembership(r dto.CreateMembershipRequest) (*models.Membership, error)
-	GetMembership(ident, id string) (*models.Membership, error)
-	DeleteMembership(id string) error
-	UpdateMembership(r dto.UpdateMembershipRequest) (*models.Membership, error)
-	GetCompanyMembers(companyID string) ([]models.Membership, error)
-	UpdateMembershipRole(id string, role models.RoleType) error
-	UpdateMembershipStatus(id string, status models.StatusType) error
+	CreateMembership(ctx context.Context, r dto.CreateMembershipRequest) (*models.Membership, error)
+	GetMembership(ctx context.Context, ident, id string) (*models.Membership, error)
+	DeleteMembership(ctx context.Context, id string) error
+	UpdateMembership(ctx context.Context, r dto.UpdateMembershipRequest) (*models.Membership, error)
+	GetCompanyMembers(ctx context.Context, companyID string) ([]models.Membership, error)
+	UpdateMembershipRole(ctx context.Context, id string, role models.RoleType) error
+	UpdateMembershipStatus(ctx context.Context, id string, status models.StatusType) error
 }
 
 type membershipService struct {
@@ -32,7 +34,7 @@ func NewMembershipService(repo repositories.MembershipRepository, validator *val
 	}
 }
 
-func (m *membershipService) CreateMembership(r dto.CreateMembershipRequest) (*models.Membership, error) {
+func (m *membershipService) CreateMembership(ctx context.Context, r dto.CreateMembershipRequest) (*models.Membership, error) {
 	if err := m.validator.Struct(r); err != nil {
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
@@ -45,7 +47,7 @@ func (m *membershipService) CreateMembership(r dto.CreateMembershipRequest) (*mo
 		Status:    models.StatusActive,
 	}
 
-	created, err := m.repo.Create(&membership)
+	created, err := m.repo.Create(ctx, &membership)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create membership: %w", err)
 	}
@@ -53,30 +55,36 @@ func (m *membershipService) CreateMembership(r dto.CreateMembershipRequest) (*mo
 	return created, nil
 }
 
-func (m *membershipService) GetMembership(ident, id string) (*models.Membership, error) {
-	membership, err := m.repo.GetByIdentifier(ident, id)
+func (m *membershipService) GetMembership(ctx context.Context, ident, id string) (*models.Membership, error) {
+	membership, err := m.repo.GetByIdentifier(ctx, ident, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get membership: %w", err)
 	}
 	return membership, nil
 }
 
-func (m *membershipService) UpdateMembership(r dto.UpdateMembershipRequest) (*models.Membership, error) {
+func (m *membershipService) UpdateMembership(ctx context.Context, r dto.UpdateMembershipRequest) (*models.Membership, error) {
 	if err := m.validator.Struct(r); err != nil {
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
 
 	// First get existing membership
-	existing, err := m.repo.GetByIdentifier("id", r.ID)
+	existing, err := m.repo.GetByIdentifier(ctx, "id", r.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find membership: %w", err)
 	}
 
+	if r.Role != models.RoleAdmin || r.Status != models.StatusActive {
+		if err := m.ensureOtherAdmin(ctx, existing, "cannot demote the last admin of the company"); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update fields
 	existing.Role = r.Role
 	existing.Status = r.Status
 
-	updated, err := m.repo.Update(existing)
+	updated, err := m.repo.Update(ctx, existing)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update membership: %w", err)
 	}
@@ -84,36 +92,42 @@ func (m *membershipService) UpdateMembership(r dto.UpdateMembershipRequest) (*mo
 	return updated, nil
 }
 
-func (m *membershipService) DeleteMembership(id string) error {
+func (m *membershipService) DeleteMembership(ctx context.Context, id string) error {
 	// Check if it's the last admin
-	if err := m.validateDeletion(id); err != nil {
+	if err := m.validateDeletion(ctx, id); err != nil {
 		return err
 	}
 
-	if err := m.repo.Delete(id); err != nil {
+	if err := m.repo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete membership: %w", err)
 	}
 
 	return nil
 }
 
-func (m *membershipService) GetCompanyMembers(companyID string) ([]models.Membership, error) {
+func (m *membershipService) GetCompanyMembers(ctx context.Context, companyID string) ([]models.Membership, error) {
 	var memberships []models.Membership
-	result := m.repo.GetDB().Where("company_id = ?", companyID).Find(&memberships)
+	result := m.repo.GetDB().WithContext(ctx).Where("company_id = ?", companyID).Find(&memberships)
 	if result.Error != nil {
 		return nil, fmt.Errorf("failed to get company members: %w", result.Error)
 	}
 	return memberships, nil
 }
 
-func (m *membershipService) UpdateMembershipRole(id string, role models.RoleType) error {
-	membership, err := m.repo.GetByIdentifier("id", id)
+func (m *membershipService) UpdateMembershipRole(ctx context.Context, id string, role models.RoleType) error {
+	membership, err := m.repo.GetByIdentifier(ctx, "id", id)
 	if err != nil {
 		return fmt.Errorf("failed to find membership: %w", err)
 	}
 
+	if role != models.RoleAdmin {
+		if err := m.ensureOtherAdmin(ctx, membership, "cannot demote the last admin of the company"); err != nil {
+			return err
+		}
+	}
+
 	membership.Role = role
-	_, err = m.repo.Update(membership)
+	_, err = m.repo.Update(ctx, membership)
 	if err != nil {
 		return fmt.Errorf("failed to update membership role: %w", err)
 	}
@@ -121,14 +135,20 @@ func (m *membershipService) UpdateMembershipRole(id string, role models.RoleType
 	return nil
 }
 
-func (m *membershipService) UpdateMembershipStatus(id string, status models.StatusType) error {
-	membership, err := m.repo.GetByIdentifier("id", id)
+func (m *membershipService) UpdateMembershipStatus(ctx context.Context, id string, status models.StatusType) error {
+	membership, err := m.repo.GetByIdentifier(ctx, "id", id)
 	if err != nil {
 		return fmt.Errorf("failed to find membership: %w", err)
 	}
 
+	if status != models.StatusActive {
+		if err := m.ensureOtherAdmin(ctx, membership, "cannot deactivate the last admin of the company"); err != nil {
+			return err
+		}
+	}
+
 	membership.Status = status
-	_, err = m.repo.Update(membership)
+	_, err = m.repo.Update(ctx, membership)
 	if err != nil {
 		return fmt.Errorf("failed to update membership status: %w", err)
 	}
@@ -137,22 +157,29 @@ func (m *membershipService) UpdateMembershipStatus(id string, status models.Stat
 }
 
 // validateDeletion checks if the membership can be safely deleted
-func (m *membershipService) validateDeletion(id string) error {
-	membership, err := m.repo.GetByIdentifier("id", id)
+func (m *membershipService) validateDeletion(ctx context.Context, id string) error {
+	membership, err := m.repo.GetByIdentifier(ctx, "id", id)
 	if err != nil {
 		return fmt.Errorf("failed to find membership: %w", err)
 	}
 
-	// If the membership is not an admin, we can safely delete it
+	return m.ensureOtherAdmin(ctx, membership, "cannot delete the last admin of the company")
+}
+
+// ensureOtherAdmin fails with a conflict when membership is an admin and no
+// other active admin would be left in the company once it is removed,
+// demoted or deactivated
+func (m *membershipService) ensureOtherAdmin(ctx context.Context, membership *models.Membership, message string) error {
+	// If the membership is not an admin, the company keeps its admins
 	if membership.Role != models.RoleAdmin {
 		return nil
 	}
 
 	// Count remaining active admins
 	var adminCount int64
-	result := m.repo.GetDB().Model(&models.Membership{}).
+	result := m.repo.GetDB().WithContext(ctx).Model(&models.Membership{}).
 		Where("company_id = ? AND role = ? AND status = ? AND id != ?",
-			membership.CompanyID, models.RoleAdmin, models.StatusActive, id).
+			membership.CompanyID, models.RoleAdmin, models.StatusActive, membership.ID).
 		Count(&adminCount)
 
 	if result.Error != nil {
@@ -160,7 +187,7 @@ func (m *membershipService) validateDeletion(id string) error {
 	}
 
 	if adminCount == 0 {
-		return fmt.Errorf("cannot delete the last admin of the company")
+		return apperror.Conflict(message)
 	}
 
 	return nil
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"github.com/Slightly-Techie/st-okr-api/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// companyObjectiveFilter builds the filter for a company's objectives from
+// request parameters: team is a team ID or name, cycle one of the company's
+// cycles or a calendar period as accepted by parseCycle. It also returns the
+// company's teams.
+func companyObjectiveFilter(db *gorm.DB, companyID, team, cycle string, statuses []models.ObjectiveStatus) (repositories.ObjectiveFilter, []models.Team, error) {
+	filter := repositories.ObjectiveFilter{CompanyID: companyID}
+
+	for _, status := range statuses {
+		if !validObjectiveStatus(status) {
+			return filter, nil, apperror.BadRequest(fmt.Sprintf("unknown objective status %q", status))
+		}
+	}
+	filter.Statuses = statuses
+
+	if cycle != "" {
+		var cycles []models.Cycle
+		if err := db.Where("company_id = ?", companyID).Find(&cycles).Error; err != nil {
+			return filter, nil, fmt.Errorf("failed to load company cycles: %w", err)
+		}
+		from, to, err := newCycleLookup(cycles).resolve(cycle)
+		if err != nil {
+			return filter, nil, apperror.BadRequest(err.Error())
+		}
+		filter.From, filter.To = from, to
+	}
+
+	var teams []models.Team
+	if err := db.Where("company_id = ?", companyID).Find(&teams).Error; err != nil {
+		return filter, nil, fmt.Errorf("failed to load company teams: %w", err)
+	}
+	if team != "" {
+		id, err := newTeamLookup(teams).resolve(team)
+		if err != nil {
+			return filter, nil, apperror.BadRequest(err.Error())
+		}
+		filter.TeamID = id
+	}
+
+	return filter, teams, nil
+}
+
+func validObjectiveStatus(status models.ObjectiveStatus) bool {
+	switch status {
+	case models.ObjectiveStatusDraft, models.ObjectiveStatusActive, models.ObjectiveStatusCompleted,
+		models.ObjectiveStatusArchived, models.ObjectiveStatusOnHold:
+		return true
+	}
+	return false
+}
+
+var cyclePattern = regexp.MustCompile(`^(\d{4})(?:-([QqHh])([1-4]))?$`)
+
+// parseCycle turns 2025, 2025-H2 or 2025-Q3 into the half-open range of
+// dates it covers
+func parseCycle(cycle string) (time.Time, time.Time, error) {
+	invalid := apperror.BadRequest(fmt.Sprintf("invalid cycle %q; use a year, a half or a quarter such as 2025, 2025-H1 or 2025-Q3", cycle))
+
+	m := cyclePattern.FindStringSubmatch(strings.TrimSpace(cycle))
+	if m == nil {
+		return time.Time{}, time.Time{}, invalid
+	}
+	year, _ := strconv.Atoi(m[1])
+	if m[2] == "" {
+		from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		return from, from.AddDate(1, 0, 0), nil
+	}
+
+	n, _ := strconv.Atoi(m[3])
+	months := 3
+	if strings.EqualFold(m[2], "h") {
+		if n > 2 {
+			return time.Time{}, time.Time{}, invalid
+		}
+		months = 6
+	}
+	from := time.Date(year, time.Month(1+(n-1)*months), 1, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(0, months, 0), nil
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -12,15 +13,15 @@ import (
 )
 
 type ObjectiveService interface {
-	CreateObjective(req dto.CreateObjectiveRequest) (*models.Objective, error)
-	GetObjective(identifier, id string) (*models.Objective, error)
-	GetObjectiveWithKeyResults(id string) (*dto.ObjectiveResponse, error)
-	UpdateObjective(req dto.UpdateObjectiveRequest) (*models.Objective, error)
-	DeleteObjective(id string) error
-	ListObjectivesByCompany(companyID string) ([]dto.ObjectiveListResponse, error)
-	ListObjectivesByTeam(teamID string) ([]dto.ObjectiveListResponse, error)
-	ListObjectivesByOwner(ownerID string) ([]dto.ObjectiveListResponse, error)
-	UpdateObjectiveProgress(objectiveID string) error
+	CreateObjective(ctx context.Context, req dto.CreateObjectiveRequest) (*models.Objective, error)
+	GetObjective(ctx context.Context, identifier, id string) (*models.Objective, error)
+	GetObjectiveWithKeyResults(ctx context.Context, id string) (*dto.ObjectiveResponse, error)
+	UpdateObjective(ctx context.Context, req dto.UpdateObjectiveRequest) (*models.Objective, error)
+	DeleteObjective(ctx context.Context, id string) error
+	ListObjectivesByCompany(ctx context.Context, companyID string) ([]dto.ObjectiveListResponse, error)
+	ListObjectivesByTeam(ctx context.Context, teamID string) ([]dto.ObjectiveListResponse, error)
+	ListObjectivesByOwner(ctx context.Context, ownerID string) ([]dto.ObjectiveListResponse, error)
+	UpdateObjectiveProgress(ctx context.Context, objectiveID string) error
 }
 
 type objectiveService struct {
@@ -35,7 +36,7 @@ func NewObjectiveService(repo repositories.ObjectiveRepository, validator *valid
 	}
 }
 
-func (s *objectiveService) CreateObjective(req dto.CreateObjectiveRequest) (*models.Objective, error) {
+func (s *objectiveService) CreateObjective(ctx context.Context, req dto.CreateObjectiveRequest) (*models.Objective, error) {
 	if err := s.validator.Struct(req); err != nil {
 		return nil, err
 	}
@@ -56,7 +57,7 @@ func (s *objectiveService) CreateObjective(req dto.CreateObjectiveRequest) (*mod
 		UpdatedAt:   time.Now(),
 	}
 
-	created, err := s.repo.Create(&objective)
+	created, err := s.repo.Create(ctx, &objective)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create objective: %w", err)
 	}
@@ -64,37 +65,52 @@ func (s *objectiveService) CreateObjective(req dto.CreateObjectiveRequest) (*mod
 	return created, nil
 }
 
-func (s *objectiveService) GetObjective(identifier, id string) (*models.Objective, error) {
-	objective, err := s.repo.GetByIdentifier(identifier, id)
+func (s *objectiveService) GetObjective(ctx context.Context, identifier, id string) (*models.Objective, error) {
+	objective, err := s.repo.GetByIdentifier(ctx, identifier, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get objective: %v", err)
+		return nil, fmt.Errorf("failed to get objective: %w", err)
 	}
 
 	return objective, nil
 }
 
-func (s *objectiveService) GetObjectiveWithKeyResults(id string) (*dto.ObjectiveResponse, error) {
-	objective, err := s.repo.GetWithKeyResults(id)
+func (s *objectiveService) GetObjectiveWithKeyResults(ctx context.Context, id string) (*dto.ObjectiveResponse, error) {
+	objective, err := s.repo.GetWithKeyResults(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get objective with key results: %v", err)
+		return nil, fmt.Errorf("failed to get objective with key results: %w", err)
+	}
+
+	refs := make([]*models.KeyResult, len(objective.KeyResults))
+	for i := range objective.KeyResults {
+		refs[i] = &objective.KeyResults[i]
+	}
+	if err := formatAmounts(s.repo.GetDB().WithContext(ctx), refs...); err != nil {
+		return nil, err
 	}
 
 	keyResults := make([]dto.KeyResultResponse, len(objective.KeyResults))
 	for i, kr := range objective.KeyResults {
 		keyResults[i] = dto.KeyResultResponse{
-			ID:           kr.ID,
-			ObjectiveID:  kr.ObjectiveID,
-			Title:        kr.Title,
-			Description:  kr.Description,
-			AssigneeType: kr.AssigneeType,
-			AssigneeID:   kr.AssigneeID,
-			MetricType:   kr.MetricType,
-			CurrentValue: kr.CurrentValue,
-			TargetValue:  kr.TargetValue,
-			Progress:     kr.Progress,
-			StartDate:    kr.StartDate,
-			DueDate:      kr.DueDate,
-			Status:       kr.Status,
+			ID:               kr.ID,
+			ObjectiveID:      kr.ObjectiveID,
+			Title:            kr.Title,
+			Description:      kr.Description,
+			AssigneeType:     kr.AssigneeType,
+			AssigneeID:       kr.AssigneeID,
+			MetricType:       kr.MetricType,
+			CurrentValue:     kr.CurrentValue,
+			TargetValue:      kr.TargetValue,
+			StartValue:       kr.StartValue,
+			Direction:        kr.Direction,
+			RangeMin:         kr.RangeMin,
+			RangeMax:         kr.RangeMax,
+			Progress:         kr.Progress,
+			StartDate:        kr.StartDate,
+			DueDate:          kr.DueDate,
+			Status:           kr.Status,
+			Currency:         kr.Currency,
+			Amounts:          kr.Amounts,
+			ReportingAmounts: kr.ReportingAmounts,
 		}
 	}
 
@@ -118,12 +134,12 @@ func (s *objectiveService) GetObjectiveWithKeyResults(id string) (*dto.Objective
 	return response, nil
 }
 
-func (s *objectiveService) UpdateObjective(req dto.UpdateObjectiveRequest) (*models.Objective, error) {
+func (s *objectiveService) UpdateObjective(ctx context.Context, req dto.UpdateObjectiveRequest) (*models.Objective, error) {
 	if err := s.validator.Struct(req); err != nil {
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
 
-	existing, err := s.repo.GetByIdentifier("id", req.ID)
+	existing, err := s.repo.GetByIdentifier(ctx, "id", req.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find objective: %w", err)
 	}
@@ -146,60 +162,60 @@ func (s *objectiveService) UpdateObjective(req dto.UpdateObjectiveRequest) (*mod
 
 	existing.UpdatedAt = time.Now()
 
-	updated, err := s.repo.Update(existing)
+	updated, err := s.repo.Update(ctx, existing)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update objective: %v", err)
+		return nil, fmt.Errorf("failed to update objective: %w", err)
 	}
 
 	return updated, nil
 }
 
-func (s *objectiveService) DeleteObjective(id string) error {
-	if err := s.repo.Delete(id); err != nil {
-		return fmt.Errorf("failed to delete objective: %v", err)
+func (s *objectiveService) DeleteObjective(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete objective: %w", err)
 	}
 	return nil
 }
 
-func (s *objectiveService) ListObjectivesByCompany(companyID string) ([]dto.ObjectiveListResponse, error) {
-	objectives, err := s.repo.ListByCompany(companyID)
+func (s *objectiveService) ListObjectivesByCompany(ctx context.Context, companyID string) ([]dto.ObjectiveListResponse, error) {
+	objectives, err := s.repo.ListByCompany(ctx, companyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list objectives by company: %v", err)
+		return nil, fmt.Errorf("failed to list objectives by company: %w", err)
 	}
 
 	return s.mapToListResponse(objectives), nil
 }
 
-func (s *objectiveService) ListObjectivesByTeam(teamID string) ([]dto.ObjectiveListResponse, error) {
-	objectives, err := s.repo.ListByTeam(teamID)
+func (s *objectiveService) ListObjectivesByTeam(ctx context.Context, teamID string) ([]dto.ObjectiveListResponse, error) {
+	objectives, err := s.repo.ListByTeam(ctx, teamID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list objectives by team: %v", err)
+		return nil, fmt.Errorf("failed to list objectives by team: %w", err)
 	}
 
 	return s.mapToListResponse(objectives), nil
 }
 
-func (s *objectiveService) ListObjectivesByOwner(ownerID string) ([]dto.ObjectiveListResponse, error) {
-	objectives, err := s.repo.ListByOwner(ownerID)
+func (s *objectiveService) ListObjectivesByOwner(ctx context.Context, ownerID string) ([]dto.ObjectiveListResponse, error) {
+	objectives, err := s.repo.ListByOwner(ctx, ownerID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list objectives by owner: %v", err)
+		return nil, fmt.Errorf("failed to list objectives by owner: %w", err)
 	}
 
 	return s.mapToListResponse(objectives), nil
 }
 
-func (s *objectiveService) UpdateObjectiveProgress(objectiveID string) error {
-	objective, err := s.repo.GetWithKeyResults(objectiveID)
+func (s *objectiveService) UpdateObjectiveProgress(ctx context.Context, objectiveID string) error {
+	objective, err := s.repo.GetWithKeyResults(ctx, objectiveID)
 	if err != nil {
-		return fmt.Errorf("failed to get objective: %v", err)
+		return fmt.Errorf("failed to get objective: %w", err)
 	}
 
 	objective.UpdateProgress()
 	objective.UpdateStatus()
 
-	_, err = s.repo.Update(objective)
+	_, err = s.repo.Update(ctx, objective)
 	if err != nil {
-		return fmt.Errorf("failed to update objective progress: %v", err)
+		return fmt.Errorf("failed to update objective progress: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,678 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/config"
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"github.com/Slightly-Techie/st-okr-api/internal/repositories"
+	auth "github.com/Slightly-Techie/st-okr-api/pkg"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/crewjam/saml"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotCompanyAdmin      = apperror.Forbidden("only company admins can manage single sign-on")
+	ErrSSONotConfigured     = apperror.NotFound("single sign-on is not enabled for this company")
+	ErrSSORequired          = apperror.Forbidden("your organization requires signing in with single sign-on")
+	ErrSSODomainNotAllowed  = apperror.Forbidden("email domain is not a verified domain of the company")
+	ErrSSOMembershipBlocked = apperror.Forbidden("your membership in this company is not active")
+	ErrDomainNotVerified    = apperror.BadRequest("verification record not found in DNS")
+	ErrDomainClaimed        = apperror.Conflict("the domain is already verified by another company")
+)
+
+// SSOLoginRequest is what the browser needs to start a login at the IdP
+type SSOLoginRequest struct {
+	RedirectURL string
+	State       string
+	Nonce       string
+	RequestID   string
+}
+
+type SSOService interface {
+	GetConfig(ctx context.Context, userID, companyID string) (*dto.SSOConfigResponse, error)
+	UpsertConfig(ctx context.Context, userID, companyID string, req dto.UpsertSSOConfigRequest) (*dto.SSOConfigResponse, error)
+	DeleteConfig(ctx context.Context, userID, companyID string) error
+
+	AddDomain(ctx context.Context, userID, companyID string, req dto.CreateCompanyDomainRequest) (*dto.CompanyDomainResponse, error)
+	ListDomains(ctx context.Context, userID, companyID string) ([]dto.CompanyDomainResponse, error)
+	VerifyDomain(ctx context.Context, userID, companyID, domainID string) (*dto.CompanyDomainResponse, error)
+	RemoveDomain(ctx context.Context, userID, companyID, domainID string) error
+
+	Discover(ctx context.Context, email string) (*dto.SSODiscoverResponse, error)
+	BeginLogin(ctx context.Context, companyID string) (*SSOLoginRequest, error)
+	CompleteOIDCLogin(ctx context.Context, companyID, code, nonce string) (*dto.AuthResponse, error)
+	CompleteSAMLLogin(r *http.Request, companyID string, possibleRequestIDs []string) (*dto.AuthResponse, error)
+	ServiceProviderMetadata(ctx context.Context, companyID string) (*saml.EntityDescriptor, error)
+}
+
+type ssoService struct {
+	repo      repositories.SSORepository
+	validator *validator.Validate
+}
+
+func NewSSOService(repo repositories.SSORepository, validator *validator.Validate) SSOService {
+	return &ssoService{
+		repo:      repo,
+		validator: validator,
+	}
+}
+
+func (s *ssoService) GetConfig(ctx context.Context, userID, companyID string) (*dto.SSOConfigResponse, error) {
+	if err := s.requireCompanyAdmin(ctx, userID, companyID); err != nil {
+		return nil, err
+	}
+
+	cfg, err := s.repo.GetConfig(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapSSOConfigResponse(cfg), nil
+}
+
+func (s *ssoService) UpsertConfig(ctx context.Context, userID, companyID string, req dto.UpsertSSOConfigRequest) (*dto.SSOConfigResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.requireCompanyAdmin(ctx, userID, companyID); err != nil {
+		return nil, err
+	}
+
+	cfg, err := s.repo.GetConfig(ctx, companyID)
+	if errors.Is(err, repositories.ErrSSOConfigNotFound) {
+		cfg = &models.CompanySSOConfig{
+			ID:        uuid.NewString(),
+			CompanyID: companyID,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	cfg.Protocol = req.Protocol
+	cfg.Enabled = req.Enabled
+	cfg.EnforceSSO = req.EnforceSSO
+	cfg.DefaultRole = req.DefaultRole
+	if cfg.DefaultRole == "" {
+		cfg.DefaultRole = models.RoleMember
+	}
+
+	switch req.Protocol {
+	case models.SSOProtocolOIDC:
+		cfg.OIDCIssuer = req.OIDCIssuer
+		cfg.OIDCClientID = req.OIDCClientID
+		// An empty secret keeps the stored one so admins don't have to re-enter it
+		if req.OIDCClientSecret != "" {
+			cfg.OIDCClientSecret = req.OIDCClientSecret
+		}
+		cfg.SAMLMetadataURL, cfg.SAMLMetadataXML = "", ""
+	case models.SSOProtocolSAML:
+		if req.SAMLMetadataURL == "" && req.SAMLMetadataXML == "" {
+			return nil, apperror.BadRequest("saml_metadata_url or saml_metadata_xml is required for SAML")
+		}
+		// Metadata behind a URL is fetched once here and stored, so logins
+		// never reach out to an admin-supplied address. Saving the config
+		// again fetches it afresh, e.g. after the IdP rotates its certificate.
+		metadataXML := req.SAMLMetadataXML
+		if metadataXML == "" {
+			data, err := auth.FetchSAMLMetadata(ctx, req.SAMLMetadataURL)
+			if err != nil {
+				return nil, apperror.BadRequest(err.Error())
+			}
+			metadataXML = string(data)
+		}
+		if _, err := auth.SAMLServiceProvider(metadataXML, spMetadataURL(companyID), ssoURL(companyID, "acs")); err != nil {
+			return nil, err
+		}
+		cfg.SAMLMetadataURL = req.SAMLMetadataURL
+		cfg.SAMLMetadataXML = metadataXML
+		cfg.OIDCIssuer, cfg.OIDCClientID, cfg.OIDCClientSecret = "", "", ""
+	}
+
+	if cfg.Enabled && cfg.EnforceSSO {
+		domains, err := s.repo.ListDomains(ctx, companyID)
+		if err != nil {
+			return nil, err
+		}
+		if !hasVerifiedDomain(domains) {
+			return nil, apperror.BadRequest("verify at least one domain before enforcing single sign-on")
+		}
+	}
+
+	saved, err := s.repo.SaveConfig(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save SSO config: %w", err)
+	}
+
+	return mapSSOConfigResponse(saved), nil
+}
+
+func (s *ssoService) DeleteConfig(ctx context.Context, userID, companyID string) error {
+	if err := s.requireCompanyAdmin(ctx, userID, companyID); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteConfig(ctx, companyID)
+}
+
+func (s *ssoService) AddDomain(ctx context.Context, userID, companyID string, req dto.CreateCompanyDomainRequest) (*dto.CompanyDomainResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.requireCompanyAdmin(ctx, userID, companyID); err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(req.Domain, "."))
+	// Claims are per company; only verification is exclusive
+	if verified, err := s.repo.GetVerifiedDomain(ctx, name); err == nil {
+		if verified.CompanyID != companyID {
+			return nil, ErrDomainClaimed
+		}
+	} else if !errors.Is(err, repositories.ErrCompanyDomainNotFound) {
+		return nil, err
+	}
+
+	token, _, err := auth.GenerateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	domain, err := s.repo.CreateDomain(ctx, &models.CompanyDomain{
+		ID:                uuid.NewString(),
+		CompanyID:         companyID,
+		Domain:            name,
+		VerificationToken: token,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add domain: %w", err)
+	}
+
+	return mapCompanyDomainResponse(domain), nil
+}
+
+func (s *ssoService) ListDomains(ctx context.Context, userID, companyID string) ([]dto.CompanyDomainResponse, error) {
+	if err := s.requireCompanyAdmin(ctx, userID, companyID); err != nil {
+		return nil, err
+	}
+
+	domains, err := s.repo.ListDomains(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.CompanyDomainResponse, len(domains))
+	for i := range domains {
+		res[i] = *mapCompanyDomainResponse(&domains[i])
+	}
+	return res, nil
+}
+
+// VerifyDomain checks the domain's DNS TXT records for the verification value
+func (s *ssoService) VerifyDomain(ctx context.Context, userID, companyID, domainID string) (*dto.CompanyDomainResponse, error) {
+	log := logger.FromContext(ctx)
+
+	if err := s.requireCompanyAdmin(ctx, userID, companyID); err != nil {
+		return nil, err
+	}
+
+	domain, err := s.repo.GetDomain(ctx, companyID, domainID)
+	if err != nil {
+		return nil, err
+	}
+
+	if domain.VerifiedAt != nil {
+		return mapCompanyDomainResponse(domain), nil
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	records, err := net.DefaultResolver.LookupTXT(lookupCtx, domain.Domain)
+	if err != nil {
+		log.Warn("Domain verification lookup failed",
+			"company_id", companyID,
+			"domain", domain.Domain,
+			"error", err.Error(),
+		)
+		return nil, ErrDomainNotVerified
+	}
+
+	expected := domain.DomainVerificationRecord()
+	for _, record := range records {
+		if strings.TrimSpace(record) == expected {
+			now := time.Now()
+			domain.VerifiedAt = &now
+
+			updated, err := s.repo.UpdateDomain(ctx, domain)
+			if errors.Is(err, repositories.ErrDuplicate) {
+				return nil, ErrDomainClaimed
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to mark domain verified: %w", err)
+			}
+
+			log.Info("Company domain verified",
+				"company_id", companyID,
+				"domain", domain.Domain,
+			)
+			return mapCompanyDomainResponse(updated), nil
+		}
+	}
+
+	return nil, ErrDomainNotVerified
+}
+
+func (s *ssoService) RemoveDomain(ctx context.Context, userID, companyID, domainID string) error {
+	if err := s.requireCompanyAdmin(ctx, userID, companyID); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteDomain(ctx, companyID, domainID)
+}
+
+// Discover finds the company whose SSO should be used for an email address
+func (s *ssoService) Discover(ctx context.Context, email string) (*dto.SSODiscoverResponse, error) {
+	domain, err := s.repo.GetVerifiedDomain(ctx, emailDomain(email))
+	if err != nil {
+		return nil, ErrSSONotConfigured
+	}
+
+	cfg, err := s.repo.GetConfig(ctx, domain.CompanyID)
+	if err != nil || !cfg.Enabled {
+		return nil, ErrSSONotConfigured
+	}
+
+	return &dto.SSODiscoverResponse{
+		CompanyID: cfg.CompanyID,
+		LoginURL:  ssoURL(cfg.CompanyID, "login"),
+	}, nil
+}
+
+func (s *ssoService) BeginLogin(ctx context.Context, companyID string) (*SSOLoginRequest, error) {
+	cfg, err := s.enabledConfig(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Protocol {
+	case models.SSOProtocolOIDC:
+		_, oauthConfig, err := auth.OIDCClient(ctx, cfg.OIDCIssuer, cfg.OIDCClientID, cfg.OIDCClientSecret, ssoURL(companyID, "callback"))
+		if err != nil {
+			return nil, err
+		}
+
+		state, _, err := auth.GenerateToken()
+		if err != nil {
+			return nil, err
+		}
+		nonce, _, err := auth.GenerateToken()
+		if err != nil {
+			return nil, err
+		}
+
+		return &SSOLoginRequest{
+			RedirectURL: oauthConfig.AuthCodeURL(state, oidc.Nonce(nonce)),
+			State:       state,
+			Nonce:       nonce,
+		}, nil
+
+	case models.SSOProtocolSAML:
+		sp, err := s.serviceProvider(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		authnRequest, err := sp.MakeAuthenticationRequest(
+			sp.GetSSOBindingLocation(saml.HTTPRedirectBinding), saml.HTTPRedirectBinding, saml.HTTPPostBinding,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SAML request: %w", err)
+		}
+
+		// The state travels as RelayState and comes back with the assertion
+		state, _, err := auth.GenerateToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate state: %w", err)
+		}
+
+		redirectURL, err := authnRequest.Redirect(state, sp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SAML redirect: %w", err)
+		}
+
+		return &SSOLoginRequest{
+			RedirectURL: redirectURL.String(),
+			State:       state,
+			RequestID:   authnRequest.ID,
+		}, nil
+	}
+
+	return nil, ErrSSONotConfigured
+}
+
+func (s *ssoService) CompleteOIDCLogin(ctx context.Context, companyID, code, nonce string) (*dto.AuthResponse, error) {
+	cfg, err := s.enabledConfig(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Protocol != models.SSOProtocolOIDC {
+		return nil, ErrSSONotConfigured
+	}
+
+	provider, oauthConfig, err := auth.OIDCClient(ctx, cfg.OIDCIssuer, cfg.OIDCClientID, cfg.OIDCClientSecret, ssoURL(companyID, "callback"))
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := auth.OIDCExchange(ctx, oauthConfig, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, apperror.Unauthorized("identity provider did not return an ID token")
+	}
+
+	identity, err := auth.OIDCIdentity(ctx, provider, cfg.OIDCClientID, rawIDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.provision(ctx, cfg, identity)
+}
+
+func (s *ssoService) CompleteSAMLLogin(r *http.Request, companyID string, possibleRequestIDs []string) (*dto.AuthResponse, error) {
+	ctx := r.Context()
+	cfg, err := s.enabledConfig(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Protocol != models.SSOProtocolSAML {
+		return nil, ErrSSONotConfigured
+	}
+
+	sp, err := s.serviceProvider(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	assertion, err := sp.ParseResponse(r, possibleRequestIDs)
+	if err != nil {
+		var invalid *saml.InvalidResponseError
+		if errors.As(err, &invalid) {
+			logger.FromContext(ctx).Warn("Rejected SAML response",
+				"company_id", companyID,
+				"reason", invalid.PrivateErr.Error(),
+			)
+		}
+		return nil, fmt.Errorf("invalid SAML response: %w", err)
+	}
+
+	return s.provision(ctx, cfg, auth.SAMLIdentity(assertion))
+}
+
+func (s *ssoService) ServiceProviderMetadata(ctx context.Context, companyID string) (*saml.EntityDescriptor, error) {
+	cfg, err := s.repo.GetConfig(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Protocol != models.SSOProtocolSAML {
+		return nil, ErrSSONotConfigured
+	}
+
+	sp, err := s.serviceProvider(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return sp.Metadata(), nil
+}
+
+// provision signs in the asserted user, creating the user, the SSO identity and
+// the company membership just in time when they don't exist yet
+func (s *ssoService) provision(ctx context.Context, cfg *models.CompanySSOConfig, identity *auth.SSOIdentity) (*dto.AuthResponse, error) {
+	log := logger.FromContext(ctx)
+
+	if identity.Subject == "" || identity.Email == "" || !identity.EmailVerified {
+		return nil, apperror.Unauthorized("identity provider did not assert a verified email address")
+	}
+
+	email := normalizeEmail(identity.Email)
+	domain, err := s.repo.GetVerifiedDomain(ctx, emailDomain(email))
+	if err != nil || domain.CompanyID != cfg.CompanyID {
+		log.Warn("SSO login rejected for unverified email domain",
+			"company_id", cfg.CompanyID,
+			"email", maskEmailForLog(email),
+		)
+		return nil, ErrSSODomainNotAllowed
+	}
+
+	provider := "sso:" + cfg.CompanyID
+	var user models.User
+
+	err = s.repo.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var userIdentity models.UserIdentity
+		res := tx.Where("provider = ? AND provider_user_id = ?", provider, identity.Subject).First(&userIdentity)
+
+		switch {
+		case res.Error == nil:
+			if err := tx.Where("id = ?", userIdentity.UserID).First(&user).Error; err != nil {
+				return fmt.Errorf("failed to get user for identity: %w", err)
+			}
+		case errors.Is(res.Error, gorm.ErrRecordNotFound):
+			res = tx.Where("LOWER(email) = ?", email).First(&user)
+			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+				verifiedAt := time.Now()
+				user = models.User{
+					ID:              uuid.NewString(),
+					FirstName:       identity.FirstName,
+					LastName:        identity.LastName,
+					UserName:        strings.Split(email, "@")[0],
+					Email:           email,
+					EmailVerifiedAt: &verifiedAt,
+				}
+				if err := tx.Create(&user).Error; err != nil {
+					return fmt.Errorf("failed to create user: %w", err)
+				}
+
+				log.Info("Provisioned user from SSO",
+					"company_id", cfg.CompanyID,
+					"user_id", user.ID,
+				)
+			} else if res.Error != nil {
+				return fmt.Errorf("database error: %w", res.Error)
+			}
+
+			userIdentity = models.UserIdentity{
+				ID:             uuid.NewString(),
+				UserID:         user.ID,
+				Provider:       provider,
+				ProviderUserID: identity.Subject,
+				Email:          email,
+				// The company verified the domain of the asserted email
+				EmailVerified: strings.EqualFold(email, user.Email),
+			}
+			if err := tx.Create(&userIdentity).Error; err != nil {
+				return fmt.Errorf("failed to create user identity: %w", err)
+			}
+		default:
+			return fmt.Errorf("database error: %w", res.Error)
+		}
+
+		var membership models.Membership
+		res = tx.Where("user_id = ? AND company_id = ?", user.ID, cfg.CompanyID).First(&membership)
+		switch {
+		case errors.Is(res.Error, gorm.ErrRecordNotFound):
+			membership = models.Membership{
+				ID:        uuid.NewString(),
+				UserID:    user.ID,
+				CompanyID: cfg.CompanyID,
+				Role:      cfg.DefaultRole,
+				Status:    models.StatusActive,
+			}
+			if err := tx.Create(&membership).Error; err != nil {
+				return fmt.Errorf("failed to create membership: %w", err)
+			}
+
+			log.Info("Provisioned membership from SSO",
+				"company_id", cfg.CompanyID,
+				"user_id", user.ID,
+				"role", membership.Role,
+			)
+		case res.Error != nil:
+			return fmt.Errorf("database error: %w", res.Error)
+		case membership.Status != models.StatusActive:
+			return ErrSSOMembershipBlocked
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return issueTokens(ctx, &user)
+}
+
+func (s *ssoService) enabledConfig(ctx context.Context, companyID string) (*models.CompanySSOConfig, error) {
+	cfg, err := s.repo.GetConfig(ctx, companyID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrSSOConfigNotFound) {
+			return nil, ErrSSONotConfigured
+		}
+		return nil, err
+	}
+	if !cfg.Enabled {
+		return nil, ErrSSONotConfigured
+	}
+	return cfg, nil
+}
+
+// serviceProvider builds the company's SAML service provider. Configs saved
+// before metadata was stored have only a URL; it is fetched and stored once.
+func (s *ssoService) serviceProvider(ctx context.Context, cfg *models.CompanySSOConfig) (*saml.ServiceProvider, error) {
+	if cfg.SAMLMetadataXML == "" && cfg.SAMLMetadataURL != "" {
+		data, err := auth.FetchSAMLMetadata(ctx, cfg.SAMLMetadataURL)
+		if err != nil {
+			return nil, err
+		}
+		cfg.SAMLMetadataXML = string(data)
+		if _, err := s.repo.SaveConfig(ctx, cfg); err != nil {
+			return nil, fmt.Errorf("failed to store IdP metadata: %w", err)
+		}
+	}
+	return auth.SAMLServiceProvider(cfg.SAMLMetadataXML, spMetadataURL(cfg.CompanyID), ssoURL(cfg.CompanyID, "acs"))
+}
+
+func (s *ssoService) requireCompanyAdmin(ctx context.Context, userID, companyID string) error {
+	var count int64
+	err := s.repo.GetDB().WithContext(ctx).Model(&models.Membership{}).
+		Where("user_id = ? AND company_id = ? AND role = ? AND status = ?", userID, companyID, models.RoleAdmin, models.StatusActive).
+		Count(&count).Error
+	if err != nil {
+		return fmt.Errorf("failed to check company role: %w", err)
+	}
+	if count == 0 {
+		return ErrNotCompanyAdmin
+	}
+	return nil
+}
+
+// ssoEnforcedFor reports whether the user must sign in through their company's
+// SSO, either as a member of an enforcing company or by owning an email on one
+// of its verified domains
+func ssoEnforcedFor(db *gorm.DB, user *models.User) (bool, error) {
+	var count int64
+
+	err := db.Model(&models.CompanySSOConfig{}).
+		Where("enabled AND enforce_sso").
+		Where(
+			db.Where("company_id IN (?)",
+				db.Model(&models.Membership{}).Select("company_id").Where("user_id = ? AND status = ?", user.ID, models.StatusActive),
+			).Or("company_id IN (?)",
+				db.Model(&models.CompanyDomain{}).Select("company_id").Where("domain = ? AND verified_at IS NOT NULL", emailDomain(user.Email)),
+			),
+		).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check SSO enforcement: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func ssoURL(companyID, action string) string {
+	return fmt.Sprintf("%s/sso/%s/%s", strings.TrimSuffix(config.ENV.Server.OAuthCallbackURL, "/"), companyID, action)
+}
+
+func spMetadataURL(companyID string) string {
+	return ssoURL(companyID, "metadata")
+}
+
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at == -1 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
+
+func hasVerifiedDomain(domains []models.CompanyDomain) bool {
+	for _, d := range domains {
+		if d.VerifiedAt != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func mapSSOConfigResponse(cfg *models.CompanySSOConfig) *dto.SSOConfigResponse {
+	res := &dto.SSOConfigResponse{
+		CompanyID:       cfg.CompanyID,
+		Protocol:        cfg.Protocol,
+		Enabled:         cfg.Enabled,
+		OIDCIssuer:      cfg.OIDCIssuer,
+		OIDCClientID:    cfg.OIDCClientID,
+		HasClientSecret: cfg.OIDCClientSecret != "",
+		SAMLMetadataURL: cfg.SAMLMetadataURL,
+		HasSAMLMetadata: cfg.SAMLMetadataXML != "" || cfg.SAMLMetadataURL != "",
+		DefaultRole:     cfg.DefaultRole,
+		EnforceSSO:      cfg.EnforceSSO,
+		LoginURL:        ssoURL(cfg.CompanyID, "login"),
+		CallbackURL:     ssoURL(cfg.CompanyID, "callback"),
+		UpdatedAt:       cfg.UpdatedAt,
+	}
+	if cfg.Protocol == models.SSOProtocolSAML {
+		res.CallbackURL = ssoURL(cfg.CompanyID, "acs")
+		res.SAMLMetadataPath = spMetadataURL(cfg.CompanyID)
+	}
+	return res
+}
+
+func mapCompanyDomainResponse(domain *models.CompanyDomain) *dto.CompanyDomainResponse {
+	return &dto.CompanyDomainResponse{
+		ID:          domain.ID,
+		Domain:      domain.Domain,
+		Verified:    domain.VerifiedAt != nil,
+		VerifiedAt:  domain.VerifiedAt,
+		RecordType:  "TXT",
+		RecordName:  domain.Domain,
+		RecordValue: domain.DomainVerificationRecord(),
+	}
+}
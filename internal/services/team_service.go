@@ -1,8 +1,10 @@
 package services
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
 	"github.com/Slightly-Techie/st-okr-api/internal/dto"
 	"github.com/Slightly-Techie/st-okr-api/internal/models"
 	"github.com/Slightly-Techie/st-okr-api/internal/repositories"
@@ -11,15 +13,15 @@ import (
 )
 
 type TeamService interface {
-	CreateTeam(t dto.CreateTeamRequest) (*models.Team, error)
-	GetTeam(identifier, id string) (*models.Team, error)
-	UpdateTeam(t dto.UpdateTeamRequest) (*models.Team, error)
-	DeleteTeam(id string) error
+	CreateTeam(ctx context.Context, t dto.CreateTeamRequest) (*models.Team, error)
+	GetTeam(ctx context.Context, identifier, id string) (*models.Team, error)
+	UpdateTeam(ctx context.Context, t dto.UpdateTeamRequest) (*models.Team, error)
+	DeleteTeam(ctx context.Context, id string) error
 
 	// AddMember(teamID, userID string) (*models.TeamMember, error)
-	AddMember(t *dto.TeamMemberRequest) (*models.TeamMember, error)
-	ListMembers(identifier, teamID string) ([]models.TeamMember, error)
-	RemoveMember(id string) error
+	AddMember(ctx context.Context, t *dto.TeamMemberRequest) (*models.TeamMember, error)
+	ListMembers(ctx context.Context, identifier, teamID string) ([]models.TeamMember, error)
+	RemoveMember(ctx context.Context, id string) error
 	// isTeamMember(t dto.TeamMemberRequest) (bool, error)
 }
 
@@ -35,7 +37,7 @@ func NewTeamService(repo repositories.TeamRepository, validator *validator.Valid
 	}
 }
 
-func (r *teamService) CreateTeam(t dto.CreateTeamRequest) (*models.Team, error) {
+func (r *teamService) CreateTeam(ctx context.Context, t dto.CreateTeamRequest) (*models.Team, error) {
 	if err := r.validator.Struct(t); err != nil {
 		return nil, err
 	}
@@ -47,7 +49,7 @@ func (r *teamService) CreateTeam(t dto.CreateTeamRequest) (*models.Team, error)
 		Description: t.Description,
 	}
 
-	created, err := r.repo.CreateTeam(&team)
+	created, err := r.repo.CreateTeam(ctx, &team)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create team: %w", err)
 	}
@@ -55,15 +57,15 @@ func (r *teamService) CreateTeam(t dto.CreateTeamRequest) (*models.Team, error)
 	return created, nil
 }
 
-func (r *teamService) GetTeam(identifier, id string) (*models.Team, error) {
-	team, err := r.repo.GetByIdentifier(identifier, id)
+func (r *teamService) GetTeam(ctx context.Context, identifier, id string) (*models.Team, error) {
+	team, err := r.repo.GetByIdentifier(ctx, identifier, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get team: %v", err)
+		return nil, fmt.Errorf("failed to get team: %w", err)
 	}
 	return team, nil
 }
 
-func (r *teamService) UpdateTeam(t dto.UpdateTeamRequest) (*models.Team, error) {
+func (r *teamService) UpdateTeam(ctx context.Context, t dto.UpdateTeamRequest) (*models.Team, error) {
 	if err := r.validator.Struct(t); err != nil {
 		return nil, err
 	}
@@ -73,33 +75,33 @@ func (r *teamService) UpdateTeam(t dto.UpdateTeamRequest) (*models.Team, error)
 		Description: t.Description,
 	}
 
-	updatedTeam, err := r.repo.UpdateTeam(&team)
+	updatedTeam, err := r.repo.UpdateTeam(ctx, &team)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update team: %v", err)
+		return nil, fmt.Errorf("failed to update team: %w", err)
 	}
 
 	return updatedTeam, nil
 }
 
-func (r *teamService) DeleteTeam(id string) error {
-	if err := r.repo.DeleteTeam(id); err != nil {
-		return fmt.Errorf("failed to delete team: %v", err)
+func (r *teamService) DeleteTeam(ctx context.Context, id string) error {
+	if err := r.repo.DeleteTeam(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete team: %w", err)
 	}
 	return nil
 }
 
-func (r *teamService) AddMember(t *dto.TeamMemberRequest) (*models.TeamMember, error) {
+func (r *teamService) AddMember(ctx context.Context, t *dto.TeamMemberRequest) (*models.TeamMember, error) {
 	if err := r.validator.Struct(t); err != nil {
-		return nil, fmt.Errorf("validation error: %v", err)
+		return nil, fmt.Errorf("validation error: %w", err)
 	}
 
-	isMember, err := r.repo.IsMember(t.TeamID, t.UserID)
+	isMember, err := r.repo.IsMember(ctx, t.TeamID, t.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check user's team membership: %v", err)
+		return nil, fmt.Errorf("failed to check user's team membership: %w", err)
 	}
 
 	if isMember {
-		return nil, fmt.Errorf("user is already a member of the team")
+		return nil, apperror.Conflict("user is already a member of the team")
 	}
 
 	teamMember := models.TeamMember{
@@ -108,7 +110,7 @@ func (r *teamService) AddMember(t *dto.TeamMemberRequest) (*models.TeamMember, e
 		TeamID: t.TeamID,
 	}
 
-	created, err := r.repo.AddTeamMember(&teamMember)
+	created, err := r.repo.AddTeamMember(ctx, &teamMember)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create team membership: %w", err)
 	}
@@ -116,23 +118,23 @@ func (r *teamService) AddMember(t *dto.TeamMemberRequest) (*models.TeamMember, e
 	return created, nil
 }
 
-func (r *teamService) ListMembers(identifier, teamID string) ([]models.TeamMember, error) {
-	teamMembers, err := r.repo.GetTeamMembers(identifier, teamID)
+func (r *teamService) ListMembers(ctx context.Context, identifier, teamID string) ([]models.TeamMember, error) {
+	teamMembers, err := r.repo.GetTeamMembers(ctx, identifier, teamID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get team members: %v", err)
+		return nil, fmt.Errorf("failed to get team members: %w", err)
 	}
 
 	return teamMembers, nil
 }
 
-func (r *teamService) RemoveMember(id string) error {
-	if err := r.repo.RemoveTeamMember(id); err != nil {
-		return fmt.Errorf("failed to remove team member: %v", err)
+func (r *teamService) RemoveMember(ctx context.Context, id string) error {
+	if err := r.repo.RemoveTeamMember(ctx, id); err != nil {
+		return fmt.Errorf("failed to remove team member: %w", err)
 	}
 
 	return nil
 }
 
-// func (r *teamService) isTeamMember(t dto.TeamMemberRequest) (bool, error) {
+// func (r *teamService) isTeamMember(ctx context.Context, t dto.TeamMemberRequest) (bool, error) {
 
 // }
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+	"github.com/Slightly-Techie/st-okr-api/internal/logger"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"github.com/Slightly-Techie/st-okr-api/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// maxIdealPoints bounds the ideal trajectory of long periods, which is then
+// drawn every few days instead of daily
+const maxIdealPoints = 400
+
+const day = 24 * time.Hour
+
+type TimeSeriesService interface {
+	RecordSnapshots(ctx context.Context) error
+	ObjectiveTimeSeries(ctx context.Context, userID, objectiveID string) (*dto.TimeSeriesResponse, error)
+	KeyResultTimeSeries(ctx context.Context, userID, keyResultID string) (*dto.TimeSeriesResponse, error)
+}
+
+type timeSeriesService struct {
+	repo repositories.ProgressSnapshotRepository
+	now  func() time.Time
+}
+
+func NewTimeSeriesService(repo repositories.ProgressSnapshotRepository) TimeSeriesService {
+	return &timeSeriesService{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+// RecordSnapshots files today's progress of every running objective and key
+// result. Running it again the same day overwrites the day's snapshots.
+func (s *timeSeriesService) RecordSnapshots(ctx context.Context) error {
+	written, err := s.repo.RecordActive(ctx, s.now())
+	if err != nil {
+		return fmt.Errorf("failed to record progress snapshots: %w", err)
+	}
+	logger.FromContext(ctx).Info("Progress snapshots recorded", "snapshots", written)
+	return nil
+}
+
+func (s *timeSeriesService) ObjectiveTimeSeries(ctx context.Context, userID, objectiveID string) (*dto.TimeSeriesResponse, error) {
+	db := s.repo.GetDB().WithContext(ctx)
+
+	var objective models.Objective
+	if err := db.Where("id = ?", objectiveID).First(&objective).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repositories.ErrObjectiveNotFound
+		}
+		return nil, fmt.Errorf("failed to get objective: %w", err)
+	}
+	if err := checkCompanyAccess(db, objective.CompanyID, userID, ErrNotCompanyMember, models.RoleAdmin, models.RoleMember, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	current := models.ProgressSnapshot{Progress: objective.Progress}
+	return s.timeSeries(ctx, models.SnapshotSubjectObjective, objective.ID, objective.StartDate, objective.EndDate, current, steadyTrajectory(nil, nil))
+}
+
+func (s *timeSeriesService) KeyResultTimeSeries(ctx context.Context, userID, keyResultID string) (*dto.TimeSeriesResponse, error) {
+	db := s.repo.GetDB().WithContext(ctx)
+
+	var kr models.KeyResult
+	if err := db.Where("id = ?", keyResultID).First(&kr).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repositories.ErrKeyResultNotFound
+		}
+		return nil, fmt.Errorf("failed to get key result: %w", err)
+	}
+	var objective models.Objective
+	if err := db.Select("id", "company_id").Where("id = ?", kr.ObjectiveID).First(&objective).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repositories.ErrObjectiveNotFound
+		}
+		return nil, fmt.Errorf("failed to get objective: %w", err)
+	}
+	if err := checkCompanyAccess(db, objective.CompanyID, userID, ErrNotCompanyMember, models.RoleAdmin, models.RoleMember, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	value := kr.CurrentValue
+	current := models.ProgressSnapshot{Progress: kr.Progress, Value: &value}
+
+	ideal := steadyTrajectory(&kr.StartValue, &kr.TargetValue)
+	if kr.MetricType == models.MetricTypeRange {
+		ideal = rangeTrajectory
+	}
+	series, err := s.timeSeries(ctx, models.SnapshotSubjectKeyResult, kr.ID, kr.StartDate, kr.DueDate, current, ideal)
+	if err != nil {
+		return nil, err
+	}
+
+	if kr.MetricType == models.MetricTypeRange {
+		series.RangeMin, series.RangeMax = &kr.RangeMin, &kr.RangeMax
+	} else {
+		series.StartValue, series.TargetValue = &kr.StartValue, &kr.TargetValue
+	}
+	return series, nil
+}
+
+// trajectory is the ideal progress, and value if any, a fraction of the way
+// from the start date to the end date
+type trajectory func(fraction float64) (float64, *float64)
+
+// steadyTrajectory rises linearly from 0% to 100%. Given start and target
+// values, the expected value moves linearly between them too.
+func steadyTrajectory(start, target *float64) trajectory {
+	return func(fraction float64) (float64, *float64) {
+		if start == nil || target == nil {
+			return fraction * 100, nil
+		}
+		value := roundProgress(*start + (*target-*start)*fraction)
+		return fraction * 100, &value
+	}
+}
+
+// rangeTrajectory stays at 100%, since a range key result should hold its
+// range throughout
+func rangeTrajectory(float64) (float64, *float64) {
+	return 100, nil
+}
+
+// timeSeries reads the snapshots taken between start and end. Today's point
+// is the current progress, so the series is up to date between job runs.
+func (s *timeSeriesService) timeSeries(ctx context.Context, subjectType models.SnapshotSubject, subjectID string, start, end time.Time, current models.ProgressSnapshot, ideal trajectory) (*dto.TimeSeriesResponse, error) {
+	today := models.SnapshotDay(s.now())
+	first, last := models.SnapshotDay(start), models.SnapshotDay(end)
+
+	until := last
+	if today.Before(until) {
+		until = today
+	}
+	snapshots, err := s.repo.ListBySubject(ctx, subjectType, subjectID, first, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list progress snapshots: %w", err)
+	}
+
+	points := make([]dto.TimeSeriesPoint, 0, len(snapshots)+1)
+	for _, snapshot := range snapshots {
+		if models.SnapshotDay(snapshot.Day).Equal(today) {
+			continue
+		}
+		points = append(points, timeSeriesPoint(snapshot.Day, snapshot.Progress, snapshot.Value))
+	}
+	if !today.Before(first) && !today.After(last) {
+		points = append(points, timeSeriesPoint(today, current.Progress, current.Value))
+	}
+
+	return &dto.TimeSeriesResponse{
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		StartDate:   start,
+		EndDate:     end,
+		Points:      points,
+		Ideal:       idealPoints(first, last, ideal),
+	}, nil
+}
+
+// idealPoints draws ideal daily from first to last
+func idealPoints(first, last time.Time, ideal trajectory) []dto.TimeSeriesPoint {
+	point := func(date time.Time, fraction float64) dto.TimeSeriesPoint {
+		progress, value := ideal(fraction)
+		return timeSeriesPoint(date, progress, value)
+	}
+
+	days := int(last.Sub(first) / day)
+	if days <= 0 {
+		return []dto.TimeSeriesPoint{point(first, 1)}
+	}
+
+	step := (days + maxIdealPoints - 1) / maxIdealPoints
+	points := make([]dto.TimeSeriesPoint, 0, days/step+2)
+	for elapsed := 0; elapsed < days; elapsed += step {
+		points = append(points, point(first.AddDate(0, 0, elapsed), float64(elapsed)/float64(days)))
+	}
+	return append(points, point(last, 1))
+}
+
+func timeSeriesPoint(date time.Time, progress float64, value *float64) dto.TimeSeriesPoint {
+	return dto.TimeSeriesPoint{
+		Date:     date.UTC().Format("2006-01-02"),
+		Progress: roundProgress(progress),
+		Value:    value,
+	}
+}
@@ -0,0 +1,146 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/fr"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	frTranslations "github.com/go-playground/validator/v10/translations/fr"
+)
+
+// customMessages holds the messages for the tags registered in
+// KeyResultValidators, keyed by locale and then by tag
+var customMessages = map[string]map[string]string{
+	"en": {
+		"due_date":         "{0} must be a date in the future",
+		"metric_type":      "{0} must be one of numeric, percentage, binary, currency or range",
+		"metric_direction": "{0} must be one of increase or decrease",
+		"assignee_type":    "{0} must be one of individual or team",
+	},
+	"fr": {
+		"due_date":         "{0} doit être une date future",
+		"metric_type":      "{0} doit être numeric, percentage, binary, currency ou range",
+		"metric_direction": "{0} doit être increase ou decrease",
+		"assignee_type":    "{0} doit être individual ou team",
+	},
+}
+
+type locale struct {
+	translator   locales.Translator
+	registerTags func(*validator.Validate, ut.Translator) error
+}
+
+// supported lists the locales validation messages are available in. The
+// first one is the fallback for clients asking for anything else.
+var supported = []locale{
+	{en.New(), enTranslations.RegisterDefaultTranslations},
+	{fr.New(), frTranslations.RegisterDefaultTranslations},
+}
+
+var universal = newUniversalTranslator()
+
+func newUniversalTranslator() *ut.UniversalTranslator {
+	translators := make([]locales.Translator, len(supported))
+	for i, l := range supported {
+		translators[i] = l.translator
+	}
+	return ut.New(translators[0], translators...)
+}
+
+// RegisterTranslations makes v report fields by their JSON name and registers
+// the messages used by Translate for every supported locale
+func RegisterTranslations(v *validator.Validate) error {
+	v.RegisterTagNameFunc(jsonFieldName)
+
+	for _, l := range supported {
+		name := l.translator.Locale()
+		trans, _ := universal.GetTranslator(name)
+
+		if err := l.registerTags(v, trans); err != nil {
+			return fmt.Errorf("failed to register %s validation messages: %w", name, err)
+		}
+
+		for tag, message := range customMessages[name] {
+			if err := v.RegisterTranslation(tag, trans, registerMessage(tag, message), translateField); err != nil {
+				return fmt.Errorf("failed to register %s message for %q: %w", name, tag, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Translate turns validation errors into messages keyed by the JSON path of
+// the offending field, in the best match for an Accept-Language header
+func Translate(errs validator.ValidationErrors, acceptLanguage string) map[string]string {
+	trans, _ := universal.FindTranslator(parseAcceptLanguage(acceptLanguage)...)
+
+	details := make(map[string]string, len(errs))
+	for _, fieldErr := range errs {
+		message := fieldErr.Translate(trans)
+		if message == fieldErr.Error() {
+			// No message is registered for this tag
+			message = fmt.Sprintf("%s failed the %q rule", fieldErr.Field(), fieldErr.Tag())
+		}
+		details[fieldPath(fieldErr)] = message
+	}
+	return details
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// fieldPath drops the struct name from the namespace, so a nested field is
+// reported as "parent.child"
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.IndexByte(namespace, '.'); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fieldErr.Field()
+}
+
+// parseAcceptLanguage returns the languages of an Accept-Language header in
+// the order given, each followed by its base language ("fr-CA" adds "fr")
+func parseAcceptLanguage(header string) []string {
+	var tags []string
+	for _, part := range strings.Split(header, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		tag = strings.ReplaceAll(strings.ToLower(tag), "-", "_")
+		tags = append(tags, tag)
+		if base, _, found := strings.Cut(tag, "_"); found {
+			tags = append(tags, base)
+		}
+	}
+	return tags
+}
+
+func registerMessage(tag, message string) validator.RegisterTranslationsFunc {
+	return func(trans ut.Translator) error {
+		return trans.Add(tag, message, true)
+	}
+}
+
+func translateField(trans ut.Translator, fieldErr validator.FieldError) string {
+	message, err := trans.T(fieldErr.Tag(), fieldErr.Field())
+	if err != nil {
+		return fieldErr.Error()
+	}
+	return message
+}
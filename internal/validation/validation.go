@@ -1,10 +1,11 @@
 package validation
 
 import (
-	"fmt"
 	"time"
 
+	"github.com/Slightly-Techie/st-okr-api/internal/apperror"
 	"github.com/Slightly-Techie/st-okr-api/internal/models"
+	"github.com/Slightly-Techie/st-okr-api/internal/money"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -12,6 +13,7 @@ func KeyResultValidators(v *validator.Validate) {
 	v.RegisterValidation("due_date", ValidateDueDate)
 	v.RegisterValidation("metric_type", ValidateMetricType)
 	v.RegisterValidation("assignee_type", ValidateAssigneeType)
+	v.RegisterValidation("metric_direction", ValidateMetricDirection)
 }
 
 func ValidateDueDate(f validator.FieldLevel) bool {
@@ -28,7 +30,17 @@ func ValidateMetricType(f validator.FieldLevel) bool {
 	case models.MetricTypeNumeric,
 		models.MetricTypePercentage,
 		models.MetricTypeBinary,
-		models.MetrictTypeCurrency:
+		models.MetrictTypeCurrency,
+		models.MetricTypeRange:
+		return true
+	default:
+		return false
+	}
+}
+
+func ValidateMetricDirection(f validator.FieldLevel) bool {
+	switch models.MetricDirection(f.Field().String()) {
+	case models.DirectionIncrease, models.DirectionDecrease:
 		return true
 	default:
 		return false
@@ -46,45 +58,69 @@ func ValidateAssigneeType(f validator.FieldLevel) bool {
 	}
 }
 
+// ValidateMetricValues checks the values of a key result against its metric
+// type. The error's Details name the offending field.
 func ValidateMetricValues(kr *models.KeyResult) error {
 	switch kr.MetricType {
 
 	case models.MetricTypeNumeric, models.MetrictTypeCurrency:
+		if _, ok := money.Lookup(kr.Currency); kr.MetricType == models.MetrictTypeCurrency && !ok {
+			return metricError("currency", "currency must be an ISO 4217 code such as USD")
+		}
 		if kr.CurrentValue < 0 {
-			return fmt.Errorf("current value cannot be negative")
+			return metricError("current_value", "current value cannot be negative")
+		}
+		if kr.StartValue < 0 {
+			return metricError("start_value", "start value cannot be negative")
+		}
+		// The current value cannot follow a decreasing key result below zero
+		if kr.TargetValue < 0 {
+			return metricError("target_value", "target value cannot be negative")
 		}
 
 	case models.MetricTypePercentage:
-		if kr.TargetValue <= 0 || kr.TargetValue > 100 {
-			return fmt.Errorf("percentage target must be between 0 and 100")
+		if kr.TargetValue < 0 || kr.TargetValue > 100 {
+			return metricError("target_value", "percentage target must be between 0 and 100")
 		}
 		if kr.CurrentValue < 0 || kr.CurrentValue > 100 {
-			return fmt.Errorf("percentage current value must be between 0 and 100")
+			return metricError("current_value", "percentage current value must be between 0 and 100")
+		}
+		if kr.StartValue < 0 || kr.StartValue > 100 {
+			return metricError("start_value", "percentage start value must be between 0 and 100")
 		}
 
 	case models.MetricTypeBinary:
 		if kr.TargetValue != 0 && kr.TargetValue != 1 {
-			return fmt.Errorf("boolean target must be 0 or 1")
+			return metricError("target_value", "boolean target must be 0 or 1")
 		}
 		if kr.CurrentValue != 0 && kr.CurrentValue != 1 {
-			return fmt.Errorf("boolean current value must be 0 or 1")
+			return metricError("current_value", "boolean current value must be 0 or 1")
+		}
+
+	case models.MetricTypeRange:
+		if kr.RangeMin >= kr.RangeMax {
+			return metricError("range_max", "range maximum must be above the range minimum")
 		}
 	}
 	return nil
 }
 
+func metricError(field, message string) error {
+	return apperror.Validation(message, map[string]string{field: message})
+}
+
 func ValidateAssigneeID(kr *models.KeyResult, isUser, isTeam func(string) bool) error {
 	switch kr.AssigneeID {
 	case string(models.AssigneeTypeIndividual):
 		if !isUser(kr.AssigneeID) {
-			return fmt.Errorf("assignee ID is not valid for individual assignee")
+			return apperror.BadRequest("assignee ID is not valid for individual assignee")
 		}
 	case string(models.AssigneeTypeTeam):
 		if !isTeam(kr.AssigneeID) {
-			return fmt.Errorf("assignee ID is not valid for team assignee")
+			return apperror.BadRequest("assignee ID is not valid for team assignee")
 		}
 	default:
-		return fmt.Errorf("invalid assignee type")
+		return apperror.BadRequest("invalid assignee type")
 	}
 	return nil
 }
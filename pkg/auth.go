@@ -2,18 +2,40 @@ package auth
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/Slightly-Techie/st-okr-api/config"
 	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/github"
 	"github.com/markbates/goth/providers/google"
+	"github.com/markbates/goth/providers/microsoftonline"
 )
 
 func NewAuth() {
-	googleClientID := config.ENV.GoogleClientID
-	googleClientSecret := config.ENV.GoogleClientSecret
-	googleCallbackURL := fmt.Sprintf("http://localhost:%s/api/v1/auth/google/callback", config.ENV.ServerPort)
-
-	goth.UseProviders(
-		google.New(googleClientID, googleClientSecret, googleCallbackURL, "email", "profile"),
-	)
-}
\ No newline at end of file
+	var providers []goth.Provider
+
+	if config.ENV.Auth.Google.ClientID != "" {
+		providers = append(providers,
+			google.New(config.ENV.Auth.Google.ClientID, config.ENV.Auth.Google.ClientSecret, callbackURL("google"), "email", "profile"),
+		)
+	}
+
+	if config.ENV.Auth.GitHub.ClientID != "" {
+		providers = append(providers,
+			github.New(config.ENV.Auth.GitHub.ClientID, config.ENV.Auth.GitHub.ClientSecret, callbackURL("github"), "read:user", "user:email"),
+		)
+	}
+
+	if config.ENV.Auth.Microsoft.ClientID != "" {
+		providers = append(providers,
+			microsoftonline.New(config.ENV.Auth.Microsoft.ClientID, config.ENV.Auth.Microsoft.ClientSecret, callbackURL("microsoftonline"), "User.Read"),
+		)
+	}
+
+	goth.UseProviders(providers...)
+}
+
+// callbackURL builds the OAuth redirect URL for a provider from the configured base URL
+func callbackURL(provider string) string {
+	return fmt.Sprintf("%s/%s/callback", strings.TrimSuffix(config.ENV.Server.OAuthCallbackURL, "/"), provider)
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+)
+
+// AuthService calls the email, password and token endpoints. The browser
+// redirect flows (social and SSO logins) are not available to API clients.
+type AuthService struct {
+	c *Client
+}
+
+func (s *AuthService) Register(ctx context.Context, req RegisterRequest) error {
+	_, err := s.c.do(ctx, call{method: http.MethodPost, path: "/auth/register", body: req})
+	return err
+}
+
+// Login signs in and switches the client to the returned tokens
+func (s *AuthService) Login(ctx context.Context, email, password string) (*AuthResponse, error) {
+	return s.issue(ctx, "/auth/login", dto.LoginRequest{Email: email, Password: password})
+}
+
+// Refresh rotates the refresh token and switches the client to the returned
+// tokens. The client calls it itself when the access token is rejected.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*AuthResponse, error) {
+	return s.issue(ctx, "/auth/refresh", dto.RefreshTokenRequest{RefreshToken: refreshToken})
+}
+
+// ExchangeCode trades the one-time code from a browser login for tokens
+func (s *AuthService) ExchangeCode(ctx context.Context, code string) (*AuthResponse, error) {
+	return s.issue(ctx, "/auth/token", dto.AuthorizationCodeRequest{Code: code})
+}
+
+func (s *AuthService) VerifyMagicLink(ctx context.Context, token string) (*AuthResponse, error) {
+	return s.issue(ctx, "/auth/magic-link/verify", dto.TokenRequest{Token: token})
+}
+
+func (s *AuthService) RequestMagicLink(ctx context.Context, email string) error {
+	_, err := s.c.do(ctx, call{method: http.MethodPost, path: "/auth/magic-link", body: dto.EmailRequest{Email: email}})
+	return err
+}
+
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	_, err := s.c.do(ctx, call{method: http.MethodPost, path: "/auth/verify-email", body: dto.TokenRequest{Token: token}})
+	return err
+}
+
+func (s *AuthService) ResendVerification(ctx context.Context, email string) error {
+	_, err := s.c.do(ctx, call{method: http.MethodPost, path: "/auth/verify-email/resend", body: dto.EmailRequest{Email: email}})
+	return err
+}
+
+func (s *AuthService) ForgotPassword(ctx context.Context, email string) error {
+	_, err := s.c.do(ctx, call{method: http.MethodPost, path: "/auth/password/forgot", body: dto.EmailRequest{Email: email}})
+	return err
+}
+
+func (s *AuthService) ResetPassword(ctx context.Context, req ResetPasswordRequest) error {
+	_, err := s.c.do(ctx, call{method: http.MethodPost, path: "/auth/password/reset", body: req})
+	return err
+}
+
+// ListIdentities lists the sign-in providers linked to the current user
+func (s *AuthService) ListIdentities(ctx context.Context) ([]UserIdentity, error) {
+	var identities []UserIdentity
+	_, err := s.c.do(ctx, call{method: http.MethodGet, path: "/auth/identities/", out: &identities})
+	return identities, err
+}
+
+// StartIdentityLink returns the token to pass as link_token when sending the
+// browser to a social login that should be linked to the current user
+func (s *AuthService) StartIdentityLink(ctx context.Context) (*dto.IdentityLinkResponse, error) {
+	return fetch[dto.IdentityLinkResponse](ctx, s.c, call{method: http.MethodPost, path: "/auth/identities/link"})
+}
+
+func (s *AuthService) UnlinkIdentity(ctx context.Context, id string) error {
+	_, err := s.c.do(ctx, call{method: http.MethodDelete, path: pathf("/auth/identities/%s", id)})
+	return err
+}
+
+// issue calls an endpoint that returns tokens and keeps them for later calls
+func (s *AuthService) issue(ctx context.Context, path string, body any) (*AuthResponse, error) {
+	res, err := fetch[AuthResponse](ctx, s.c, call{method: http.MethodPost, path: path, body: body, noRefresh: true})
+	if err != nil {
+		return nil, err
+	}
+	s.c.SetTokens(res.AccessToken, res.RefreshToken)
+	return res, nil
+}
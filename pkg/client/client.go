@@ -0,0 +1,307 @@
+// Package client is a typed Go client for the OKR API. Request and response
+// bodies are the API's own dto and model types, re-exported in types.go so
+// callers outside this module can name them.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/response"
+)
+
+const (
+	apiPrefix        = "/api/v1"
+	defaultPageSize  = 100
+	defaultUserAgent = "st-okr-api-go-client"
+)
+
+// Config configures a Client. Only BaseURL is required.
+type Config struct {
+	// BaseURL is the server root, e.g. https://okr.example.com
+	BaseURL string
+	// HTTPClient sends the requests; http.DefaultClient when nil
+	HTTPClient *http.Client
+	// AccessToken and RefreshToken authenticate requests. Login and Refresh
+	// replace them, and an expired access token is refreshed once on a 401.
+	AccessToken  string
+	RefreshToken string
+	Retry        RetryPolicy
+	// PageSize is the number of items list iterators request per page
+	PageSize  int
+	UserAgent string
+}
+
+// Client calls the API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	retry      RetryPolicy
+	pageSize   int
+	userAgent  string
+
+	mu           sync.RWMutex
+	accessToken  string
+	refreshToken string
+	// refreshing serialises refreshes so concurrent 401s rotate the refresh
+	// token once
+	refreshing sync.Mutex
+
+	Auth        *AuthService
+	SSO         *SSOService
+	Companies   *CompanyService
+	Memberships *MembershipService
+	Teams       *TeamService
+	Objectives  *ObjectiveService
+	KeyResults  *KeyResultService
+}
+
+func New(cfg Config) (*Client, error) {
+	base, err := url.Parse(strings.TrimSuffix(cfg.BaseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme must be http or https", cfg.BaseURL)
+	}
+
+	c := &Client{
+		baseURL:      base,
+		httpClient:   cfg.HTTPClient,
+		retry:        cfg.Retry.withDefaults(),
+		pageSize:     cfg.PageSize,
+		userAgent:    cfg.UserAgent,
+		accessToken:  cfg.AccessToken,
+		refreshToken: cfg.RefreshToken,
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	if c.pageSize <= 0 {
+		c.pageSize = defaultPageSize
+	}
+	if c.userAgent == "" {
+		c.userAgent = defaultUserAgent
+	}
+
+	c.Auth = &AuthService{c}
+	c.SSO = &SSOService{c}
+	c.Companies = &CompanyService{c}
+	c.Memberships = &MembershipService{c}
+	c.Teams = &TeamService{c}
+	c.Objectives = &ObjectiveService{c}
+	c.KeyResults = &KeyResultService{c}
+
+	return c, nil
+}
+
+// Tokens returns the tokens the client currently authenticates with
+func (c *Client) Tokens() (accessToken, refreshToken string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.accessToken, c.refreshToken
+}
+
+// SetTokens replaces the tokens the client authenticates with
+func (c *Client) SetTokens(accessToken, refreshToken string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accessToken, c.refreshToken = accessToken, refreshToken
+}
+
+// call describes one API request. path is relative to /api/v1.
+type call struct {
+	method string
+	path   string
+	query  url.Values
+	body   any
+	// raw is sent as is with contentType instead of body as JSON
+	raw         []byte
+	contentType string
+	// out receives the data field of the success envelope
+	out any
+	// noRefresh stops a 401 from triggering a token refresh, for the
+	// endpoints that issue tokens
+	noRefresh bool
+}
+
+// envelope is the success response with its data left undecoded
+type envelope struct {
+	Data      json.RawMessage `json:"data"`
+	Message   string          `json:"message"`
+	RequestID string          `json:"request_id"`
+	Meta      *response.Meta  `json:"meta"`
+}
+
+// do sends the call, retrying as the retry policy allows, and decodes the
+// envelope. It returns the envelope metadata for pagination.
+func (c *Client) do(ctx context.Context, cl call) (*response.Meta, error) {
+	body := cl.raw
+	if cl.body != nil {
+		var err error
+		if body, err = json.Marshal(cl.body); err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+	}
+
+	refreshed := cl.noRefresh
+	for attempt := 1; ; attempt++ {
+		accessToken, _ := c.Tokens()
+		res, err := c.send(ctx, cl, body, accessToken)
+		if err != nil {
+			if attempt < c.retry.MaxAttempts && ctx.Err() == nil && idempotent(cl.method) {
+				if err := c.retry.wait(ctx, attempt, nil); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return nil, err
+		}
+
+		if res.StatusCode == http.StatusUnauthorized && !refreshed && accessToken != "" {
+			drain(res)
+			refreshed = true
+			if err := c.refreshAfter(ctx, accessToken); err != nil {
+				return nil, err
+			}
+			attempt--
+			continue
+		}
+
+		if attempt < c.retry.MaxAttempts && retryable(cl.method, res.StatusCode) {
+			drain(res)
+			if err := c.retry.wait(ctx, attempt, res); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		return decode(res, cl.out)
+	}
+}
+
+func (c *Client) send(ctx context.Context, cl call, body []byte, accessToken string) (*http.Response, error) {
+	target := c.baseURL.String() + apiPrefix + cl.path
+	if len(cl.query) > 0 {
+		target += "?" + cl.query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, cl.method, target, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if body != nil {
+		contentType := cl.contentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	return c.httpClient.Do(req)
+}
+
+// refreshAfter refreshes the tokens after the server rejected rejected. If
+// another request already refreshed them, the new access token is used.
+func (c *Client) refreshAfter(ctx context.Context, rejected string) error {
+	c.refreshing.Lock()
+	defer c.refreshing.Unlock()
+
+	accessToken, refreshToken := c.Tokens()
+	if accessToken != rejected {
+		return nil
+	}
+	if refreshToken == "" {
+		return &Error{
+			StatusCode: http.StatusUnauthorized,
+			Code:       ErrCodeUnauthorized,
+			Message:    "access token rejected and no refresh token is set",
+		}
+	}
+
+	_, err := c.Auth.Refresh(ctx, refreshToken)
+	return err
+}
+
+func decode(res *http.Response, out any) (*response.Meta, error) {
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return nil, newError(res, data)
+	}
+	if res.StatusCode == http.StatusNoContent || len(data) == 0 {
+		return nil, nil
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if out != nil && len(env.Data) > 0 && !bytes.Equal(env.Data, []byte("null")) {
+		if err := json.Unmarshal(env.Data, out); err != nil {
+			return nil, fmt.Errorf("failed to decode response data: %w", err)
+		}
+	}
+	return env.Meta, nil
+}
+
+// drain discards the body so the connection can be reused
+func drain(res *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	res.Body.Close()
+}
+
+// pathf builds a path with each argument escaped as one segment
+func pathf(format string, args ...string) string {
+	escaped := make([]any, len(args))
+	for i, arg := range args {
+		escaped[i] = url.PathEscape(arg)
+	}
+	return fmt.Sprintf(format, escaped...)
+}
+
+// sleep waits for d unless ctx ends first
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// fetch sends cl and returns the decoded data
+func fetch[T any](ctx context.Context, c *Client, cl call) (*T, error) {
+	var out T
+	cl.out = &out
+	if _, err := c.do(ctx, cl); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+)
+
+type CompanyService struct {
+	c *Client
+}
+
+// Create creates a company owned by the current user
+func (s *CompanyService) Create(ctx context.Context, name string) (*Company, error) {
+	body := struct {
+		Name string `json:"name"`
+	}{name}
+	return fetch[Company](ctx, s.c, call{method: http.MethodPost, path: "/companies/", body: body})
+}
+
+func (s *CompanyService) Get(ctx context.Context, id string) (*Company, error) {
+	return fetch[Company](ctx, s.c, call{method: http.MethodGet, path: pathf("/companies/%s", id)})
+}
+
+func (s *CompanyService) Update(ctx context.Context, id string, req UpdateCompanyRequest) (*Company, error) {
+	return fetch[Company](ctx, s.c, call{method: http.MethodPut, path: pathf("/companies/%s", id), body: req})
+}
+
+func (s *CompanyService) Delete(ctx context.Context, id string) error {
+	_, err := s.c.do(ctx, call{method: http.MethodDelete, path: pathf("/companies/%s", id)})
+	return err
+}
+
+// Import creates the objectives and key results of document, a CSV, JSON or
+// YAML file as given by contentType, in one transaction. With dryRun nothing
+// is created and the problems found are listed in the report; otherwise a
+// document with problems fails with an *Error whose Details name each row.
+func (s *CompanyService) Import(ctx context.Context, companyID, contentType string, document []byte, dryRun bool) (*ImportReport, error) {
+	query := url.Values{"dry_run": {strconv.FormatBool(dryRun)}}
+	return fetch[ImportReport](ctx, s.c, call{
+		method:      http.MethodPost,
+		path:        pathf("/companies/%s/import", companyID),
+		query:       query,
+		raw:         document,
+		contentType: contentType,
+	})
+}
+
+// StatusThresholds returns how far below expected progress the company's key
+// results may fall before they are at risk or behind
+func (s *CompanyService) StatusThresholds(ctx context.Context, companyID string) (*StatusThresholds, error) {
+	return fetch[StatusThresholds](ctx, s.c, call{method: http.MethodGet, path: pathf("/companies/%s/status-thresholds", companyID)})
+}
+
+// SetStatusThresholds changes the company's thresholds. Only admins may.
+func (s *CompanyService) SetStatusThresholds(ctx context.Context, companyID string, req StatusThresholdsRequest) (*StatusThresholds, error) {
+	return fetch[StatusThresholds](ctx, s.c, call{method: http.MethodPut, path: pathf("/companies/%s/status-thresholds", companyID), body: req})
+}
+
+// CurrencySettings returns the currency the company reports currency key
+// results in and the exchange rates it converts them at
+func (s *CompanyService) CurrencySettings(ctx context.Context, companyID string) (*CurrencySettings, error) {
+	return fetch[CurrencySettings](ctx, s.c, call{method: http.MethodGet, path: pathf("/companies/%s/currency", companyID)})
+}
+
+// SetReportingCurrency changes the company's reporting currency; an empty
+// currency stops conversion. Only admins may.
+func (s *CompanyService) SetReportingCurrency(ctx context.Context, companyID, currency string) (*CurrencySettings, error) {
+	req := dto.ReportingCurrencyRequest{ReportingCurrency: currency}
+	return fetch[CurrencySettings](ctx, s.c, call{method: http.MethodPut, path: pathf("/companies/%s/currency", companyID), body: req})
+}
+
+// SetExchangeRate adds or replaces the rate between two currencies. Only
+// admins may.
+func (s *CompanyService) SetExchangeRate(ctx context.Context, companyID string, req ExchangeRateRequest) (*ExchangeRate, error) {
+	return fetch[ExchangeRate](ctx, s.c, call{method: http.MethodPut, path: pathf("/companies/%s/currency/rates", companyID), body: req})
+}
+
+func (s *CompanyService) DeleteExchangeRate(ctx context.Context, companyID, from, to string) error {
+	_, err := s.c.do(ctx, call{method: http.MethodDelete, path: pathf("/companies/%s/currency/rates/%s/%s", companyID, from, to)})
+	return err
+}
+
+// Cycles lists the company's named cycles in date order
+func (s *CompanyService) Cycles(ctx context.Context, companyID string) ([]Cycle, error) {
+	var cycles []Cycle
+	_, err := s.c.do(ctx, call{method: http.MethodGet, path: pathf("/companies/%s/cycles", companyID), out: &cycles})
+	return cycles, err
+}
+
+// CreateCycle names a period of the company's OKRs. Only admins may.
+func (s *CompanyService) CreateCycle(ctx context.Context, companyID string, req CreateCycleRequest) (*Cycle, error) {
+	return fetch[Cycle](ctx, s.c, call{method: http.MethodPost, path: pathf("/companies/%s/cycles", companyID), body: req})
+}
+
+func (s *CompanyService) DeleteCycle(ctx context.Context, companyID, cycleID string) error {
+	_, err := s.c.do(ctx, call{method: http.MethodDelete, path: pathf("/companies/%s/cycles/%s", companyID, cycleID)})
+	return err
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/response"
+)
+
+// ErrorCode is the machine-readable code of an API error
+type ErrorCode = response.ErrorCode
+
+const (
+	ErrCodeValidationFailed   = response.ErrCodeValidationFailed
+	ErrCodeUnauthorized       = response.ErrCodeUnauthorized
+	ErrCodeForbidden          = response.ErrCodeForbidden
+	ErrCodeNotFound           = response.ErrCodeNotFound
+	ErrCodeConflict           = response.ErrCodeConflict
+	ErrCodeBadRequest         = response.ErrCodeBadRequest
+	ErrCodeTooManyRequests    = response.ErrCodeTooManyRequests
+	ErrCodeInternalError      = response.ErrCodeInternalError
+	ErrCodeServiceUnavailable = response.ErrCodeServiceUnavailable
+	ErrCodeDatabaseError      = response.ErrCodeDatabaseError
+)
+
+// Error is an error response from the API. Details holds per-field messages
+// for validation errors; RequestID identifies the request in the server logs.
+type Error struct {
+	StatusCode int
+	Code       ErrorCode
+	Message    string
+	Details    map[string]string
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("okr api: %s (%s, status %d", e.Message, e.Code, e.StatusCode)
+	if e.RequestID != "" {
+		msg += ", request " + e.RequestID
+	}
+	return msg + ")"
+}
+
+// IsCode reports whether err is an API error with the given code
+func IsCode(err error, code ErrorCode) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// newError decodes an error envelope. Responses that are not one, such as a
+// proxy's error page, keep the status and fall back to its text.
+func newError(res *http.Response, body []byte) *Error {
+	apiErr := &Error{
+		StatusCode: res.StatusCode,
+		Message:    http.StatusText(res.StatusCode),
+		RequestID:  res.Header.Get("X-Request-ID"),
+	}
+
+	var env response.ErrorResponse
+	if err := json.Unmarshal(body, &env); err != nil || env.Error.Code == "" {
+		return apiErr
+	}
+
+	apiErr.Code = env.Error.Code
+	apiErr.Message = env.Error.Message
+	apiErr.Details = env.Error.Details
+	if env.RequestID != "" {
+		apiErr.RequestID = env.RequestID
+	}
+	return apiErr
+}
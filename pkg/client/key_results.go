@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+)
+
+type KeyResultService struct {
+	c *Client
+}
+
+func (s *KeyResultService) Create(ctx context.Context, req CreateKeyResultRequest) (*KeyResult, error) {
+	return fetch[KeyResult](ctx, s.c, call{method: http.MethodPost, path: "/key-results/", body: req})
+}
+
+func (s *KeyResultService) Get(ctx context.Context, id string) (*KeyResult, error) {
+	return fetch[KeyResult](ctx, s.c, call{method: http.MethodGet, path: pathf("/key-results/%s", id)})
+}
+
+// Update changes a key result. req.ID is set to id.
+func (s *KeyResultService) Update(ctx context.Context, id string, req UpdateKeyResultRequest) (*KeyResult, error) {
+	req.ID = id
+	return fetch[KeyResult](ctx, s.c, call{method: http.MethodPatch, path: pathf("/key-results/%s", id), body: req})
+}
+
+func (s *KeyResultService) Delete(ctx context.Context, id string) error {
+	_, err := s.c.do(ctx, call{method: http.MethodDelete, path: pathf("/key-results/%s", id)})
+	return err
+}
+
+// TimeSeries returns a key result's daily progress and value with its ideal
+// trajectory
+func (s *KeyResultService) TimeSeries(ctx context.Context, id string) (*TimeSeriesResponse, error) {
+	return fetch[TimeSeriesResponse](ctx, s.c, call{method: http.MethodGet, path: pathf("/key-results/%s/timeseries", id)})
+}
+
+func (s *KeyResultService) ListByObjective(ctx context.Context, objectiveID string) iter.Seq2[KeyResult, error] {
+	return list[KeyResult](ctx, s.c, pathf("/key-results/objective/%s", objectiveID))
+}
+
+// ListByAssignee iterates over the key results of a user or team
+func (s *KeyResultService) ListByAssignee(ctx context.Context, assigneeID string) iter.Seq2[KeyResult, error] {
+	return list[KeyResult](ctx, s.c, pathf("/key-results/assignee/%s", assigneeID))
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+)
+
+type MembershipService struct {
+	c *Client
+}
+
+func (s *MembershipService) Create(ctx context.Context, req CreateMembershipRequest) (*Membership, error) {
+	return fetch[Membership](ctx, s.c, call{method: http.MethodPost, path: "/memberships/", body: req})
+}
+
+func (s *MembershipService) Get(ctx context.Context, id string) (*Membership, error) {
+	return fetch[Membership](ctx, s.c, call{method: http.MethodGet, path: pathf("/memberships/%s", id)})
+}
+
+// Update changes a membership. req.ID is set to id.
+func (s *MembershipService) Update(ctx context.Context, id string, req UpdateMembershipRequest) (*Membership, error) {
+	req.ID = id
+	return fetch[Membership](ctx, s.c, call{method: http.MethodPut, path: pathf("/memberships/%s", id), body: req})
+}
+
+func (s *MembershipService) Delete(ctx context.Context, id string) error {
+	_, err := s.c.do(ctx, call{method: http.MethodDelete, path: pathf("/memberships/%s", id)})
+	return err
+}
+
+func (s *MembershipService) UpdateRole(ctx context.Context, id string, role RoleType) error {
+	body := struct {
+		Role RoleType `json:"role"`
+	}{role}
+	_, err := s.c.do(ctx, call{method: http.MethodPatch, path: pathf("/memberships/%s/role", id), body: body})
+	return err
+}
+
+func (s *MembershipService) UpdateStatus(ctx context.Context, id string, status StatusType) error {
+	body := struct {
+		Status StatusType `json:"status"`
+	}{status}
+	_, err := s.c.do(ctx, call{method: http.MethodPatch, path: pathf("/memberships/%s/status", id), body: body})
+	return err
+}
+
+// ListByCompany iterates over a company's members
+func (s *MembershipService) ListByCompany(ctx context.Context, companyID string) iter.Seq2[Membership, error] {
+	return list[Membership](ctx, s.c, pathf("/memberships/company/%s", companyID))
+}
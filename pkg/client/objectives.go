@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+)
+
+type ObjectiveService struct {
+	c *Client
+}
+
+func (s *ObjectiveService) Create(ctx context.Context, req CreateObjectiveRequest) (*Objective, error) {
+	return fetch[Objective](ctx, s.c, call{method: http.MethodPost, path: "/objectives/", body: req})
+}
+
+func (s *ObjectiveService) Get(ctx context.Context, id string) (*Objective, error) {
+	return fetch[Objective](ctx, s.c, call{method: http.MethodGet, path: pathf("/objectives/%s", id)})
+}
+
+// GetWithKeyResults returns an objective together with its key results
+func (s *ObjectiveService) GetWithKeyResults(ctx context.Context, id string) (*ObjectiveResponse, error) {
+	return fetch[ObjectiveResponse](ctx, s.c, call{method: http.MethodGet, path: pathf("/objectives/%s/details", id)})
+}
+
+func (s *ObjectiveService) Update(ctx context.Context, id string, req UpdateObjectiveRequest) (*Objective, error) {
+	req.ID = id
+	return fetch[Objective](ctx, s.c, call{method: http.MethodPut, path: pathf("/objectives/%s", id), body: req})
+}
+
+func (s *ObjectiveService) Delete(ctx context.Context, id string) error {
+	_, err := s.c.do(ctx, call{method: http.MethodDelete, path: pathf("/objectives/%s", id)})
+	return err
+}
+
+// RecalculateProgress recomputes an objective's progress from its key results
+func (s *ObjectiveService) RecalculateProgress(ctx context.Context, id string) error {
+	_, err := s.c.do(ctx, call{method: http.MethodPatch, path: pathf("/objectives/%s/progress", id)})
+	return err
+}
+
+// TimeSeries returns an objective's daily progress and ideal trajectory
+func (s *ObjectiveService) TimeSeries(ctx context.Context, id string) (*TimeSeriesResponse, error) {
+	return fetch[TimeSeriesResponse](ctx, s.c, call{method: http.MethodGet, path: pathf("/objectives/%s/timeseries", id)})
+}
+
+func (s *ObjectiveService) ListByCompany(ctx context.Context, companyID string) iter.Seq2[ObjectiveListResponse, error] {
+	return list[ObjectiveListResponse](ctx, s.c, pathf("/objectives/company/%s", companyID))
+}
+
+func (s *ObjectiveService) ListByTeam(ctx context.Context, teamID string) iter.Seq2[ObjectiveListResponse, error] {
+	return list[ObjectiveListResponse](ctx, s.c, pathf("/objectives/team/%s", teamID))
+}
+
+func (s *ObjectiveService) ListByOwner(ctx context.Context, ownerID string) iter.Seq2[ObjectiveListResponse, error] {
+	return list[ObjectiveListResponse](ctx, s.c, pathf("/objectives/owner/%s", ownerID))
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"strconv"
+)
+
+// list iterates over a list endpoint page by page, requesting the next page
+// while the envelope's meta.has_more is true. Endpoints that return the whole
+// list in one page end the iteration after the first request.
+func list[T any](ctx context.Context, c *Client, path string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for page := 1; ; page++ {
+			var items []T
+			meta, err := c.do(ctx, call{
+				method: http.MethodGet,
+				path:   path,
+				query: map[string][]string{
+					"page":  {strconv.Itoa(page)},
+					"limit": {strconv.Itoa(c.pageSize)},
+				},
+				out: &items,
+			})
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if len(items) == 0 || meta == nil || meta.HasMore == nil || !*meta.HasMore {
+				return
+			}
+		}
+	}
+}
+
+// Collect reads every item of a list iterator, stopping at the first error
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. Requests rejected by
+// the rate limiter are always safe to resend; network errors and 502, 503
+// and 504 responses are only retried for idempotent methods, since the
+// server may have acted on the request.
+type RetryPolicy struct {
+	// MaxAttempts includes the first request; 1 disables retries. Defaults
+	// to 3.
+	MaxAttempts int
+	// MinBackoff is the first delay, doubled on every retry up to
+	// MaxBackoff. A Retry-After header takes precedence.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.MinBackoff <= 0 {
+		p.MinBackoff = 200 * time.Millisecond
+	}
+	if p.MaxBackoff < p.MinBackoff {
+		p.MaxBackoff = max(10*time.Second, p.MinBackoff)
+	}
+	return p
+}
+
+// wait sleeps before retry number attempt. res is the rejected response, or
+// nil after a network error.
+func (p RetryPolicy) wait(ctx context.Context, attempt int, res *http.Response) error {
+	if res != nil {
+		if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return sleep(ctx, min(time.Duration(seconds)*time.Second, p.MaxBackoff))
+		}
+	}
+
+	backoff := p.MinBackoff << (attempt - 1)
+	if backoff <= 0 || backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	// Full jitter keeps clients that failed together from retrying together
+	return sleep(ctx, rand.N(backoff)+1)
+}
+
+func retryable(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent(method)
+	}
+	return false
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
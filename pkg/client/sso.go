@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+)
+
+// SSOService manages a company's single sign-on configuration and email
+// domains
+type SSOService struct {
+	c *Client
+}
+
+// Discover returns the SSO login for the company that owns the email's
+// domain
+func (s *SSOService) Discover(ctx context.Context, email string) (*SSODiscoverResponse, error) {
+	return fetch[SSODiscoverResponse](ctx, s.c, call{method: http.MethodPost, path: "/auth/sso/discover", body: dto.EmailRequest{Email: email}, noRefresh: true})
+}
+
+func (s *SSOService) GetConfig(ctx context.Context, companyID string) (*SSOConfigResponse, error) {
+	return fetch[SSOConfigResponse](ctx, s.c, call{method: http.MethodGet, path: pathf("/companies/%s/sso", companyID)})
+}
+
+func (s *SSOService) PutConfig(ctx context.Context, companyID string, req UpsertSSOConfigRequest) (*SSOConfigResponse, error) {
+	return fetch[SSOConfigResponse](ctx, s.c, call{method: http.MethodPut, path: pathf("/companies/%s/sso", companyID), body: req})
+}
+
+func (s *SSOService) DeleteConfig(ctx context.Context, companyID string) error {
+	_, err := s.c.do(ctx, call{method: http.MethodDelete, path: pathf("/companies/%s/sso", companyID)})
+	return err
+}
+
+func (s *SSOService) ListDomains(ctx context.Context, companyID string) ([]CompanyDomainResponse, error) {
+	var domains []CompanyDomainResponse
+	_, err := s.c.do(ctx, call{method: http.MethodGet, path: pathf("/companies/%s/domains", companyID), out: &domains})
+	return domains, err
+}
+
+// AddDomain registers a domain. The response holds the DNS record that
+// proves ownership before VerifyDomain succeeds.
+func (s *SSOService) AddDomain(ctx context.Context, companyID, domain string) (*CompanyDomainResponse, error) {
+	body := dto.CreateCompanyDomainRequest{Domain: domain}
+	return fetch[CompanyDomainResponse](ctx, s.c, call{method: http.MethodPost, path: pathf("/companies/%s/domains", companyID), body: body})
+}
+
+func (s *SSOService) VerifyDomain(ctx context.Context, companyID, domainID string) (*CompanyDomainResponse, error) {
+	return fetch[CompanyDomainResponse](ctx, s.c, call{method: http.MethodPost, path: pathf("/companies/%s/domains/%s/verify", companyID, domainID)})
+}
+
+func (s *SSOService) DeleteDomain(ctx context.Context, companyID, domainID string) error {
+	_, err := s.c.do(ctx, call{method: http.MethodDelete, path: pathf("/companies/%s/domains/%s", companyID, domainID)})
+	return err
+}
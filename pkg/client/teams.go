@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+)
+
+type TeamService struct {
+	c *Client
+}
+
+func (s *TeamService) Create(ctx context.Context, req CreateTeamRequest) (*Team, error) {
+	return fetch[Team](ctx, s.c, call{method: http.MethodPost, path: "/teams/", body: req})
+}
+
+func (s *TeamService) Get(ctx context.Context, id string) (*Team, error) {
+	return fetch[Team](ctx, s.c, call{method: http.MethodGet, path: pathf("/teams/%s", id)})
+}
+
+// Update changes a team. req.ID is set to id.
+func (s *TeamService) Update(ctx context.Context, id string, req UpdateTeamRequest) (*Team, error) {
+	req.ID = id
+	return fetch[Team](ctx, s.c, call{method: http.MethodPut, path: pathf("/teams/%s", id), body: req})
+}
+
+func (s *TeamService) Delete(ctx context.Context, id string) error {
+	_, err := s.c.do(ctx, call{method: http.MethodDelete, path: pathf("/teams/%s", id)})
+	return err
+}
+
+func (s *TeamService) AddMember(ctx context.Context, teamID, userID string) (*TeamMember, error) {
+	body := TeamMemberRequest{TeamID: teamID, UserID: userID}
+	return fetch[TeamMember](ctx, s.c, call{method: http.MethodPost, path: pathf("/teams/%s/members", teamID), body: body})
+}
+
+// ListMembers iterates over a team's members
+func (s *TeamService) ListMembers(ctx context.Context, teamID string) iter.Seq2[TeamMember, error] {
+	return list[TeamMember](ctx, s.c, pathf("/teams/%s/members", teamID))
+}
+
+// RemoveMember removes a team membership by its own ID, not the user's
+func (s *TeamService) RemoveMember(ctx context.Context, memberID string) error {
+	_, err := s.c.do(ctx, call{method: http.MethodDelete, path: pathf("/teams/members/%s", memberID)})
+	return err
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"github.com/Slightly-Techie/st-okr-api/internal/dto"
+	"github.com/Slightly-Techie/st-okr-api/internal/models"
+)
+
+// Request and response bodies are aliases of the API's own types, so the
+// client cannot drift from the server
+
+type (
+	AuthResponse            = dto.AuthResponse
+	RegisterRequest         = dto.RegisterRequest
+	LoginRequest            = dto.LoginRequest
+	ResetPasswordRequest    = dto.ResetPasswordRequest
+	SSODiscoverResponse     = dto.SSODiscoverResponse
+	UpsertSSOConfigRequest  = dto.UpsertSSOConfigRequest
+	SSOConfigResponse       = dto.SSOConfigResponse
+	CompanyDomainResponse   = dto.CompanyDomainResponse
+	UpdateCompanyRequest    = dto.CreateCompanyRequest
+	CreateMembershipRequest = dto.CreateMembershipRequest
+	UpdateMembershipRequest = dto.UpdateMembershipRequest
+	CreateTeamRequest       = dto.CreateTeamRequest
+	UpdateTeamRequest       = dto.UpdateTeamRequest
+	TeamMemberRequest       = dto.TeamMemberRequest
+	CreateObjectiveRequest  = dto.CreateObjectiveRequest
+	UpdateObjectiveRequest  = dto.UpdateObjectiveRequest
+	ObjectiveResponse       = dto.ObjectiveResponse
+	ObjectiveListResponse   = dto.ObjectiveListResponse
+	CreateKeyResultRequest  = dto.CreateKeyResultRequest
+	UpdateKeyResultRequest  = dto.UpdateKeyResultRequest
+	KeyResultResponse       = dto.KeyResultResponse
+	ImportDocument          = dto.ImportDocument
+	ImportReport            = dto.ImportReport
+	ImportError             = dto.ImportError
+	TimeSeriesResponse      = dto.TimeSeriesResponse
+	StatusThresholdsRequest = dto.StatusThresholdsRequest
+	StatusThresholds        = models.StatusThresholds
+	ExchangeRateRequest     = dto.ExchangeRateRequest
+	CurrencySettings        = dto.CurrencySettingsResponse
+	ExchangeRate            = models.ExchangeRate
+	CreateCycleRequest      = dto.CreateCycleRequest
+	Cycle                   = models.Cycle
+	Amounts                 = models.Amounts
+	UserIdentity            = models.UserIdentity
+	Company                 = models.Company
+	Membership              = models.Membership
+	Team                    = models.Team
+	TeamMember              = models.TeamMember
+	Objective               = models.Objective
+	KeyResult               = models.KeyResult
+	RoleType                = models.RoleType
+	StatusType              = models.StatusType
+	ObjectiveType           = models.ObjectiveType
+	ObjectiveStatus         = models.ObjectiveStatus
+	MetricType              = models.MetricType
+	AssigneeType            = models.AssigneeType
+	KeyResultProgressStatus = models.KeyResultProgressStatus
+	SSOProtocol             = models.SSOProtocol
+)
@@ -1,39 +1,118 @@
 package auth
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/Slightly-Techie/st-okr-api/config"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-func CreateJWTTokens(data any) (string, string, int64, error) {
-	accessTokenExp := time.Now().Add(time.Hour * 24 * 15).Unix()
-	accessTokenClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": data,              // Subject (user identifier)
-		"iss": "st-okr",          // Issuer
-		"aud": data,              // Audience (user role)
-		"exp": accessTokenExp,    // Expiration time
-		"iat": time.Now().Unix(), // Issued at
-	})
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+
+	accessTokenTTL  = time.Hour * 24 * 15
+	refreshTokenTTL = time.Hour * 24 * 30
+)
+
+// Claims are the claims carried by access and refresh tokens
+type Claims struct {
+	Type string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+var keys *KeySet
+
+// InitKeys loads the signing keys from the configured directory. The server
+// must not start without them.
+func InitKeys() error {
+	set, err := LoadKeySet(config.ENV.Auth.JWT.KeysDir, config.ENV.Auth.JWT.ActiveKeyID)
+	if err != nil {
+		return err
+	}
+	keys = set
+	return nil
+}
+
+// Keys returns the loaded key set
+func Keys() *KeySet {
+	return keys
+}
 
-	accessTokenString, err := accessTokenClaims.SignedString([]byte(config.ENV.JWTKey))
+func CreateJWTTokens(userID string) (string, string, int64, error) {
+	now := time.Now()
+	accessTokenExp := now.Add(accessTokenTTL)
+
+	accessTokenString, err := signToken(userID, AccessToken, now, accessTokenExp)
 	if err != nil {
 		return "", "", 0, err
 	}
 
-	refreshTokenClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": data,                                       // Subject (user identifier)
-		"iss": "st-okr",                                   // Issuer
-		"aud": data,                                       // Audience (user role)
-		"exp": time.Now().Add(time.Hour * 24 * 30).Unix(), // Expiration time = 30 days
-		"iat": time.Now().Unix(),                          // Issued at
+	refreshTokenString, err := signToken(userID, RefreshToken, now, now.Add(refreshTokenTTL))
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	return accessTokenString, refreshTokenString, accessTokenExp.Unix(), nil
+}
+
+func signToken(userID, tokenType string, issuedAt, expiresAt time.Time) (string, error) {
+	if keys == nil {
+		return "", errors.New("signing keys are not loaded")
+	}
+	key := keys.Active()
+
+	token := jwt.NewWithClaims(key.Method, Claims{
+		Type: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userID,                                         // Subject (user identifier)
+			Issuer:    config.ENV.Auth.JWT.Issuer,                     // Issuer
+			Audience:  jwt.ClaimStrings{config.ENV.Auth.JWT.Audience}, // Audience (the API)
+			ExpiresAt: jwt.NewNumericDate(expiresAt),                  // Expiration time
+			IssuedAt:  jwt.NewNumericDate(issuedAt),                   // Issued at
+			NotBefore: jwt.NewNumericDate(issuedAt.Add(-time.Minute)), // Tolerate clock skew
+		},
 	})
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.PrivateKey)
+}
 
-	refreshTokenString, err := refreshTokenClaims.SignedString([]byte(config.ENV.JWTKey))
+// ParseToken verifies a token against the key named in its kid header and
+// checks issuer, audience, expiry and token type
+func ParseToken(tokenString, tokenType string) (*Claims, error) {
+	if keys == nil {
+		return nil, errors.New("signing keys are not loaded")
+	}
+
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keys.Lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.PublicKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(config.ENV.Auth.JWT.Issuer),
+		jwt.WithAudience(config.ENV.Auth.JWT.Audience),
+		jwt.WithExpirationRequired(),
+	)
 	if err != nil {
-		return "", "", 0, err
+		return nil, err
+	}
+
+	if claims.Type != tokenType {
+		return nil, fmt.Errorf("expected %s token, got %q", tokenType, claims.Type)
 	}
 
-	return accessTokenString, refreshTokenString, accessTokenExp, nil
-}
\ No newline at end of file
+	return claims, nil
+}
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is a key pair used to sign or verify tokens. Retired keys only
+// have a public half and are kept so tokens they signed stay valid until expiry.
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// KeySet holds the active signing key and every key tokens may be verified with
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set as published at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// LoadKeySet reads PEM keys from dir. Each file is named after its key ID:
+// "<kid>.pem" holds a PKCS#8 RSA or Ed25519 private key and "<kid>.pub.pem"
+// holds the public key of a retired key. activeKeyID selects the signing key;
+// it may be empty when the directory holds a single private key.
+func LoadKeySet(dir, activeKeyID string) (*KeySet, error) {
+	if dir == "" {
+		return nil, errors.New("JWT_KEYS_DIR is not set; generate a key with `openssl genpkey -algorithm ed25519 -out <dir>/<kid>.pem`")
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	set := &KeySet{keys: make(map[string]*SigningKey)}
+	var privateKeys []*SigningKey
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key %s: %w", file, err)
+		}
+
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("key %s is not PEM encoded", file)
+		}
+
+		name := filepath.Base(file)
+		key := &SigningKey{}
+
+		if strings.HasSuffix(name, ".pub.pem") {
+			key.ID = strings.TrimSuffix(name, ".pub.pem")
+			key.PublicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+		} else {
+			key.ID = strings.TrimSuffix(name, ".pem")
+			var parsed any
+			parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err == nil {
+				signer, ok := parsed.(crypto.Signer)
+				if !ok {
+					return nil, fmt.Errorf("key %s cannot sign", file)
+				}
+				key.PrivateKey = signer
+				key.PublicKey = signer.Public()
+				privateKeys = append(privateKeys, key)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key %s: %w", file, err)
+		}
+
+		switch key.PublicKey.(type) {
+		case ed25519.PublicKey:
+			key.Method = jwt.SigningMethodEdDSA
+		case *rsa.PublicKey:
+			key.Method = jwt.SigningMethodRS256
+		default:
+			return nil, fmt.Errorf("key %s must be an RSA or Ed25519 key", file)
+		}
+
+		if _, exists := set.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key ID %q", key.ID)
+		}
+		set.keys[key.ID] = key
+	}
+
+	switch {
+	case activeKeyID != "":
+		key, ok := set.keys[activeKeyID]
+		if !ok || key.PrivateKey == nil {
+			return nil, fmt.Errorf("active key %q has no private key in %s", activeKeyID, dir)
+		}
+		set.active = key
+	case len(privateKeys) == 1:
+		set.active = privateKeys[0]
+	case len(privateKeys) == 0:
+		return nil, fmt.Errorf("no private signing key found in %s", dir)
+	default:
+		return nil, errors.New("several private keys found; set JWT_ACTIVE_KEY_ID to choose the signing key")
+	}
+
+	return set, nil
+}
+
+// Active returns the key new tokens are signed with
+func (s *KeySet) Active() *SigningKey {
+	return s.active
+}
+
+// Lookup returns the verification key for a key ID
+func (s *KeySet) Lookup(kid string) (*SigningKey, bool) {
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// JWKS returns the public halves of all keys
+func (s *KeySet) JWKS() JWKS {
+	ids := make([]string, 0, len(s.keys))
+	for id := range s.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(ids))}
+	for _, id := range ids {
+		key := s.keys[id]
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Method.Alg()}
+
+		switch pub := key.PublicKey.(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
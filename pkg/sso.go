@@ -0,0 +1,342 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Slightly-Techie/st-okr-api/config"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/samlsp"
+	"golang.org/x/oauth2"
+)
+
+// SSOIdentity is the user information asserted by a company identity provider
+type SSOIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+}
+
+// Discovered OIDC providers are cached per issuer for a while, and only for
+// so many issuers, so issuers that are no longer configured drop out
+const (
+	oidcProviderTTL      = time.Hour
+	maxCachedOIDCIssuers = 100
+)
+
+type cachedOIDCProvider struct {
+	provider   *oidc.Provider
+	discovered time.Time
+}
+
+var (
+	oidcProvidersMu sync.Mutex
+	oidcProviders   = map[string]cachedOIDCProvider{}
+)
+
+// OIDCClient returns the discovered provider and OAuth2 config for an issuer.
+// The issuer is admin-supplied, so discovery and the provider's key set are
+// fetched with the client that refuses non-public addresses.
+func OIDCClient(ctx context.Context, issuer, clientID, clientSecret, redirectURL string) (*oidc.Provider, *oauth2.Config, error) {
+	provider, err := oidcProvider(ctx, issuer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	oauthConfig := &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+	}
+
+	return provider, oauthConfig, nil
+}
+
+func oidcProvider(ctx context.Context, issuer string) (*oidc.Provider, error) {
+	now := time.Now()
+
+	oidcProvidersMu.Lock()
+	cached, ok := oidcProviders[issuer]
+	oidcProvidersMu.Unlock()
+	if ok && now.Sub(cached.discovered) < oidcProviderTTL {
+		return cached.provider, nil
+	}
+
+	provider, err := oidc.NewProvider(oidc.ClientContext(ctx, publicHTTPClient()), issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC issuer: %w", err)
+	}
+
+	oidcProvidersMu.Lock()
+	defer oidcProvidersMu.Unlock()
+	for cachedIssuer, entry := range oidcProviders {
+		if now.Sub(entry.discovered) >= oidcProviderTTL {
+			delete(oidcProviders, cachedIssuer)
+		}
+	}
+	for len(oidcProviders) >= maxCachedOIDCIssuers {
+		oldest := ""
+		for cachedIssuer, entry := range oidcProviders {
+			if oldest == "" || entry.discovered.Before(oidcProviders[oldest].discovered) {
+				oldest = cachedIssuer
+			}
+		}
+		delete(oidcProviders, oldest)
+	}
+	oidcProviders[issuer] = cachedOIDCProvider{provider: provider, discovered: now}
+	return provider, nil
+}
+
+// OIDCExchange redeems an authorization code at the provider's token
+// endpoint, which comes from the admin-supplied issuer's discovery document
+func OIDCExchange(ctx context.Context, oauthConfig *oauth2.Config, code string) (*oauth2.Token, error) {
+	return oauthConfig.Exchange(context.WithValue(ctx, oauth2.HTTPClient, publicHTTPClient()), code)
+}
+
+// OIDCIdentity verifies an ID token and extracts the user it describes
+func OIDCIdentity(ctx context.Context, provider *oidc.Provider, clientID, rawIDToken, nonce string) (*SSOIdentity, error) {
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: clientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify ID token: %w", err)
+	}
+
+	if idToken.Nonce != nonce {
+		return nil, errors.New("ID token nonce mismatch")
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified *bool  `json:"email_verified"`
+		GivenName     string `json:"given_name"`
+		FamilyName    string `json:"family_name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to parse ID token claims: %w", err)
+	}
+
+	return &SSOIdentity{
+		Subject: idToken.Subject,
+		Email:   claims.Email,
+		// Enterprise IdPs often omit the claim; the email domain is checked separately
+		EmailVerified: claims.EmailVerified == nil || *claims.EmailVerified,
+		FirstName:     claims.GivenName,
+		LastName:      claims.FamilyName,
+	}, nil
+}
+
+var (
+	samlKeyPairOnce sync.Once
+	samlKey         *rsa.PrivateKey
+	samlCert        *x509.Certificate
+	samlKeyPairErr  error
+)
+
+// loadSAMLKeyPair reads the service provider signing key pair from the configured files
+func loadSAMLKeyPair() (*rsa.PrivateKey, *x509.Certificate, error) {
+	samlKeyPairOnce.Do(func() {
+		if config.ENV.Auth.SAML.CertFile == "" || config.ENV.Auth.SAML.KeyFile == "" {
+			samlKeyPairErr = errors.New("SAML_CERT_FILE and SAML_KEY_FILE must be set to use SAML")
+			return
+		}
+
+		keyPair, err := tls.LoadX509KeyPair(config.ENV.Auth.SAML.CertFile, config.ENV.Auth.SAML.KeyFile)
+		if err != nil {
+			samlKeyPairErr = fmt.Errorf("failed to load SAML key pair: %w", err)
+			return
+		}
+
+		key, ok := keyPair.PrivateKey.(*rsa.PrivateKey)
+		if !ok {
+			samlKeyPairErr = errors.New("SAML key must be an RSA private key")
+			return
+		}
+
+		cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+		if err != nil {
+			samlKeyPairErr = fmt.Errorf("failed to parse SAML certificate: %w", err)
+			return
+		}
+
+		samlKey, samlCert = key, cert
+	})
+
+	return samlKey, samlCert, samlKeyPairErr
+}
+
+// maxSAMLMetadataSize bounds the IdP metadata read from a metadata URL
+const maxSAMLMetadataSize = 1 << 20
+
+// FetchSAMLMetadata downloads and parses IdP metadata from an admin-supplied
+// URL through publicHTTPClient
+func FetchSAMLMetadata(ctx context.Context, metadataURL string) ([]byte, error) {
+	parsed, err := url.Parse(metadataURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata URL: %w", err)
+	}
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return nil, errors.New("metadata URL must use https or http")
+	}
+
+	client := publicHTTPClient()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch IdP metadata: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch IdP metadata: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSAMLMetadataSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch IdP metadata: %w", err)
+	}
+	if len(data) > maxSAMLMetadataSize {
+		return nil, errors.New("IdP metadata is larger than 1 MiB")
+	}
+	if _, err := samlsp.ParseMetadata(data); err != nil {
+		return nil, fmt.Errorf("failed to parse IdP metadata: %w", err)
+	}
+	return data, nil
+}
+
+// publicHTTPClient fetches URLs that company admins supply. Connections to
+// loopback, private and link-local addresses are refused when they are made,
+// so neither the URL, a redirect nor a DNS answer can point a request at the
+// server's own network.
+func publicHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil {
+				return err
+			}
+			if !publicAddr(ip) {
+				return fmt.Errorf("%s is not a public address", ip)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: 10 * time.Second},
+	}
+}
+
+// publicAddr reports whether ip is routable on the internet
+func publicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() &&
+		!netip.MustParsePrefix("100.64.0.0/10").Contains(ip) // carrier-grade NAT
+}
+
+// SAMLServiceProvider builds the service provider for a company from the IdP
+// metadata stored with its configuration. Only responses to an AuthnRequest
+// this service provider sent are accepted; IdP-initiated logins are not.
+func SAMLServiceProvider(metadataXML string, spMetadataURL, acsURL string) (*saml.ServiceProvider, error) {
+	key, cert, err := loadSAMLKeyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	if metadataXML == "" {
+		return nil, errors.New("no SAML identity provider metadata configured")
+	}
+	idpMetadata, err := samlsp.ParseMetadata([]byte(metadataXML))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load IdP metadata: %w", err)
+	}
+
+	metadata, err := url.Parse(spMetadataURL)
+	if err != nil {
+		return nil, err
+	}
+	acs, err := url.Parse(acsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &saml.ServiceProvider{
+		EntityID:    spMetadataURL,
+		Key:         key,
+		Certificate: cert,
+		MetadataURL: *metadata,
+		AcsURL:      *acs,
+		IDPMetadata: idpMetadata,
+	}, nil
+}
+
+// SAMLIdentity extracts the user from a verified assertion using the common
+// attribute names of Azure AD, Okta, Google Workspace and ADFS
+func SAMLIdentity(assertion *saml.Assertion) *SSOIdentity {
+	identity := &SSOIdentity{EmailVerified: true}
+
+	if assertion.Subject != nil && assertion.Subject.NameID != nil {
+		identity.Subject = assertion.Subject.NameID.Value
+	}
+
+	for _, statement := range assertion.AttributeStatements {
+		for _, attr := range statement.Attributes {
+			if len(attr.Values) == 0 {
+				continue
+			}
+			value := attr.Values[0].Value
+
+			switch strings.ToLower(firstNonEmpty(attr.FriendlyName, attr.Name)) {
+			case "email", "mail", "emailaddress",
+				"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress":
+				identity.Email = value
+			case "givenname", "firstname", "first_name",
+				"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/givenname":
+				identity.FirstName = value
+			case "sn", "surname", "lastname", "last_name",
+				"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/surname":
+				identity.LastName = value
+			}
+		}
+	}
+
+	// Many IdPs use the email address as the NameID
+	if identity.Email == "" && strings.Contains(identity.Subject, "@") {
+		identity.Email = identity.Subject
+	}
+
+	return identity
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
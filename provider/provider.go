@@ -2,7 +2,9 @@ package provider
 
 import (
 	"github.com/Slightly-Techie/st-okr-api/internal/controllers"
+	"github.com/Slightly-Techie/st-okr-api/internal/ratelimit"
 	"github.com/Slightly-Techie/st-okr-api/internal/repositories"
+	"github.com/Slightly-Techie/st-okr-api/internal/scheduler"
 	"github.com/Slightly-Techie/st-okr-api/internal/services"
 	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
@@ -15,7 +17,18 @@ type Provider struct {
 	TeamController       *controllers.TeamController
 	KeyResultController  *controllers.KeyResultController
 	ObjectiveController  *controllers.ObjectiveController
+	ImportController     *controllers.ImportController
+	ExportController     *controllers.ExportController
+	DashboardController  *controllers.DashboardController
+	TimeSeriesController *controllers.TimeSeriesController
+	SSOController        *controllers.SSOController
+	HealthController     *controllers.HealthController
 	DB                   *gorm.DB
+	// RateLimitStore holds the rate limit buckets. Replace it before setting
+	// up the router to share budgets across replicas.
+	RateLimitStore ratelimit.Store
+	// Jobs is the background work to hand to the scheduler
+	Jobs []scheduler.Job
 }
 
 func NewProvider(db *gorm.DB, validator *validator.Validate) *Provider {
@@ -26,14 +39,22 @@ func NewProvider(db *gorm.DB, validator *validator.Validate) *Provider {
 	teamRepo := repositories.NewTeamRepository(db)
 	keyResultRepo := repositories.NewKeyResultRepository(db)
 	objectiveRepo := repositories.NewObjectiveRepository(db)
+	ssoRepo := repositories.NewSSORepository(db)
+	dashboardRepo := repositories.NewDashboardRepository(db)
+	snapshotRepo := repositories.NewProgressSnapshotRepository(db)
 
 	// Initialize services
 	userService := services.NewAuthService(userRepo, validator)
 	companyService := services.NewCompanyService(companyRepo, validator)
 	membershipService := services.NewMembershipService(membershipRepo, validator)
 	teamService := services.NewTeamService(teamRepo, validator)
-	keyResultService := services.NewKeyResultService(keyResultRepo, validator)
+	keyResultService := services.NewKeyResultService(keyResultRepo, snapshotRepo, validator)
 	objectiveService := services.NewObjectiveService(objectiveRepo, validator)
+	importService := services.NewImportService(objectiveRepo, validator)
+	exportService := services.NewExportService(objectiveRepo)
+	dashboardService := services.NewDashboardService(dashboardRepo)
+	timeSeriesService := services.NewTimeSeriesService(snapshotRepo)
+	ssoService := services.NewSSOService(ssoRepo, validator)
 
 	// Initialize controllers
 	userController := controllers.NewAuthController(userService)
@@ -42,6 +63,12 @@ func NewProvider(db *gorm.DB, validator *validator.Validate) *Provider {
 	teamController := controllers.NewTeamController(teamService)
 	keyResultController := controllers.NewKeyResultController(keyResultService)
 	objectiveController := controllers.NewObjectiveController(objectiveService)
+	importController := controllers.NewImportController(importService)
+	exportController := controllers.NewExportController(exportService)
+	dashboardController := controllers.NewDashboardController(dashboardService)
+	timeSeriesController := controllers.NewTimeSeriesController(timeSeriesService)
+	ssoController := controllers.NewSSOController(ssoService, userService)
+	healthController := controllers.NewHealthController(db)
 
 	return &Provider{
 		UserController:       userController,
@@ -50,6 +77,17 @@ func NewProvider(db *gorm.DB, validator *validator.Validate) *Provider {
 		TeamController:       teamController,
 		KeyResultController:  keyResultController,
 		ObjectiveController:  objectiveController,
+		ImportController:     importController,
+		ExportController:     exportController,
+		DashboardController:  dashboardController,
+		TimeSeriesController: timeSeriesController,
+		SSOController:        ssoController,
+		HealthController:     healthController,
 		DB:                   db,
+		RateLimitStore:       ratelimit.NewMemoryStore(),
+		Jobs: []scheduler.Job{
+			{Name: "progress_snapshots", Run: timeSeriesService.RecordSnapshots},
+			{Name: "key_result_status", Run: keyResultService.RefreshStatuses},
+		},
 	}
 }